@@ -3,6 +3,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,11 +13,18 @@ import (
 	"gin_saas_auth/internal/api/middleware"
 	v1 "gin_saas_auth/internal/api/v1"
 	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/lifecycle"
+	"gin_saas_auth/internal/metrics"
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/repository"
 	"gin_saas_auth/internal/services"
+	"gin_saas_auth/internal/utils"
 
 	_ "gin_saas_auth/api/swagger"
 
 	"github.com/gin-gonic/gin"
+	_ "github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 )
 
@@ -45,6 +53,9 @@ func main() {
 	}
 	logrus.Info("配置加载成功")
 
+	// 初始化监控指标
+	metrics.Init(cfg)
+
 	// 设置Gin模式
 	if cfg.IsProduction() {
 		gin.SetMode(gin.ReleaseMode)
@@ -54,8 +65,202 @@ func main() {
 		logrus.Info("运行在开发模式")
 	}
 
+	// 校验 JWT 签名密钥
+	if cfg.Auth.JWTSecret == "" {
+		if cfg.IsProduction() {
+			logrus.Fatal("生产环境必须配置 AUTH_JWT_SECRET")
+		}
+		secret, err := utils.GenerateRandomToken(32)
+		if err != nil {
+			logrus.Fatalf("生成临时 JWT 密钥失败: %v", err)
+		}
+		cfg.Auth.JWTSecret = secret
+		logrus.Warn("未配置 AUTH_JWT_SECRET，已生成临时密钥，重启后已签发的令牌将失效")
+	}
+
+	// 组件按依赖顺序启动，关闭时逆序停止；全部组件运行中时健康检查才返回可用
+	app := lifecycle.NewManager(cfg.Lifecycle.StartTimeout, cfg.Lifecycle.StopTimeout)
+	// HTTP 服务依赖的基础组件，关闭时在请求处理完成后才停止
+	var infrastructure []string
+
+	// 初始化数据存储
+	var userRepo interface {
+		repository.UserRepository
+		repository.PasswordResetRepository
+		repository.PasswordHistoryRepository
+	}
+	var sessionRepo repository.SessionRepository
+	var mfaRepo repository.MFARepository
+	var tenantRepo interface {
+		repository.TenantRepository
+		repository.PasswordPolicyRepository
+	}
+	var apiKeyRepo repository.APIKeyRepository
+	var auditRepo repository.AuditRepository
+	var verificationRepo repository.VerificationRepository
+	var identityProviderRepo repository.IdentityProviderRepository
+	if cfg.IsDatabaseEnabled() {
+		db, err := repository.OpenDB(cfg)
+		if err != nil {
+			logrus.Fatalf("初始化数据库失败: %v", err)
+		}
+		app.Add("database", lifecycle.Closer(nil, db.Close), lifecycle.Options{})
+		infrastructure = append(infrastructure, "database")
+		userRepo = repository.NewSQLUserRepository(db)
+		sessionRepo = repository.NewSQLSessionRepository(db)
+		mfaRepo = repository.NewSQLMFARepository(db)
+		tenantRepo = repository.NewSQLTenantRepository(db)
+		apiKeyRepo = repository.NewSQLAPIKeyRepository(db)
+		auditRepo = repository.NewSQLAuditRepository(db)
+		verificationRepo = repository.NewSQLVerificationRepository(db)
+		identityProviderRepo = repository.NewSQLIdentityProviderRepository(db)
+		logrus.Infof("使用 %s 数据库存储", cfg.Database.Driver)
+	} else {
+		userRepo = repository.NewMemoryUserRepository()
+		sessionRepo = repository.NewMemorySessionRepository()
+		mfaRepo = repository.NewMemoryMFARepository()
+		tenantRepo = repository.NewMemoryTenantRepository()
+		apiKeyRepo = repository.NewMemoryAPIKeyRepository()
+		auditRepo = repository.NewMemoryAuditRepository()
+		verificationRepo = repository.NewMemoryVerificationRepository()
+		identityProviderRepo = repository.NewMemoryIdentityProviderRepository()
+		logrus.Warn("未配置 DB_DSN，使用内存存储，重启后数据将丢失")
+	}
+
+	// 登录失败计数、验证码发送频率与请求签名随机数：依赖 Redis 时多实例共享，否则使用进程内存
+	var attemptStore repository.AttemptStore
+	var nonceStore repository.NonceStore
+	var redisClient *redis.Client
+	if cfg.IsRedisEnabled() {
+		redisClient, err = repository.OpenRedis(cfg)
+		if err != nil {
+			logrus.Fatalf("初始化 Redis 失败: %v", err)
+		}
+		app.Add("redis", lifecycle.Closer(nil, redisClient.Close), lifecycle.Options{})
+		infrastructure = append(infrastructure, "redis")
+		attemptStore = repository.NewRedisAttemptStore(redisClient, cfg.Redis.KeyPrefix)
+		nonceStore = repository.NewRedisNonceStore(redisClient, cfg.Redis.KeyPrefix)
+		logrus.Infof("使用 Redis 保存登录失败计数: %s", cfg.Redis.Addr)
+	} else {
+		attemptStore = repository.NewMemoryAttemptStore()
+		nonceStore = repository.NewMemoryNonceStore()
+	}
+
+	// 请求限流计数，未指定存储时与登录失败计数一致
+	backend := cfg.RateLimit.Backend
+	if backend == "" {
+		backend = "memory"
+		if redisClient != nil {
+			backend = "redis"
+		}
+	}
+	var rateLimitStore repository.RateLimitStore
+	switch backend {
+	case "redis":
+		if redisClient == nil {
+			logrus.Fatal("RATE_LIMIT_BACKEND=redis 需要同时设置 CONSUL_DEPEND_REDIS=true 并配置 Redis")
+		}
+		rateLimitStore = repository.NewRedisRateLimitStore(redisClient, cfg.Redis.KeyPrefix)
+	case "memory":
+		rateLimitStore = repository.NewMemoryRateLimitStore()
+	default:
+		logrus.Fatalf("不支持的限流存储: %s", backend)
+	}
+	// API 密钥自身的请求上限由创建者设置，不受 RATE_LIMIT_ENABLED 影响
+	apiKeyLimiter := services.NewRateLimiter(rateLimitStore)
+	var rateLimiter *services.RateLimiter
+	if cfg.RateLimit.Enabled {
+		rateLimiter = apiKeyLimiter
+		logrus.WithField("backend", backend).Info("已启用请求限流")
+	} else {
+		logrus.Warn("请求限流未启用")
+	}
+
+	// 过载保护，按请求耗时自适应调整并发上限
+	var loadShedder *services.LoadShedder
+	if cfg.LoadShed.Enabled {
+		loadShedder = services.NewLoadShedder(cfg.LoadShed)
+	}
+
+	// 初始化业务服务
+	auditService := services.NewAuditService(auditRepo, middleware.AuditLogger)
+	tenantService := services.NewTenantService(tenantRepo, cfg.Tenant.CacheTTL)
+	if err := tenantService.EnsureDefault(context.Background(), cfg.Tenant.DefaultTenant); err != nil {
+		logrus.Fatalf("初始化默认租户失败: %v", err)
+	}
+	tokenService := services.NewTokenService(cfg)
+	hasher := utils.NewPasswordHasher(utils.Argon2Params{
+		Memory:      cfg.Auth.Argon2Memory,
+		Iterations:  cfg.Auth.Argon2Iterations,
+		Parallelism: cfg.Auth.Argon2Parallelism,
+	})
+	passwordPolicyService, err := services.NewPasswordPolicyService(cfg, tenantRepo)
+	if err != nil {
+		logrus.Fatalf("初始化密码策略服务失败: %v", err)
+	}
+	userService, err := services.NewUserService(userRepo, userRepo, userRepo, passwordPolicyService, hasher, cfg.Auth.ResetTokenTTL)
+	if err != nil {
+		logrus.Fatalf("初始化用户服务失败: %v", err)
+	}
+	sessionService := services.NewSessionService(cfg, sessionRepo, userRepo, tokenService, auditService)
+	mfaService, err := services.NewMFAService(cfg, mfaRepo, userRepo, tokenService)
+	if err != nil {
+		logrus.Fatalf("初始化多因素认证服务失败: %v", err)
+	}
+	lockoutService := services.NewLockoutService(cfg, attemptStore, auditService)
+	signatureService := services.NewSignatureService(cfg, nonceStore)
+	if !signatureService.Enabled() {
+		logrus.Warn("未配置 SIGNING_KEYS，内部服务接口将拒绝所有请求")
+	} else if redisClient == nil {
+		logrus.Warn("未启用 Redis，请求签名随机数只保存在进程内存中，多实例部署时无法识别重放到其他实例的请求")
+	}
+	rbacService, err := services.NewRBACService(cfg.RBAC.PolicyFile, auditService)
+	if err != nil {
+		logrus.Fatalf("初始化访问控制服务失败: %v", err)
+	}
+	apiKeyService := services.NewAPIKeyService(cfg, apiKeyRepo, userRepo, rbacService)
+	exchangeService := services.NewTokenExchangeService(cfg, tokenService, rbacService)
+	sender, err := services.NewSender(cfg)
+	if err != nil {
+		logrus.Fatalf("初始化消息发送失败: %v", err)
+	}
+	verificationService := services.NewVerificationService(cfg, verificationRepo, attemptStore, sender, auditService)
+	oidcClient := services.NewOIDCClient(&http.Client{Timeout: cfg.OIDC.HTTPTimeout}, cfg.OIDC.DiscoveryTTL)
+	federationService, err := services.NewFederationService(cfg, identityProviderRepo, userService, oidcClient)
+	if err != nil {
+		logrus.Fatalf("初始化外部身份登录服务失败: %v", err)
+	}
+
+	// 后台任务
+	app.Add("rbac-watcher", lifecycle.Worker(func(ctx context.Context) {
+		rbacService.Watch(ctx, cfg.RBAC.ReloadInterval)
+	}), lifecycle.Options{})
+	infrastructure = append(infrastructure, "rbac-watcher")
+
 	// 设置路由
-	r := v1.SetupRouter(cfg.Server.Domain)
+	r := v1.SetupRouter(cfg.Server.Domain, &v1.RouterDeps{
+		Lifecycle:                  app,
+		LoadShedder:                loadShedder,
+		TokenService:               tokenService,
+		AuthController:             v1.NewAuthController(userService, sessionService, mfaService, lockoutService, verificationService, auditService),
+		SessionController:          v1.NewSessionController(sessionService, auditService),
+		MFAController:              v1.NewMFAController(mfaService, userService, sessionService, lockoutService, auditService),
+		VerificationController:     v1.NewVerificationController(verificationService, userService, sessionService, mfaService, lockoutService, auditService),
+		FederationController:       v1.NewFederationController(federationService, tenantService, sessionService, mfaService, auditService),
+		RateLimiter:                rateLimiter,
+		APIKeyRateLimiter:          apiKeyLimiter,
+		RBACService:                rbacService,
+		RBACController:             v1.NewRBACController(rbacService),
+		TenantService:              tenantService,
+		TenantController:           v1.NewTenantController(tenantService, auditService),
+		IdentityProviderController: v1.NewIdentityProviderController(federationService, auditService),
+		PasswordPolicyController:   v1.NewPasswordPolicyController(passwordPolicyService, auditService),
+		APIKeyService:              apiKeyService,
+		APIKeyController:           v1.NewAPIKeyController(apiKeyService, userService, auditService),
+		AuditController:            v1.NewAuditController(auditService, rbacService),
+		SignatureService:           signatureService,
+		InternalController:         v1.NewInternalController(tokenService, exchangeService, auditService),
+	})
 
 	// 创建HTTP服务器
 	server := &http.Server{
@@ -65,76 +270,127 @@ func main() {
 		WriteTimeout: cfg.Server.WriteTimeout,
 	}
 
-	// 初始化 Consul 注册（如果启用）
-	var consulRegistry *services.ConsulRegistry
+	// 启用 HTTPS 时加载证书，证书文件变更后自动重新加载
+	if cfg.IsTLSEnabled() {
+		certReloader, err := services.NewCertReloader(cfg)
+		if err != nil {
+			logrus.Fatalf("初始化 TLS 证书失败: %v", err)
+		}
+		server.TLSConfig = certReloader.TLSConfig()
+		app.Add("cert-reloader", lifecycle.Worker(certReloader.Watch), lifecycle.Options{})
+		infrastructure = append(infrastructure, "cert-reloader")
+		logrus.WithField("client_auth", cfg.TLS.ClientAuth).Info("已启用 HTTPS")
+	}
+
+	// 运维管理端口先于对外端口启动、晚于对外端口停止，排空期间仍可查看健康状态和监控指标
+	if cfg.IsAdminEnabled() {
+		adminServer := &http.Server{
+			Addr:              cfg.GetAdminAddr(),
+			Handler:           v1.SetupAdminRouter(cfg, app, loadShedder),
+			ReadHeaderTimeout: cfg.Server.ReadTimeout,
+		}
+		app.Add("admin-http", lifecycle.HTTPServer(adminServer, 0), lifecycle.Options{DependsOn: infrastructure})
+		infrastructure = append(infrastructure, "admin-http")
+		logrus.WithField("addr", cfg.GetAdminAddr()).Info("已启用运维管理端口")
+		if cfg.Admin.Token == "" && !cfg.IsAdminLoopback() {
+			logrus.Warn("未配置 ADMIN_TOKEN 且管理端口监听非本机地址，pprof、日志级别和配置查看接口将拒绝所有请求")
+		}
+	}
+
+	// 请求处理完成前不停止基础组件；停止时先等待网关感知实例下线
+	app.Add("http", lifecycle.HTTPServer(server, cfg.Lifecycle.PropagationDelay), lifecycle.Options{
+		DependsOn:   infrastructure,
+		StopTimeout: cfg.Lifecycle.PropagationDelay + cfg.Lifecycle.DrainTimeout,
+	})
+
+	// 初始化 Consul 注册（如果启用），服务开始监听后注册，关闭时最先注销
 	if cfg.IsConsulEnabled() {
 		logrus.Info("正在初始化 Consul 服务注册...")
 
 		// 验证 Consul 配置
 		if err := services.ValidateConfig(cfg); err != nil {
 			logrus.Warnf("Consul 配置验证失败: %v", err)
+		} else if consulRegistry, err := services.NewConsulRegistry(cfg); err != nil {
+			logrus.Errorf("创建 Consul 注册器失败: %v", err)
 		} else {
-			consulRegistry, err = services.NewConsulRegistry(cfg)
-			if err != nil {
-				logrus.Errorf("创建 Consul 注册器失败: %v", err)
-			} else {
-				// 注册服务到 Consul
-				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-				if err := consulRegistry.Register(ctx); err != nil {
-					logrus.Errorf("注册服务到 Consul 失败: %v", err)
-				}
-				cancel()
-			}
+			app.Add("consul", consulComponent(consulRegistry, auditService), lifecycle.Options{
+				DependsOn:   []string{"http"},
+				StopTimeout: cfg.Server.HealthCheckTimeout,
+			})
 		}
 	} else {
 		logrus.Info("Consul 服务发现未启用")
 	}
 
-	// 启动服务器（非阻塞）
-	go func() {
-		logrus.WithFields(logrus.Fields{
-			"host":    cfg.Server.Host,
-			"port":    cfg.App.Port,
-			"domain":  cfg.Server.Domain,
-			"env":     cfg.App.Env,
-			"version": cfg.Consul.Meta.Version,
-		}).Info("OSS文件转发服务启动成功")
-
-		logrus.Infof("外部访问地址: %s", cfg.GetServiceURL())
-		logrus.Infof("Swagger文档地址: %s/swagger/index.html", cfg.GetServiceURL())
-		logrus.Infof("健康检查地址: %s/health", cfg.GetServiceURL())
-		logrus.Infof("监控指标地址: %s/metrics", cfg.GetServiceURL())
-		logrus.Infof("服务统计地址: %s/api/v1/stats", cfg.GetServiceURL())
-
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logrus.Fatalf("服务器启动失败: %v", err)
-		}
-	}()
+	if err := app.Start(context.Background()); err != nil {
+		logrus.Fatalf("服务启动失败: %v", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"host":    cfg.Server.Host,
+		"port":    cfg.App.Port,
+		"domain":  cfg.Server.Domain,
+		"env":     cfg.App.Env,
+		"version": cfg.Consul.Meta.Version,
+	}).Info("OSS文件转发服务启动成功")
+
+	logrus.Infof("外部访问地址: %s", cfg.GetServiceURL())
+	logrus.Infof("Swagger文档地址: %s/swagger/index.html", cfg.GetServiceURL())
+	logrus.Infof("健康检查地址: %s/health", cfg.GetServiceURL())
+	logrus.Infof("监控指标地址: %s", cfg.GetMetricsURL())
+	logrus.Infof("服务统计地址: %s/api/v1/stats", cfg.GetServiceURL())
 
 	// 等待中断信号以优雅地关闭服务器
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	logrus.Info("正在关闭服务器...")
-
-	// 设置5秒的超时时间来关闭服务器
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	// 从 Consul 注销服务
-	if consulRegistry != nil {
-		logrus.Info("正在从 Consul 注销服务...")
-		if err := consulRegistry.Deregister(ctx); err != nil {
-			logrus.Errorf("从 Consul 注销服务失败: %v", err)
-		} else {
-			logrus.Info("服务已从 Consul 注销")
-		}
-	}
+	sig := <-quit
+	logrus.WithField("signal", sig.String()).Info("收到退出信号，开始优雅关闭")
 
-	// 关闭HTTP服务器
-	if err := server.Shutdown(ctx); err != nil {
-		logrus.Fatalf("服务器强制关闭: %v", err)
-	}
+	// 关闭过程中再次收到信号时立即退出
+	go func() {
+		<-quit
+		logrus.Warn("再次收到退出信号，立即退出")
+		os.Exit(1)
+	}()
+
+	// 依次：健康检查置为不可用、从 Consul 注销、等待网关感知、处理完进行中的请求、停止后台任务并关闭依赖
+	app.Shutdown()
 
 	logrus.Info("OSS文件转发服务已安全关闭")
 }
+
+// consulComponent 服务注册组件，注册失败只记录日志，不影响服务启动
+func consulComponent(registry *services.ConsulRegistry, audit services.AuditRecorder) lifecycle.Component {
+	registered := false
+	return lifecycle.Hooks{
+		OnStart: func(ctx context.Context) error {
+			if err := registry.Register(ctx); err != nil {
+				logrus.Errorf("注册服务到 Consul 失败: %v", err)
+				return nil
+			}
+			registered = true
+			recordConsulEvent(ctx, audit, models.AuditConsulRegistered, registry)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			if !registered {
+				return nil
+			}
+			if err := registry.Deregister(ctx); err != nil {
+				return fmt.Errorf("从 Consul 注销服务失败: %w", err)
+			}
+			logrus.Info("服务已从 Consul 注销")
+			recordConsulEvent(ctx, audit, models.AuditConsulDeregistered, registry)
+			return nil
+		},
+	}
+}
+
+// recordConsulEvent 记录服务注册状态变化的审计事件
+func recordConsulEvent(ctx context.Context, audit services.AuditRecorder, eventType string, registry *services.ConsulRegistry) {
+	audit.Record(ctx, &models.AuditEvent{
+		Type:       eventType,
+		Subject:    registry.GetServiceID(),
+		OccurredAt: time.Now(),
+	})
+}
@@ -0,0 +1,35 @@
+// middleware/admin.go
+package middleware
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminTokenMiddleware 运维管理接口令牌校验中间件
+// token 为空时只有管理端口监听本机地址（loopback 为 true）才放行，否则拒绝所有请求
+func AdminTokenMiddleware(token string, loopback bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			if !loopback {
+				utils.Unauthorized(c, "auth.admin_token_required")
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		provided := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			utils.Unauthorized(c, "auth.admin_token_invalid")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
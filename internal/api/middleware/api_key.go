@@ -0,0 +1,65 @@
+// middleware/api_key.go
+package middleware
+
+import (
+	"strings"
+
+	"gin_saas_auth/internal/services"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// API 密钥请求头，与 CORS 允许的请求头保持一致
+const (
+	HeaderToken  = "Token"
+	HeaderXToken = "X-Token"
+)
+
+// TokenOrAPIKeyMiddleware 同时接受访问令牌和 API 密钥的认证中间件
+// 请求携带 Token 或 X-Token 头时按 API 密钥校验并限流，否则按 AuthMiddleware 校验访问令牌
+// API 密钥换算出的声明主体为密钥所属用户，权限检查时还会受密钥权限范围限制
+func TokenOrAPIKeyMiddleware(tokens *services.TokenService, apiKeys *services.APIKeyService, limiter *services.RateLimiter) gin.HandlerFunc {
+	bearer := AuthMiddleware(tokens)
+	return func(c *gin.Context) {
+		rawKey := extractAPIKey(c)
+		if rawKey == "" {
+			bearer(c)
+			return
+		}
+
+		key, err := apiKeys.Authenticate(c.Request.Context(), rawKey)
+		if err != nil {
+			utils.RespondError(c, err)
+			c.Abort()
+			return
+		}
+
+		// 密钥只能在所属租户下使用
+		if tenantID, exists := c.Get(ContextKeyTenantID); exists && tenantID != key.TenantID {
+			utils.Unauthorized(c, "auth.api_key_tenant_mismatch")
+			c.Abort()
+			return
+		}
+
+		// 按密钥自身的请求上限计数，与其他限流规则共用计数存储
+		if rule := apiKeys.RateLimitRule(key); rule.Limit > 0 {
+			if !allowRequest(c, limiter, rule, key.ID) {
+				utils.RespondError(c, services.ErrAPIKeyRateLimited)
+				c.Abort()
+				return
+			}
+		}
+
+		c.Set(ContextKeyClaims, apiKeys.Claims(key))
+		c.Next()
+	}
+}
+
+// extractAPIKey 从 Token 或 X-Token 头中提取 API 密钥
+func extractAPIKey(c *gin.Context) string {
+	if key := strings.TrimSpace(c.GetHeader(HeaderToken)); key != "" {
+		return key
+	}
+	return strings.TrimSpace(c.GetHeader(HeaderXToken))
+}
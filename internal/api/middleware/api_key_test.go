@@ -0,0 +1,154 @@
+// middleware/api_key_test.go
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/repository"
+	"gin_saas_auth/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyTestPolicy 所有用户在全部租户拥有 users:read
+const apiKeyTestPolicy = `{
+  "roles": {"viewer": {"permissions": ["users:read"]}},
+  "bindings": [{"subject": "*", "role": "viewer", "tenant": "*"}]
+}`
+
+// apiKeyEnv API 密钥中间件测试环境，未启用全局限流时也按密钥上限计数
+type apiKeyEnv struct {
+	router *gin.Engine
+	keys   *services.APIKeyService
+	user   *models.User
+}
+
+func newAPIKeyEnv(t *testing.T) *apiKeyEnv {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	users := repository.NewMemoryUserRepository()
+	user := &models.User{ID: "user-1", TenantID: "acme", Email: "key@example.com", Status: models.UserStatusActive, CreatedAt: time.Now()}
+	if err := users.Create(context.Background(), user); err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+
+	cfg := &config.Config{Auth: config.AuthConfig{APIKeyDefaultRateLimit: 2, APIKeyMaxPerUser: 10}}
+	keys := services.NewAPIKeyService(cfg, repository.NewMemoryAPIKeyRepository(), users, newTestRBAC(t, apiKeyTestPolicy))
+	limiter := services.NewRateLimiter(repository.NewMemoryRateLimitStore())
+
+	r := gin.New()
+	r.GET("/resource", func(c *gin.Context) {
+		c.Set(ContextKeyTenantID, c.Query("tenant"))
+	}, TokenOrAPIKeyMiddleware(nil, keys, limiter), func(c *gin.Context) {
+		claims, _ := GetClaims(c)
+		c.JSON(http.StatusOK, gin.H{"subject": claims.Subject, "scopes": claims.Scopes})
+	})
+	return &apiKeyEnv{router: r, keys: keys, user: user}
+}
+
+// createKey 为测试用户创建密钥
+func (env *apiKeyEnv) createKey(t *testing.T, rateLimit *int) string {
+	t.Helper()
+	created, err := env.keys.Create(context.Background(), env.user, services.CreateAPIKeyInput{
+		Name:      "ci",
+		Scopes:    []string{"users:read"},
+		RateLimit: rateLimit,
+	})
+	if err != nil {
+		t.Fatalf("创建密钥失败: %v", err)
+	}
+	return created.Key
+}
+
+// call 携带密钥请求 acme 租户下的接口
+func (env *apiKeyEnv) call(header, key, tenant string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/resource?tenant="+tenant, nil)
+	req.Header.Set(header, key)
+	w := httptest.NewRecorder()
+	env.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestTokenOrAPIKeyMiddlewareEnforcesKeyRateLimit(t *testing.T) {
+	env := newAPIKeyEnv(t)
+	key := env.createKey(t, nil)
+
+	for i, want := range []string{"1", "0"} {
+		w := env.call(HeaderToken, key, "acme")
+		if w.Code != http.StatusOK {
+			t.Fatalf("第 %d 次请求状态码 = %d, body = %s", i+1, w.Code, w.Body.String())
+		}
+		if got := w.Header().Get(HeaderRateLimitRemaining); got != want {
+			t.Fatalf("第 %d 次请求 RateLimit-Remaining = %q, want %q", i+1, got, want)
+		}
+		if w.Header().Get(HeaderRateLimitLimit) != "2" {
+			t.Fatalf("RateLimit-Limit = %q, want 2", w.Header().Get(HeaderRateLimitLimit))
+		}
+	}
+
+	w := env.call(HeaderXToken, key, "acme")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("超出上限状态码 = %d, want 429", w.Code)
+	}
+	var problem struct {
+		Code string `json:"code"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &problem)
+	if problem.Code != services.ErrAPIKeyRateLimited.Code {
+		t.Fatalf("错误码 = %s, want %s", problem.Code, services.ErrAPIKeyRateLimited.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Fatal("超出上限时缺少 Retry-After")
+	}
+	for _, name := range []string{"X-RateLimit-Limit", "X-RateLimit-Remaining"} {
+		if w.Header().Get(name) != "" {
+			t.Fatalf("不应返回 %s", name)
+		}
+	}
+}
+
+func TestTokenOrAPIKeyMiddlewareUnlimitedKey(t *testing.T) {
+	env := newAPIKeyEnv(t)
+	unlimited := 0
+	key := env.createKey(t, &unlimited)
+
+	for i := 0; i < 5; i++ {
+		w := env.call(HeaderToken, key, "acme")
+		if w.Code != http.StatusOK {
+			t.Fatalf("第 %d 次请求状态码 = %d", i+1, w.Code)
+		}
+		if w.Header().Get(HeaderRateLimitLimit) != "" {
+			t.Fatal("不限流的密钥不应返回限流响应头")
+		}
+	}
+}
+
+func TestTokenOrAPIKeyMiddlewareRejectsInvalidKeys(t *testing.T) {
+	env := newAPIKeyEnv(t)
+	key := env.createKey(t, nil)
+
+	tests := []struct {
+		name   string
+		key    string
+		tenant string
+	}{
+		{name: "other tenant", key: key, tenant: "globex"},
+		{name: "tampered secret", key: key + "x", tenant: "acme"},
+		{name: "unknown scheme", key: "pk" + key[2:], tenant: "acme"},
+		{name: "missing secret", key: "sk_abcdef", tenant: "acme"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := env.call(HeaderToken, tt.key, tt.tenant); w.Code != http.StatusUnauthorized {
+				t.Fatalf("状态码 = %d, want 401", w.Code)
+			}
+		})
+	}
+}
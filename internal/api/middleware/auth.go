@@ -0,0 +1,99 @@
+// middleware/auth.go
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/services"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContextKeyClaims 上下文中保存访问令牌声明的键
+const ContextKeyClaims = "auth_claims"
+
+// errMFARequired 操作要求完成多因素认证
+var errMFARequired = utils.NewAppError("MFA_REQUIRED", http.StatusForbidden, "该操作需要完成多因素认证")
+
+// AuthMiddleware 访问令牌校验中间件，校验通过后将声明写入上下文
+// 优先使用 Authorization 头，没有时读取访问令牌 Cookie；Cookie 认证的写请求须携带 CSRF 令牌
+// 在 TenantMiddleware 之后使用时同时校验令牌所属租户
+func AuthMiddleware(tokens *services.TokenService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := extractBearerToken(c)
+		fromCookie := false
+		if tokenString == "" {
+			tokenString = accessTokenFromCookie(c)
+			fromCookie = tokenString != ""
+		}
+		if tokenString == "" {
+			utils.Unauthorized(c, "auth.token_required")
+			c.Abort()
+			return
+		}
+
+		claims, err := tokens.ParseAccessToken(tokenString)
+		if err != nil {
+			utils.RespondError(c, services.ErrInvalidToken)
+			c.Abort()
+			return
+		}
+
+		// 令牌只能在签发时所属的租户下使用
+		if tenantID, exists := c.Get(ContextKeyTenantID); exists && tenantID != claims.TenantID {
+			utils.Unauthorized(c, "auth.token_tenant_mismatch")
+			c.Abort()
+			return
+		}
+
+		if fromCookie {
+			if !checkCSRF(c, tokens, claims.SessionID) {
+				return
+			}
+			c.Set(ContextKeyCookieAuth, true)
+		}
+
+		c.Set(ContextKeyClaims, claims)
+		c.Next()
+	}
+}
+
+// RequireMFA 要求访问令牌来自完成多因素认证的登录，需在 AuthMiddleware 之后使用
+func RequireMFA() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := GetClaims(c)
+		if !ok {
+			utils.Unauthorized(c, "auth.token_required")
+			c.Abort()
+			return
+		}
+		if !claims.HasAMR(models.AMRMFA) {
+			utils.RespondError(c, errMFARequired)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// GetClaims 从上下文获取已校验的访问令牌声明
+func GetClaims(c *gin.Context) (*services.AccessClaims, bool) {
+	value, exists := c.Get(ContextKeyClaims)
+	if !exists {
+		return nil, false
+	}
+	claims, ok := value.(*services.AccessClaims)
+	return claims, ok
+}
+
+// extractBearerToken 从 Authorization 头中提取 Bearer 令牌
+func extractBearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
@@ -0,0 +1,32 @@
+// middleware/body_limit.go
+package middleware
+
+import (
+	"net/http"
+
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/i18n"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimitMiddleware 请求体大小限制中间件
+// 声明的长度超过上限时直接返回 413；未声明长度时限制实际读取的字节数，超出后读取报错，由绑定方法返回 413
+func BodyLimitMiddleware(cfg config.BodyLimitConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := routeSetting(cfg.Routes, c.Request.Method, c.FullPath(), cfg.Default)
+		if limit <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, "request.body_too_large", i18n.Params{"limit": limit})
+			c.Abort()
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
@@ -0,0 +1,62 @@
+// middleware/body_limit_test.go
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serveBodyLimit 以指定上限提交 JSON 请求体，chunked 为 true 时不声明长度
+func serveBodyLimit(cfg config.BodyLimitConfig, body string, chunked bool) *httptest.ResponseRecorder {
+	r := gin.New()
+	r.Use(BodyLimitMiddleware(cfg))
+	r.POST("/upload", func(c *gin.Context) {
+		var req struct {
+			Name string `json:"name"`
+		}
+		if utils.BindJSON(c, &req) {
+			c.Status(http.StatusOK)
+		}
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if chunked {
+		req.Body = io.NopCloser(strings.NewReader(body))
+		req.ContentLength = -1
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	body := `{"name": "` + strings.Repeat("a", 64) + `"}`
+	tests := []struct {
+		name    string
+		cfg     config.BodyLimitConfig
+		chunked bool
+		want    int
+	}{
+		{name: "within limit", cfg: config.BodyLimitConfig{Default: 1024}, want: http.StatusOK},
+		{name: "declared length over limit", cfg: config.BodyLimitConfig{Default: 16}, want: http.StatusRequestEntityTooLarge},
+		{name: "undeclared length over limit", cfg: config.BodyLimitConfig{Default: 16}, chunked: true, want: http.StatusRequestEntityTooLarge},
+		{name: "route override", cfg: config.BodyLimitConfig{Default: 16, Routes: map[string]int64{"POST /upload": 1024}}, want: http.StatusOK},
+		{name: "unlimited", cfg: config.BodyLimitConfig{}, chunked: true, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := serveBodyLimit(tt.cfg, body, tt.chunked); w.Code != tt.want {
+				t.Fatalf("状态码 = %d, want %d, body = %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
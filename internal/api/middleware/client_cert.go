@@ -0,0 +1,76 @@
+// middleware/client_cert.go
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContextKeyClientIdentity 上下文中保存客户端证书身份的键
+const ContextKeyClientIdentity = "client_identity"
+
+// ClientIdentity 双向 TLS 中已通过校验的客户端证书身份
+type ClientIdentity struct {
+	// Principal 客户端标识，优先级: URI SAN（如 SPIFFE ID）> DNS SAN > 邮箱 SAN > 主题 CN
+	Principal    string   `json:"principal"`
+	CommonName   string   `json:"common_name,omitempty"`
+	Organization []string `json:"organization,omitempty"`
+	DNSNames     []string `json:"dns_names,omitempty"`
+	URIs         []string `json:"uris,omitempty"`
+	Emails       []string `json:"emails,omitempty"`
+	SerialNumber string   `json:"serial_number"`
+	Fingerprint  string   `json:"fingerprint"` // 证书 DER 编码的 SHA-256
+}
+
+// ClientCertMiddleware 将已校验的客户端证书映射为请求身份
+// 只处理通过 CA 校验的证书，未提供证书或未启用双向 TLS 时不做任何处理
+func ClientCertMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		state := c.Request.TLS
+		if state != nil && len(state.VerifiedChains) > 0 && len(state.VerifiedChains[0]) > 0 {
+			c.Set(ContextKeyClientIdentity, newClientIdentity(state.VerifiedChains[0][0]))
+		}
+		c.Next()
+	}
+}
+
+// GetClientIdentity 从上下文中获取客户端证书身份
+func GetClientIdentity(c *gin.Context) (*ClientIdentity, bool) {
+	value, exists := c.Get(ContextKeyClientIdentity)
+	if !exists {
+		return nil, false
+	}
+	identity, ok := value.(*ClientIdentity)
+	return identity, ok
+}
+
+// newClientIdentity 从客户端证书提取身份信息
+func newClientIdentity(cert *x509.Certificate) *ClientIdentity {
+	sum := sha256.Sum256(cert.Raw)
+	identity := &ClientIdentity{
+		CommonName:   cert.Subject.CommonName,
+		Organization: cert.Subject.Organization,
+		DNSNames:     cert.DNSNames,
+		Emails:       cert.EmailAddresses,
+		SerialNumber: cert.SerialNumber.String(),
+		Fingerprint:  hex.EncodeToString(sum[:]),
+	}
+	for _, uri := range cert.URIs {
+		identity.URIs = append(identity.URIs, uri.String())
+	}
+
+	switch {
+	case len(identity.URIs) > 0:
+		identity.Principal = identity.URIs[0]
+	case len(identity.DNSNames) > 0:
+		identity.Principal = identity.DNSNames[0]
+	case len(identity.Emails) > 0:
+		identity.Principal = identity.Emails[0]
+	default:
+		identity.Principal = identity.CommonName
+	}
+	return identity
+}
@@ -3,6 +3,7 @@ package middleware
 
 import (
 	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/utils"
 	"net/http"
 	"strings"
 
@@ -42,9 +43,14 @@ func CORSMiddleware() gin.HandlerFunc {
 
 			c.Header("Access-Control-Allow-Origin", allowOrigin)
 			c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE, UPDATE")
-			c.Header("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept, Authorization, Token, X-Token")
-			c.Header("Access-Control-Expose-Headers", "Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers, Cache-Control, Content-Language, Content-Type")
-			c.Header("Access-Control-Allow-Credentials", "true")
+			c.Header("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept, Authorization, Token, X-Token, "+
+				config.GlobalConfig.Tenant.Header+", "+config.GlobalConfig.Cookie.CSRFHeader+", "+HeaderAuthMode+", "+utils.HeaderRequestID)
+			c.Header("Access-Control-Expose-Headers", "Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers, Cache-Control, Content-Language, Content-Type, Link, "+utils.HeaderRequestID)
+			c.Writer.Header().Add("Vary", "Origin")
+			// 携带 Cookie 的跨域请求只允许明确列出的源，通配符不能与凭证同时使用
+			if allowOrigin == origin {
+				c.Header("Access-Control-Allow-Credentials", "true")
+			}
 		}
 
 		// 处理预检请求
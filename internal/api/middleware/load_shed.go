@@ -0,0 +1,49 @@
+// middleware/load_shed.go
+package middleware
+
+import (
+	"strconv"
+
+	"gin_saas_auth/internal/metrics"
+	"gin_saas_auth/internal/services"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LoadShedMiddleware 过载保护中间件，并发超过自适应上限且排队失败时返回 503 和 Retry-After
+func LoadShedMiddleware(shedder *services.LoadShedder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if shedder == nil {
+			c.Next()
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		release, reason, err := shedder.Acquire(c.Request.Context(), route)
+		if err != nil {
+			metrics.LoadShedTotal.WithLabelValues(reason).Inc()
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(shedder.RetryAfter())))
+			utils.RespondError(c, err)
+			c.Abort()
+			return
+		}
+		defer func() {
+			release()
+			recordLoad(shedder)
+		}()
+
+		recordLoad(shedder)
+		c.Next()
+	}
+}
+
+// recordLoad 更新并发相关的监控指标
+func recordLoad(shedder *services.LoadShedder) {
+	stats := shedder.Stats()
+	metrics.ConcurrencyLimit.Set(float64(stats.Limit))
+	metrics.InFlightRequests.Set(float64(stats.InFlight))
+}
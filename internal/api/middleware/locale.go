@@ -0,0 +1,21 @@
+// middleware/locale.go
+package middleware
+
+import (
+	"gin_saas_auth/internal/i18n"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LocaleMiddleware 语言协商中间件
+// 按 Accept-Language 选择响应语言写入上下文，响应消息和错误说明据此本地化；响应按请求头区分缓存
+func LocaleMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lang := i18n.Negotiate(c.GetHeader("Accept-Language"))
+		c.Set(utils.ContextKeyLanguage, lang)
+		c.Header("Content-Language", lang)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Next()
+	}
+}
@@ -2,10 +2,10 @@
 package middleware
 
 import (
-	"fmt"
 	"io"
 	"os"
-	"time"
+
+	"gin_saas_auth/internal/utils"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -16,20 +16,29 @@ var (
 	FileLogger *logrus.Logger
 	// HTTPLogger HTTP请求日志实例
 	HTTPLogger *logrus.Logger
+	// AuditLogger 安全审计日志实例
+	AuditLogger *logrus.Logger
 )
 
 // LoggerMiddleware 日志中间件
 func LoggerMiddleware() gin.HandlerFunc {
 	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
 		// 使用HTTP专用日志记录器
-		HTTPLogger.WithFields(logrus.Fields{
+		tenantID, _ := param.Keys[ContextKeyTenantID].(string)
+		fields := logrus.Fields{
 			"status_code": param.StatusCode,
 			"latency":     param.Latency,
 			"client_ip":   param.ClientIP,
 			"method":      param.Method,
 			"path":        param.Path,
 			"user_agent":  param.Request.UserAgent(),
-		}).Info("HTTP Request")
+			"tenant_id":   tenantID,
+			"request_id":  param.Keys[utils.ContextKeyRequestID],
+		}
+		if identity, ok := param.Keys[ContextKeyClientIdentity].(*ClientIdentity); ok {
+			fields["client_cert"] = identity.Principal
+		}
+		HTTPLogger.WithFields(fields).Info("HTTP Request")
 
 		// 返回空字符串，因为我们已经通过logrus记录了日志
 		return ""
@@ -58,6 +67,13 @@ func InitLogger() {
 		TimestampFormat: "2006-01-02 15:04:05",
 	})
 
+	// 创建审计日志实例
+	AuditLogger = logrus.New()
+	AuditLogger.SetLevel(logrus.InfoLevel)
+	AuditLogger.SetFormatter(&logrus.JSONFormatter{
+		TimestampFormat: "2006-01-02 15:04:05",
+	})
+
 	// 确保日志目录存在
 	if err := os.MkdirAll("logs", 0755); err != nil {
 		logrus.Warn("创建日志目录失败:", err)
@@ -88,5 +104,32 @@ func setupFileOutput() {
 		HTTPLogger.SetOutput(io.MultiWriter(os.Stdout, httpLogFile))
 	}
 
+	// 审计日志文件，单独保存便于归档和转发
+	auditLogFile, err := os.OpenFile("logs/audit.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		logrus.Warn("无法打开审计日志文件:", err)
+		AuditLogger.SetOutput(os.Stdout)
+	} else {
+		AuditLogger.SetOutput(io.MultiWriter(os.Stdout, auditLogFile))
+	}
+
 	logrus.Info("日志系统初始化完成")
 }
+
+// SetLogLevel 运行时调整应用日志和 HTTP 请求日志的级别，审计日志不受影响
+func SetLogLevel(level string) error {
+	parsed, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	logrus.SetLevel(parsed)
+	FileLogger.SetLevel(parsed)
+	HTTPLogger.SetLevel(parsed)
+	logrus.WithField("level", parsed.String()).Warn("日志级别已调整")
+	return nil
+}
+
+// LogLevel 当前应用日志级别
+func LogLevel() string {
+	return logrus.GetLevel().String()
+}
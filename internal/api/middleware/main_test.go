@@ -0,0 +1,19 @@
+// middleware/main_test.go
+package middleware
+
+import (
+	"os"
+	"testing"
+
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestMain 初始化中间件依赖的监控指标
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	metrics.Init(&config.Config{})
+	os.Exit(m.Run())
+}
@@ -0,0 +1,33 @@
+// middleware/metrics.go
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"gin_saas_auth/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsMiddleware 记录 HTTP 请求指标，按路由模板和租户打标签
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		// 使用路由模板而不是实际路径，避免路径参数导致标签基数膨胀
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		tenantID := GetTenantID(c)
+
+		metrics.HTTPRequestsTotal.WithLabelValues(
+			c.Request.Method, route, strconv.Itoa(c.Writer.Status()), tenantID,
+		).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(
+			c.Request.Method, route, tenantID,
+		).Observe(time.Since(start).Seconds())
+	}
+}
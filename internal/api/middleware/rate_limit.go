@@ -0,0 +1,114 @@
+// middleware/rate_limit.go
+package middleware
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"gin_saas_auth/internal/metrics"
+	"gin_saas_auth/internal/services"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 限流响应头（IETF RateLimit 头字段草案）
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRateLimitPolicy    = "RateLimit-Policy"
+)
+
+// RateLimitKeyFunc 从请求中提取限流键，返回空字符串时本规则不计数
+type RateLimitKeyFunc func(c *gin.Context) string
+
+// RateLimitByIP 按客户端 IP 限流
+func RateLimitByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// RateLimitByTenant 按租户限流，需在 TenantMiddleware 之后使用
+func RateLimitByTenant(c *gin.Context) string {
+	if tenantID := GetTenantID(c); tenantID != "" {
+		return "tenant:" + tenantID
+	}
+	return ""
+}
+
+// RateLimitByUser 按登录用户限流，需在认证中间件之后使用
+func RateLimitByUser(c *gin.Context) string {
+	if claims, ok := GetClaims(c); ok {
+		return "user:" + claims.TenantID + ":" + claims.Subject
+	}
+	return ""
+}
+
+// RateLimitByAPIKey 按 API 密钥限流，使用访问令牌的请求不计数
+func RateLimitByAPIKey(c *gin.Context) string {
+	if claims, ok := GetClaims(c); ok && claims.IsAPIKey() {
+		return "apikey:" + claims.ID
+	}
+	return ""
+}
+
+// RateLimitByPrincipal 使用 API 密钥时按密钥限流，否则按用户限流
+func RateLimitByPrincipal(c *gin.Context) string {
+	if key := RateLimitByAPIKey(c); key != "" {
+		return key
+	}
+	return RateLimitByUser(c)
+}
+
+// RateLimitMiddleware 请求限流中间件
+// 放行时写入 RateLimit-* 响应头，多条规则同时生效时响应头反映剩余额度最少的规则；超限时返回 429 和 Retry-After
+func RateLimitMiddleware(limiter *services.RateLimiter, rule services.RateLimitRule, keyFunc RateLimitKeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limiter == nil || rule.Limit <= 0 {
+			c.Next()
+			return
+		}
+		key := keyFunc(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		if !allowRequest(c, limiter, rule, key) {
+			utils.ErrorResponse(c, 429, "request.rate_limited")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// allowRequest 按规则计数并写入限流响应头，超限时同时写入 Retry-After 并返回 false，错误响应由调用方写入
+func allowRequest(c *gin.Context, limiter *services.RateLimiter, rule services.RateLimitRule, key string) bool {
+	result := limiter.Allow(c.Request.Context(), rule, key)
+	if !result.Allowed {
+		metrics.RateLimitedTotal.WithLabelValues(rule.Name, GetTenantID(c)).Inc()
+		setRateLimitHeaders(c, rule, result.Remaining, result.Reset)
+		c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+		return false
+	}
+
+	if current, err := strconv.Atoi(c.Writer.Header().Get(HeaderRateLimitRemaining)); err != nil || result.Remaining < current {
+		setRateLimitHeaders(c, rule, result.Remaining, result.Reset)
+	}
+	return true
+}
+
+// setRateLimitHeaders 写入限流响应头
+func setRateLimitHeaders(c *gin.Context, rule services.RateLimitRule, remaining int, reset time.Duration) {
+	c.Header(HeaderRateLimitLimit, strconv.Itoa(rule.Limit))
+	c.Header(HeaderRateLimitRemaining, strconv.Itoa(remaining))
+	c.Header(HeaderRateLimitReset, strconv.Itoa(ceilSeconds(reset)))
+	c.Header(HeaderRateLimitPolicy, strconv.Itoa(rule.Limit)+";w="+strconv.Itoa(ceilSeconds(rule.Window)))
+}
+
+// ceilSeconds 向上取整到秒，至少为 1 秒
+func ceilSeconds(d time.Duration) int {
+	return int(math.Max(1, math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,52 @@
+// middleware/rbac.go
+package middleware
+
+import (
+	"gin_saas_auth/internal/services"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// RequirePermission 要求当前用户在当前租户下拥有指定权限，需在认证中间件之后使用
+func RequirePermission(rbac *services.RBACService, permission string) gin.HandlerFunc {
+	return requirePermission(permission, func(subject, tenantID string) bool {
+		return rbac.HasPermission(subject, tenantID, permission)
+	})
+}
+
+// RequireSystemPermission 要求当前用户拥有平台级权限（租户为 * 的角色绑定），用于可操作任意租户的接口
+func RequireSystemPermission(rbac *services.RBACService, permission string) gin.HandlerFunc {
+	return requirePermission(permission, func(subject, _ string) bool {
+		return rbac.HasSystemPermission(subject, permission)
+	})
+}
+
+// requirePermission 校验声明的权限范围并按 granted 判断角色授权
+func requirePermission(permission string, granted func(subject, tenantID string) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := GetClaims(c)
+		if !ok {
+			utils.Unauthorized(c, "auth.token_required")
+			c.Abort()
+			return
+		}
+
+		// API 密钥的权限为所属用户权限与密钥权限范围的交集
+		tenantID := GetTenantID(c)
+		if !claims.AllowsScope(permission) || !granted(claims.Subject, tenantID) {
+			logrus.WithFields(logrus.Fields{
+				"user_id":    claims.Subject,
+				"tenant_id":  tenantID,
+				"permission": permission,
+				"path":       c.FullPath(),
+			}).Warn("权限不足，拒绝访问")
+			utils.Forbidden(c, "auth.permission_denied")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,81 @@
+// middleware/rbac_test.go
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gin_saas_auth/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// tenantAdminPolicy tenant-admin 只在 acme 租户拥有租户管理权限，platform-admin 在全部租户拥有
+const tenantAdminPolicy = `{
+  "roles": {
+    "tenant_admin": {"permissions": ["tenants:*"]}
+  },
+  "bindings": [
+    {"subject": "tenant-admin", "role": "tenant_admin", "tenant": "acme"},
+    {"subject": "platform-admin", "role": "tenant_admin", "tenant": "*"}
+  ]
+}`
+
+// newTestRBAC 从策略内容创建访问控制服务
+func newTestRBAC(t *testing.T, policy string) *services.RBACService {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(path, []byte(policy), 0o600); err != nil {
+		t.Fatalf("写入策略文件失败: %v", err)
+	}
+	rbac, err := services.NewRBACService(path, nil)
+	if err != nil {
+		t.Fatalf("加载策略失败: %v", err)
+	}
+	return rbac
+}
+
+// servePermission 以指定用户和租户调用受权限保护的接口
+func servePermission(handler gin.HandlerFunc, subject, tenantID string) int {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/tenants/:id/suspend", func(c *gin.Context) {
+		c.Set(ContextKeyTenantID, tenantID)
+		c.Set(ContextKeyClaims, &services.AccessClaims{
+			RegisteredClaims: jwt.RegisteredClaims{Subject: subject},
+			TenantID:         tenantID,
+		})
+	}, handler, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/tenants/other/suspend", nil))
+	return w.Code
+}
+
+func TestRequireSystemPermission(t *testing.T) {
+	rbac := newTestRBAC(t, tenantAdminPolicy)
+	tests := []struct {
+		name     string
+		handler  gin.HandlerFunc
+		subject  string
+		tenantID string
+		want     int
+	}{
+		{name: "tenant binding in own tenant", handler: RequirePermission(rbac, "tenants:write"), subject: "tenant-admin", tenantID: "acme", want: http.StatusOK},
+		{name: "tenant binding is not system", handler: RequireSystemPermission(rbac, "tenants:write"), subject: "tenant-admin", tenantID: "acme", want: http.StatusForbidden},
+		{name: "system binding", handler: RequireSystemPermission(rbac, "tenants:write"), subject: "platform-admin", tenantID: "acme", want: http.StatusOK},
+		{name: "system binding from any tenant", handler: RequireSystemPermission(rbac, "tenants:read"), subject: "platform-admin", tenantID: "default", want: http.StatusOK},
+		{name: "unbound user", handler: RequireSystemPermission(rbac, "tenants:write"), subject: "someone", tenantID: "acme", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := servePermission(tt.handler, tt.subject, tt.tenantID); got != tt.want {
+				t.Fatalf("状态码 = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+// middleware/request_id.go
+package middleware
+
+import (
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRequestIDLength 沿用调用方请求 ID 的最大长度
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware 请求 ID 中间件
+// 沿用网关或调用方传入的 X-Request-ID，没有或格式不合法时生成新的 ID，写入上下文和响应头，并出现在日志和错误响应中
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(utils.HeaderRequestID)
+		if !validRequestID(requestID) {
+			requestID, _ = utils.GenerateRandomHex(16)
+		}
+		c.Set(utils.ContextKeyRequestID, requestID)
+		c.Header(utils.HeaderRequestID, requestID)
+		c.Next()
+	}
+}
+
+// GetRequestID 从上下文获取请求 ID
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(utils.ContextKeyRequestID)
+}
+
+// validRequestID 请求 ID 只允许字母、数字和 - _ . :，避免日志注入
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+// middleware/signature.go
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+
+	"gin_saas_auth/internal/i18n"
+	"gin_saas_auth/internal/services"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ContextKeySignatureKeyID 上下文中保存调用方签名密钥 ID 的键
+const ContextKeySignatureKeyID = "signature_key_id"
+
+// maxSignedBodyBytes 参与签名的请求体大小上限
+const maxSignedBodyBytes = 1 << 20
+
+// SignatureMiddleware 内部服务请求签名校验中间件
+// 签名覆盖请求方法、URI、请求体摘要、时间戳和随机数，时间窗口内的重复请求会被拒绝
+func SignatureMiddleware(signatures *services.SignatureService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			body, err = io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxSignedBodyBytes))
+			if err != nil {
+				utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, "request.body_too_large", i18n.Params{"limit": maxSignedBodyBytes})
+				c.Abort()
+				return
+			}
+			// 重新设置请求体，后续处理器仍可读取
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		timestamp, _ := strconv.ParseInt(c.GetHeader(utils.HeaderSignatureTimestamp), 10, 64)
+		keyID := c.GetHeader(utils.HeaderSignatureKeyID)
+		err := signatures.Verify(c.Request.Context(), keyID, c.GetHeader(utils.HeaderSignature), utils.SignedRequest{
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.RequestURI(),
+			BodyHash:  utils.HashBody(body),
+			Timestamp: timestamp,
+			Nonce:     c.GetHeader(utils.HeaderSignatureNonce),
+		})
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"key_id":    keyID,
+				"path":      c.Request.URL.Path,
+				"client_ip": c.ClientIP(),
+				"reason":    err.Error(),
+			}).Warn("内部请求签名校验失败")
+
+			utils.RespondError(c, err)
+			c.Abort()
+			return
+		}
+
+		c.Set(ContextKeySignatureKeyID, keyID)
+		c.Next()
+	}
+}
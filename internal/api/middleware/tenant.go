@@ -0,0 +1,99 @@
+// middleware/tenant.go
+package middleware
+
+import (
+	"net"
+	"strings"
+
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/services"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ContextKeyTenantID 上下文中保存当前租户 ID 的键
+const ContextKeyTenantID = "tenant_id"
+
+// TenantMiddleware 租户解析中间件
+// 依次从请求头、SERVER_DOMAIN 的子域名、访问令牌的 tid 声明中识别租户，都没有时使用默认租户
+// 租户不存在或已停用时拒绝请求，识别成功后将租户 ID 写入上下文
+func TenantMiddleware(tenants *services.TenantService, tokens *services.TokenService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID, source := resolveTenantID(c, tokens)
+		if tenantID == "" {
+			utils.BadRequest(c, "tenant.id_required")
+			c.Abort()
+			return
+		}
+
+		if _, err := tenants.Resolve(c.Request.Context(), tenantID); err != nil {
+			utils.RespondError(c, err)
+			logrus.WithFields(logrus.Fields{
+				"tenant_id": tenantID,
+				"source":    source,
+				"path":      c.Request.URL.Path,
+			}).Warn("租户无效，拒绝请求")
+			c.Abort()
+			return
+		}
+
+		c.Set(ContextKeyTenantID, tenantID)
+		c.Next()
+	}
+}
+
+// GetTenantID 从上下文获取当前租户 ID
+func GetTenantID(c *gin.Context) string {
+	return c.GetString(ContextKeyTenantID)
+}
+
+// resolveTenantID 按优先级识别租户，返回租户 ID 及其来源
+func resolveTenantID(c *gin.Context, tokens *services.TokenService) (string, string) {
+	cfg := config.GlobalConfig
+
+	if header := strings.TrimSpace(c.GetHeader(cfg.Tenant.Header)); header != "" {
+		return strings.ToLower(header), "header"
+	}
+
+	if sub := subdomainOf(c.Request.Host, cfg.Server.Domain); sub != "" {
+		return sub, "subdomain"
+	}
+
+	// 令牌仅用于识别租户，有效性由 AuthMiddleware 校验
+	if tokenString := extractBearerToken(c); tokenString != "" {
+		if claims, err := tokens.ParseAccessToken(tokenString); err == nil && claims.TenantID != "" {
+			return claims.TenantID, "token"
+		}
+	}
+
+	return cfg.Tenant.DefaultTenant, "default"
+}
+
+// subdomainOf 提取 host 相对于 domain 的一级子域名，例如 acme.auth.example.com 相对于 auth.example.com 为 acme
+func subdomainOf(host, domain string) string {
+	host = strings.ToLower(stripPort(host))
+	domain = strings.ToLower(stripPort(domain))
+	if domain == "" || net.ParseIP(domain) != nil {
+		return ""
+	}
+
+	suffix := "." + domain
+	if !strings.HasSuffix(host, suffix) {
+		return ""
+	}
+	sub := strings.TrimSuffix(host, suffix)
+	if strings.Contains(sub, ".") {
+		return ""
+	}
+	return sub
+}
+
+// stripPort 去掉 host 中的端口
+func stripPort(hostport string) string {
+	if host, _, err := net.SplitHostPort(hostport); err == nil {
+		return host
+	}
+	return hostport
+}
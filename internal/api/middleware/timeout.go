@@ -0,0 +1,144 @@
+// middleware/timeout.go
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/metrics"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// TimeoutMiddleware 请求处理超时中间件
+// 按路由为请求上下文设置截止时间，数据库、Redis 等下游调用随之取消；
+// 超时后尚未返回结果或因取消而返回 5xx 的请求统一改为 504，超时前已成功处理的结果照常返回
+func TimeoutMiddleware(cfg config.RequestTimeoutConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout := routeSetting(cfg.Routes, c.Request.Method, c.FullPath(), cfg.Default)
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		// 响应先写入缓冲区，超时后可以整体替换为 504
+		original := c.Writer
+		buffered := &timeoutWriter{
+			ResponseWriter: original,
+			header:         original.Header().Clone(),
+			status:         original.Status(),
+		}
+		c.Writer = buffered
+		defer func() {
+			c.Writer = original
+		}()
+
+		c.Next()
+
+		c.Writer = original
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && (!buffered.written || buffered.status >= http.StatusInternalServerError) {
+			route := c.FullPath()
+			metrics.RequestTimeoutsTotal.WithLabelValues(c.Request.Method, route).Inc()
+			logrus.WithFields(logrus.Fields{
+				"method":    c.Request.Method,
+				"route":     route,
+				"timeout":   timeout,
+				"tenant_id": GetTenantID(c),
+			}).Warn("request.timeout")
+			utils.ErrorResponse(c, http.StatusGatewayTimeout, "request.timeout")
+			return
+		}
+		buffered.flush()
+	}
+}
+
+// routeSetting 查找按路由覆盖的配置，依次匹配 "方法 路由模板"、"路由模板"，都未配置时使用默认值
+func routeSetting[T any](routes map[string]T, method, route string, fallback T) T {
+	if value, ok := routes[method+" "+route]; ok {
+		return value
+	}
+	if value, ok := routes[route]; ok {
+		return value
+	}
+	return fallback
+}
+
+// timeoutWriter 缓存响应头、状态码和响应体，处理完成后再写入原始连接
+type timeoutWriter struct {
+	gin.ResponseWriter
+	header  http.Header
+	body    bytes.Buffer
+	status  int
+	written bool
+}
+
+// Header 返回缓存的响应头
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader 记录状态码
+func (w *timeoutWriter) WriteHeader(code int) {
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+// WriteHeaderNow 标记响应已开始写入
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+// Write 写入响应体缓冲区
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+// WriteString 写入响应体缓冲区
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+// Status 返回记录的状态码
+func (w *timeoutWriter) Status() int {
+	return w.status
+}
+
+// Size 返回已写入的响应体大小，未写入时为 -1
+func (w *timeoutWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+// Written 响应是否已开始写入
+func (w *timeoutWriter) Written() bool {
+	return w.written
+}
+
+// Flush 响应体在处理完成后统一写出，这里不做任何操作
+func (w *timeoutWriter) Flush() {}
+
+// flush 将缓存的响应写入原始连接
+func (w *timeoutWriter) flush() {
+	header := w.ResponseWriter.Header()
+	for key, values := range w.header {
+		header[key] = values
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.body.Len() > 0 {
+		w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
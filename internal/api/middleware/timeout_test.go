@@ -0,0 +1,149 @@
+// middleware/timeout_test.go
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gin_saas_auth/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serveTimeout 以指定超时配置调用 GET /slow
+func serveTimeout(cfg config.RequestTimeoutConfig, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+	r := gin.New()
+	r.Use(TimeoutMiddleware(cfg))
+	r.GET("/slow", handler)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	return w
+}
+
+// waitDeadline 阻塞到请求上下文超时
+func waitDeadline(c *gin.Context) {
+	<-c.Request.Context().Done()
+}
+
+func TestTimeoutMiddlewareFlushesFastResponse(t *testing.T) {
+	w := serveTimeout(config.RequestTimeoutConfig{Default: time.Second}, func(c *gin.Context) {
+		c.Header("X-Handler", "fast")
+		c.String(http.StatusCreated, "created")
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("状态码 = %d, want 201", w.Code)
+	}
+	if w.Header().Get("X-Handler") != "fast" {
+		t.Fatalf("X-Handler = %q, want fast", w.Header().Get("X-Handler"))
+	}
+	if w.Body.String() != "created" {
+		t.Fatalf("body = %q, want created", w.Body.String())
+	}
+}
+
+func TestTimeoutMiddlewareReturnsGatewayTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler gin.HandlerFunc
+	}{
+		{name: "no response", handler: waitDeadline},
+		{name: "server error after cancel", handler: func(c *gin.Context) {
+			waitDeadline(c)
+			c.Header("X-Partial", "1")
+			c.String(http.StatusInternalServerError, "context canceled")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveTimeout(config.RequestTimeoutConfig{Default: 10 * time.Millisecond}, tt.handler)
+			if w.Code != http.StatusGatewayTimeout {
+				t.Fatalf("状态码 = %d, want 504", w.Code)
+			}
+			if w.Header().Get("X-Partial") != "" {
+				t.Fatal("504 响应不应包含处理函数设置的响应头")
+			}
+		})
+	}
+}
+
+func TestTimeoutMiddlewareKeepsSuccessAfterDeadline(t *testing.T) {
+	w := serveTimeout(config.RequestTimeoutConfig{Default: 10 * time.Millisecond}, func(c *gin.Context) {
+		waitDeadline(c)
+		c.String(http.StatusOK, "done")
+	})
+	if w.Code != http.StatusOK || w.Body.String() != "done" {
+		t.Fatalf("状态码 = %d, body = %q, want 200 done", w.Code, w.Body.String())
+	}
+}
+
+func TestTimeoutMiddlewareRouteOverride(t *testing.T) {
+	handler := func(c *gin.Context) {
+		if _, ok := c.Request.Context().Deadline(); ok {
+			c.Status(http.StatusGatewayTimeout)
+			return
+		}
+		c.Status(http.StatusOK)
+	}
+	tests := []struct {
+		name   string
+		routes map[string]time.Duration
+	}{
+		{name: "method and route", routes: map[string]time.Duration{"GET /slow": 0}},
+		{name: "route only", routes: map[string]time.Duration{"/slow": 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveTimeout(config.RequestTimeoutConfig{Default: time.Second, Routes: tt.routes}, handler)
+			if w.Code != http.StatusOK {
+				t.Fatalf("状态码 = %d, want 200，覆盖为 0 时不应设置截止时间", w.Code)
+			}
+		})
+	}
+}
+
+func TestRouteSetting(t *testing.T) {
+	routes := map[string]time.Duration{
+		"POST /upload": time.Minute,
+		"/upload":      30 * time.Second,
+	}
+	tests := []struct {
+		method string
+		route  string
+		want   time.Duration
+	}{
+		{method: http.MethodPost, route: "/upload", want: time.Minute},
+		{method: http.MethodPut, route: "/upload", want: 30 * time.Second},
+		{method: http.MethodGet, route: "/users", want: 5 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := routeSetting(routes, tt.method, tt.route, 5*time.Second); got != tt.want {
+			t.Errorf("routeSetting(%s, %s) = %v, want %v", tt.method, tt.route, got, tt.want)
+		}
+	}
+}
+
+func TestTimeoutWriterBuffersResponse(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	w := &timeoutWriter{ResponseWriter: c.Writer, header: http.Header{}, status: http.StatusOK}
+
+	if w.Written() || w.Size() != -1 {
+		t.Fatalf("未写入时 Written = %v, Size = %d, want false, -1", w.Written(), w.Size())
+	}
+	w.WriteHeader(http.StatusAccepted)
+	if _, err := w.WriteString("ok"); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	w.WriteHeader(http.StatusInternalServerError)
+	if w.Status() != http.StatusAccepted {
+		t.Fatalf("写入后修改状态码 Status = %d, want 202", w.Status())
+	}
+	if !w.Written() || w.Size() != 2 {
+		t.Fatalf("写入后 Written = %v, Size = %d, want true, 2", w.Written(), w.Size())
+	}
+	if c.Writer.Written() {
+		t.Fatal("flush 前不应写入原始连接")
+	}
+}
@@ -0,0 +1,104 @@
+package v1
+
+import (
+	"net/http/pprof"
+	"reflect"
+	"strings"
+	"time"
+
+	"gin_saas_auth/internal/api/middleware"
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// redactedValue 敏感配置项的展示值
+const redactedValue = "******"
+
+// LogLevelRequest 调整日志级别请求
+type LogLevelRequest struct {
+	Level string `json:"level" binding:"required"`
+}
+
+// LogLevelHandler 查询当前日志级别
+func LogLevelHandler(c *gin.Context) {
+	utils.SuccessWithData(c, gin.H{"level": middleware.LogLevel()})
+}
+
+// SetLogLevelHandler 运行时调整日志级别，重启后恢复
+func SetLogLevelHandler(c *gin.Context) {
+	var req LogLevelRequest
+	if !utils.BindJSON(c, &req) {
+		return
+	}
+	if err := middleware.SetLogLevel(req.Level); err != nil {
+		utils.BadRequest(c, "admin.invalid_log_level")
+		return
+	}
+	utils.SuccessWithData(c, gin.H{"level": middleware.LogLevel()})
+}
+
+// ConfigHandler 查看当前生效的配置，密钥、密码等敏感项已脱敏
+func ConfigHandler(c *gin.Context) {
+	utils.SuccessWithData(c, redactConfig(reflect.ValueOf(*config.GlobalConfig), ""))
+}
+
+// PprofHandler 性能分析接口，按路径分发到 net/http/pprof 的处理器
+func PprofHandler(c *gin.Context) {
+	switch c.Param("profile") {
+	case "/cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "/profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "/symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "/trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		pprof.Index(c.Writer, c.Request)
+	}
+}
+
+// redactConfig 将配置转换为可序列化的结构，敏感字段只显示是否已配置
+func redactConfig(value reflect.Value, name string) interface{} {
+	if duration, ok := value.Interface().(time.Duration); ok {
+		return duration.String()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		result := make(map[string]interface{}, value.NumField())
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if field.IsExported() {
+				result[field.Name] = redactConfig(value.Field(i), field.Name)
+			}
+		}
+		return result
+	case reflect.String:
+		if isSensitiveConfig(name) && value.String() != "" {
+			return redactedValue
+		}
+	case reflect.Map:
+		if isSensitiveConfig(name) {
+			result := make(map[string]string, value.Len())
+			iter := value.MapRange()
+			for iter.Next() {
+				result[iter.Key().String()] = redactedValue
+			}
+			return result
+		}
+	}
+	return value.Interface()
+}
+
+// isSensitiveConfig 判断配置字段是否为密钥、密码等敏感项
+func isSensitiveConfig(name string) bool {
+	for _, marker := range []string{"Secret", "Password", "Token", "DSN"} {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return strings.HasSuffix(name, "Key") || name == "Keys"
+}
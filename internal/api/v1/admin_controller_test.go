@@ -0,0 +1,109 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gin_saas_auth/internal/api/middleware"
+	"gin_saas_auth/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// newAdminTestRouter 创建启用 pprof 的运维管理端口路由
+func newAdminTestRouter(host, token string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	// 调整日志级别接口会修改文件日志和请求日志，测试中不写日志文件
+	middleware.FileLogger = logrus.New()
+	middleware.HTTPLogger = logrus.New()
+	cfg := &config.Config{Admin: config.AdminConfig{
+		Enabled: true,
+		Host:    host,
+		Port:    9090,
+		Token:   token,
+		Pprof:   true,
+	}}
+	return SetupAdminRouter(cfg, nil, nil)
+}
+
+// adminRequests 需要管理令牌的接口
+var adminRequests = []struct {
+	method string
+	path   string
+	body   string
+}{
+	{method: http.MethodGet, path: "/debug/pprof/"},
+	{method: http.MethodGet, path: "/debug/pprof/cmdline"},
+	{method: http.MethodGet, path: "/log-level"},
+	{method: http.MethodPut, path: "/log-level", body: `{"level":"info"}`},
+}
+
+// serveAdmin 向管理端口路由发送请求
+func serveAdmin(r *gin.Engine, method, path, body, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAdminRejectsWithoutTokenOnNonLoopbackHost(t *testing.T) {
+	for _, host := range []string{"0.0.0.0", "10.0.0.5", ""} {
+		r := newAdminTestRouter(host, "")
+		for _, tt := range adminRequests {
+			if w := serveAdmin(r, tt.method, tt.path, tt.body, ""); w.Code != http.StatusUnauthorized {
+				t.Errorf("host %q %s %s 状态码 = %d, want 401", host, tt.method, tt.path, w.Code)
+			}
+		}
+	}
+}
+
+func TestAdminAllowsWithoutTokenOnLoopbackHost(t *testing.T) {
+	for _, host := range []string{"127.0.0.1", "::1", "localhost"} {
+		r := newAdminTestRouter(host, "")
+		for _, tt := range adminRequests {
+			if w := serveAdmin(r, tt.method, tt.path, tt.body, ""); w.Code != http.StatusOK {
+				t.Errorf("host %q %s %s 状态码 = %d, want 200", host, tt.method, tt.path, w.Code)
+			}
+		}
+	}
+}
+
+func TestAdminRequiresConfiguredToken(t *testing.T) {
+	r := newAdminTestRouter("0.0.0.0", "admin-secret")
+	for _, tt := range adminRequests {
+		if w := serveAdmin(r, tt.method, tt.path, tt.body, ""); w.Code != http.StatusUnauthorized {
+			t.Errorf("未携带令牌 %s %s 状态码 = %d, want 401", tt.method, tt.path, w.Code)
+		}
+		if w := serveAdmin(r, tt.method, tt.path, tt.body, "wrong"); w.Code != http.StatusUnauthorized {
+			t.Errorf("令牌错误 %s %s 状态码 = %d, want 401", tt.method, tt.path, w.Code)
+		}
+		if w := serveAdmin(r, tt.method, tt.path, tt.body, "admin-secret"); w.Code != http.StatusOK {
+			t.Errorf("令牌正确 %s %s 状态码 = %d, want 200", tt.method, tt.path, w.Code)
+		}
+	}
+}
+
+func TestAdminPprofDisabledByDefault(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	if cfg.Admin.Pprof || !cfg.IsAdminLoopback() {
+		t.Fatalf("默认管理端口配置 host = %q, pprof = %v, want 本机地址且不启用 pprof", cfg.Admin.Host, cfg.Admin.Pprof)
+	}
+
+	cfg.Admin.Enabled = true
+	if w := serveAdmin(SetupAdminRouter(cfg, nil, nil), http.MethodGet, "/debug/pprof/", "", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("默认配置 pprof 状态码 = %d, want 404", w.Code)
+	}
+}
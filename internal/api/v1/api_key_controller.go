@@ -0,0 +1,150 @@
+package v1
+
+import (
+	"strings"
+	"time"
+
+	"gin_saas_auth/internal/api/middleware"
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/services"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAPIKeyRequest 创建 API 密钥请求
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1"`
+	RateLimit *int       `json:"rate_limit"` // 每分钟请求上限，不传使用默认值，0 表示不限制
+	ExpiresAt *time.Time `json:"expires_at"` // 不传表示永不过期
+}
+
+// apiKeyListSpec API 密钥列表的排序和过滤字段
+var apiKeyListSpec = utils.ListSpec[*models.APIKey]{
+	Fields: map[string]utils.ListField[*models.APIKey]{
+		"name": {
+			Type:     utils.FieldString,
+			Value:    func(k *models.APIKey) any { return k.Name },
+			Sortable: true,
+			Filters:  []utils.FilterOp{utils.FilterEq, utils.FilterContains},
+		},
+		"revoked": {
+			Type:    utils.FieldBool,
+			Value:   func(k *models.APIKey) any { return k.RevokedAt != nil },
+			Filters: []utils.FilterOp{utils.FilterEq},
+		},
+		"created_at": {
+			Type:     utils.FieldTime,
+			Value:    func(k *models.APIKey) any { return k.CreatedAt },
+			Sortable: true,
+			Filters:  []utils.FilterOp{utils.FilterGte, utils.FilterLt},
+		},
+		"last_used_at": {
+			Type:     utils.FieldTime,
+			Value:    func(k *models.APIKey) any { return k.LastUsedAt },
+			Sortable: true,
+		},
+		"expires_at": {
+			Type:     utils.FieldTime,
+			Value:    func(k *models.APIKey) any { return k.ExpiresAt },
+			Sortable: true,
+		},
+	},
+	Key:         func(k *models.APIKey) string { return k.ID },
+	DefaultSort: "-created_at",
+}
+
+// APIKeyController API 密钥管理控制器
+type APIKeyController struct {
+	apiKeyService *services.APIKeyService
+	userService   *services.UserService
+	audit         services.AuditRecorder
+}
+
+// NewAPIKeyController 创建 API 密钥管理控制器
+func NewAPIKeyController(apiKeyService *services.APIKeyService, userService *services.UserService, audit services.AuditRecorder) *APIKeyController {
+	return &APIKeyController{
+		apiKeyService: apiKeyService,
+		userService:   userService,
+		audit:         audit,
+	}
+}
+
+// List 分页查询当前用户的 API 密钥
+// 查询参数: page, page_size, cursor, sort (name, created_at, last_used_at, expires_at), name, revoked, created_at[gte|lt]
+func (ctl *APIKeyController) List(c *gin.Context) {
+	query, ok := utils.ParseListQuery(c, apiKeyListSpec)
+	if !ok {
+		return
+	}
+	claims, _ := middleware.GetClaims(c)
+
+	keys, err := ctl.apiKeyService.List(c.Request.Context(), claims.Subject)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	page, info := apiKeyListSpec.Apply(keys, query)
+	utils.SuccessWithPage(c, page, info)
+}
+
+// Create 创建 API 密钥，明文密钥只在响应中返回一次
+func (ctl *APIKeyController) Create(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if !utils.BindJSON(c, &req) {
+		return
+	}
+
+	claims, _ := middleware.GetClaims(c)
+	user, err := ctl.userService.GetUser(c.Request.Context(), claims.Subject)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	created, err := ctl.apiKeyService.Create(c.Request.Context(), user, services.CreateAPIKeyInput{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		RateLimit: req.RateLimit,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	recordAudit(c, ctl.audit, models.AuditAPIKeyCreated, created.ID, map[string]string{
+		"name":   created.Name,
+		"prefix": created.Prefix,
+		"scopes": strings.Join(created.Scopes, ","),
+	})
+	utils.SuccessResponse(c, 201, "api_key.created", created)
+}
+
+// Get 查询当前用户的指定 API 密钥
+func (ctl *APIKeyController) Get(c *gin.Context) {
+	claims, _ := middleware.GetClaims(c)
+
+	key, err := ctl.apiKeyService.Get(c.Request.Context(), claims.Subject, c.Param("id"))
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	utils.SuccessWithData(c, key)
+}
+
+// Revoke 撤销当前用户的指定 API 密钥
+func (ctl *APIKeyController) Revoke(c *gin.Context) {
+	claims, _ := middleware.GetClaims(c)
+
+	if err := ctl.apiKeyService.Revoke(c.Request.Context(), claims.Subject, c.Param("id")); err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	recordAudit(c, ctl.audit, models.AuditAPIKeyRevoked, c.Param("id"), nil)
+
+	utils.Success(c, "api_key.revoked")
+}
@@ -0,0 +1,162 @@
+package v1
+
+import (
+	"strconv"
+	"time"
+
+	"gin_saas_auth/internal/api/middleware"
+	"gin_saas_auth/internal/i18n"
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/repository"
+	"gin_saas_auth/internal/services"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// 审计事件查询条数
+const (
+	auditDefaultLimit = 100
+	auditMaxLimit     = 1000
+)
+
+// AuditController 审计日志控制器
+type AuditController struct {
+	auditService *services.AuditService
+	rbacService  *services.RBACService
+}
+
+// NewAuditController 创建审计日志控制器
+func NewAuditController(auditService *services.AuditService, rbacService *services.RBACService) *AuditController {
+	return &AuditController{auditService: auditService, rbacService: rbacService}
+}
+
+// List 查询审计事件，按序号升序返回
+// 查询参数: actor_id, tenant_id, type, from, to (RFC3339), after_seq, limit
+// 默认只查询当前租户；查询其他租户或全部租户（tenant_id=*）需要在目标租户拥有 audit:read 权限
+func (ctl *AuditController) List(c *gin.Context) {
+	filter := repository.AuditFilter{
+		TenantID: middleware.GetTenantID(c),
+		ActorID:  c.Query("actor_id"),
+		Type:     c.Query("type"),
+		Limit:    auditDefaultLimit,
+	}
+
+	if tenantID := c.Query("tenant_id"); tenantID != "" && tenantID != filter.TenantID {
+		claims, _ := middleware.GetClaims(c)
+		if !ctl.rbacService.HasPermission(claims.Subject, tenantID, "audit:read") || !claims.AllowsScope("audit:read") {
+			utils.Forbidden(c, "audit.tenant_forbidden")
+			return
+		}
+		filter.TenantID = tenantID
+		if tenantID == "*" {
+			filter.TenantID = ""
+		}
+	}
+
+	var err error
+	if filter.From, err = parseTimeQuery(c, "from"); err != nil {
+		utils.BadRequest(c, "audit.invalid_time", i18n.Params{"param": "from"})
+		return
+	}
+	if filter.To, err = parseTimeQuery(c, "to"); err != nil {
+		utils.BadRequest(c, "audit.invalid_time", i18n.Params{"param": "to"})
+		return
+	}
+	if v := c.Query("after_seq"); v != "" {
+		if filter.AfterSeq, err = strconv.ParseInt(v, 10, 64); err != nil || filter.AfterSeq < 0 {
+			utils.BadRequest(c, "audit.invalid_after_seq")
+			return
+		}
+	}
+	if v := c.Query("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit <= 0 {
+			utils.BadRequest(c, "audit.invalid_limit")
+			return
+		}
+		if filter.Limit > auditMaxLimit {
+			filter.Limit = auditMaxLimit
+		}
+	}
+
+	events, err := ctl.auditService.Query(c.Request.Context(), filter)
+	if err != nil {
+		logrus.WithError(err).Error("查询审计事件失败")
+		utils.InternalServerError(c, "request.internal_error")
+		return
+	}
+
+	// 返回下一页游标，客户端以 after_seq 继续查询
+	var nextSeq int64
+	if len(events) == filter.Limit {
+		nextSeq = events[len(events)-1].Seq
+	}
+	utils.SuccessWithData(c, gin.H{
+		"events":   events,
+		"next_seq": nextSeq,
+	})
+}
+
+// Verify 校验审计日志哈希链是否完整
+func (ctl *AuditController) Verify(c *gin.Context) {
+	result, err := ctl.auditService.Verify(c.Request.Context())
+	if err != nil {
+		logrus.WithError(err).Error("校验审计日志失败")
+		utils.InternalServerError(c, "request.internal_error")
+		return
+	}
+
+	if !result.Valid {
+		logrus.WithFields(logrus.Fields{
+			"broken_seq": result.BrokenSeq,
+			"hint":       result.BrokenHint,
+		}).Error("审计日志哈希链校验失败，日志可能被篡改")
+	}
+	utils.SuccessWithData(c, result)
+}
+
+// parseTimeQuery 解析 RFC3339 格式的时间查询参数，参数为空时返回零值
+func parseTimeQuery(c *gin.Context, name string) (time.Time, error) {
+	v := c.Query(name)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+// auditEvent 根据当前请求构造审计事件，操作者为访问令牌对应的用户
+func auditEvent(c *gin.Context, eventType, subject string, metadata map[string]string) *models.AuditEvent {
+	event := &models.AuditEvent{
+		Type:       eventType,
+		TenantID:   middleware.GetTenantID(c),
+		Subject:    subject,
+		ClientIP:   c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+		Metadata:   metadata,
+		OccurredAt: time.Now(),
+	}
+	if claims, ok := middleware.GetClaims(c); ok {
+		event.ActorID = claims.Subject
+		// 通过 API 密钥操作时记录密钥 ID，便于追溯
+		if claims.IsAPIKey() {
+			if event.Metadata == nil {
+				event.Metadata = map[string]string{}
+			}
+			event.Metadata["api_key_id"] = claims.ID
+		}
+	}
+	// 双向 TLS 连接记录客户端证书身份
+	if identity, ok := middleware.GetClientIdentity(c); ok {
+		if event.Metadata == nil {
+			event.Metadata = map[string]string{}
+		}
+		event.Metadata["client_cert"] = identity.Principal
+	}
+	return event
+}
+
+// recordAudit 记录由当前请求触发的审计事件
+func recordAudit(c *gin.Context, audit services.AuditRecorder, eventType, subject string, metadata map[string]string) {
+	audit.Record(c.Request.Context(), auditEvent(c, eventType, subject, metadata))
+}
@@ -0,0 +1,414 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gin_saas_auth/internal/api/middleware"
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/services"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// RegisterRequest 注册请求
+type RegisterRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// LoginRequest 登录请求，密码已过期时须同时提供新密码
+type LoginRequest struct {
+	Email       string `json:"email" binding:"required,email"`
+	Password    string `json:"password" binding:"required"`
+	NewPassword string `json:"new_password"`
+}
+
+// ChangePasswordRequest 修改密码请求
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// RefreshRequest 刷新令牌请求，Cookie 会话可省略请求体，从 Cookie 读取刷新令牌
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// ForgotPasswordRequest 忘记密码请求，邮箱发送重置链接，已绑定的手机号发送短信验证码
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"omitempty,email"`
+	Phone string `json:"phone"`
+}
+
+// ResetPasswordRequest 重置密码请求，使用邮件中的重置令牌或手机号加短信验证码
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	Phone       string `json:"phone"`
+	Code        string `json:"code"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// errResetCredentialRequired 重置密码时未提供重置令牌或手机号和验证码
+var errResetCredentialRequired = utils.NewAppError("RESET_CREDENTIAL_REQUIRED", http.StatusBadRequest, "请提供重置令牌，或手机号和短信验证码")
+
+// AuthController 认证控制器
+type AuthController struct {
+	userService    *services.UserService
+	sessionService *services.SessionService
+	mfaService     *services.MFAService
+	lockout        *services.LockoutService
+	verification   *services.VerificationService
+	audit          services.AuditRecorder
+}
+
+// NewAuthController 创建认证控制器
+func NewAuthController(userService *services.UserService, sessionService *services.SessionService, mfaService *services.MFAService, lockout *services.LockoutService, verification *services.VerificationService, audit services.AuditRecorder) *AuthController {
+	return &AuthController{
+		userService:    userService,
+		sessionService: sessionService,
+		mfaService:     mfaService,
+		lockout:        lockout,
+		verification:   verification,
+		audit:          audit,
+	}
+}
+
+// Register 用户注册，注册成功后向邮箱发送验证码
+func (ctl *AuthController) Register(c *gin.Context) {
+	var req RegisterRequest
+	if !utils.BindJSON(c, &req) {
+		return
+	}
+
+	user, err := ctl.userService.Register(c.Request.Context(), middleware.GetTenantID(c), req.Email, req.Password)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	// 验证邮件发送失败不影响注册，用户可通过 /auth/verify/email/send 重新获取
+	if ctl.verification.Supports(models.ChannelEmail) {
+		_, err := ctl.verification.SendCode(c.Request.Context(), services.VerificationRequest{
+			TenantID:    user.TenantID,
+			UserID:      user.ID,
+			Channel:     models.ChannelEmail,
+			Destination: user.Email,
+			Purpose:     models.PurposeVerify,
+		})
+		if err != nil {
+			logrus.WithError(err).WithField("user_id", user.ID).Warn("发送注册验证邮件失败")
+		}
+	}
+
+	utils.SuccessResponse(c, 201, "auth.registered", user)
+}
+
+// Login 用户登录
+// 已启用动态码认证的用户返回 MFA 挑战，需调用 /auth/mfa/verify 完成登录
+// 连续失败时按账户和 IP 限制尝试频率，详见 LockoutService
+func (ctl *AuthController) Login(c *gin.Context) {
+	var req LoginRequest
+	if !utils.BindJSON(c, &req) {
+		return
+	}
+
+	attempt := loginAttempt(c, req.Email)
+	if decision := ctl.lockout.Check(c.Request.Context(), attempt); decision.Locked {
+		respondLocked(c, decision)
+		return
+	}
+
+	user, err := ctl.userService.Authenticate(c.Request.Context(), attempt.TenantID, req.Email, req.Password)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			respondLoginFailure(c, err, ctl.lockout.RecordFailure(c.Request.Context(), attempt))
+			return
+		}
+		utils.RespondError(c, err)
+		return
+	}
+	ctl.lockout.RecordSuccess(c.Request.Context(), attempt, user.ID)
+
+	if config.GlobalConfig.Verification.RequireEmail && !user.IsEmailVerified() {
+		utils.RespondError(c, services.ErrEmailNotVerified)
+		return
+	}
+	if !ctl.renewExpiredPassword(c, user, &req) {
+		return
+	}
+
+	mfaEnabled, err := ctl.mfaService.IsEnabled(c.Request.Context(), user.ID)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	if mfaEnabled {
+		challenge, err := ctl.mfaService.StartChallenge(user, []string{models.AMRPassword})
+		if err != nil {
+			utils.RespondError(c, err)
+			return
+		}
+		utils.SuccessWithData(c, challenge)
+		return
+	}
+
+	result, err := ctl.sessionService.StartSession(c.Request.Context(), user, []string{models.AMRPassword}, clientInfo(c))
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	respondSession(c, ctl.sessionService, result, wantsCookies(c))
+}
+
+// Refresh 使用刷新令牌换取新的令牌对（刷新令牌同时轮换）
+func (ctl *AuthController) Refresh(c *gin.Context) {
+	rawToken, fromCookie, ok := bindRefreshToken(c, ctl.sessionService)
+	if !ok {
+		return
+	}
+
+	result, err := ctl.sessionService.Refresh(c.Request.Context(), rawToken, clientInfo(c))
+	if err != nil {
+		if fromCookie {
+			clearSessionCookies(c)
+		}
+		utils.RespondError(c, err)
+		return
+	}
+
+	respondSession(c, ctl.sessionService, result, fromCookie)
+}
+
+// Logout 注销刷新令牌所属的会话
+func (ctl *AuthController) Logout(c *gin.Context) {
+	rawToken, fromCookie, ok := bindRefreshToken(c, ctl.sessionService)
+	if !ok {
+		return
+	}
+
+	if err := ctl.sessionService.Logout(c.Request.Context(), rawToken, clientInfo(c)); err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	if fromCookie {
+		clearSessionCookies(c)
+	}
+	utils.Success(c, "auth.logged_out")
+}
+
+// Me 获取当前登录用户信息
+func (ctl *AuthController) Me(c *gin.Context) {
+	claims, _ := middleware.GetClaims(c)
+
+	user, err := ctl.userService.GetUser(c.Request.Context(), claims.Subject)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	utils.SuccessWithData(c, user)
+}
+
+// ChangePassword 修改密码
+func (ctl *AuthController) ChangePassword(c *gin.Context) {
+	var req ChangePasswordRequest
+	if !utils.BindJSON(c, &req) {
+		return
+	}
+
+	claims, _ := middleware.GetClaims(c)
+	if err := ctl.userService.ChangePassword(c.Request.Context(), claims.Subject, req.OldPassword, req.NewPassword); err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	recordAudit(c, ctl.audit, models.AuditPasswordChanged, claims.Subject, nil)
+
+	// 修改密码后所有会话需要重新登录
+	count, err := ctl.sessionService.RevokeAllSessions(c.Request.Context(), claims.Subject)
+	if err != nil {
+		logrus.WithError(err).WithField("user_id", claims.Subject).Error("修改密码后撤销会话失败")
+	}
+	recordAudit(c, ctl.audit, models.AuditTokenRevoked, claims.Subject, map[string]string{
+		"reason":   "password_changed",
+		"sessions": strconv.Itoa(count),
+	})
+
+	utils.Success(c, "auth.password_changed")
+}
+
+// ForgotPassword 申请重置密码
+// 邮箱发送一次性重置链接，手机号发送短信验证码；无论账户是否存在都返回相同响应，避免泄露账户信息
+func (ctl *AuthController) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if !utils.BindJSON(c, &req) {
+		return
+	}
+
+	channel, destination, err := bindDestination(req.Email, req.Phone)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	tenantID := middleware.GetTenantID(c)
+	send := services.VerificationRequest{
+		TenantID:    tenantID,
+		Channel:     channel,
+		Destination: destination,
+		Purpose:     models.PurposePasswordReset,
+	}
+
+	var retryAfter time.Duration
+	if channel == models.ChannelEmail {
+		retryAfter, err = ctl.verification.SendResetLink(c.Request.Context(), send, func(ctx context.Context) (string, error) {
+			return ctl.userService.RequestPasswordReset(ctx, tenantID, destination)
+		})
+	} else {
+		var user *models.User
+		if user, err = ctl.userService.FindByDestination(c.Request.Context(), tenantID, channel, destination); err != nil {
+			utils.RespondError(c, err)
+			return
+		}
+		if user != nil {
+			send.UserID = user.ID
+		}
+		retryAfter, err = ctl.verification.SendCode(c.Request.Context(), send)
+	}
+	if err != nil {
+		respondSendError(c, err, retryAfter)
+		return
+	}
+
+	utils.Success(c, "auth.reset_requested")
+}
+
+// ResetPassword 使用重置令牌或短信验证码设置新密码
+func (ctl *AuthController) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if !utils.BindJSON(c, &req) {
+		return
+	}
+
+	userID, err := ctl.resetPassword(c, &req)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	// 重置密码的请求未登录，操作者即令牌对应的用户
+	event := auditEvent(c, models.AuditPasswordReset, userID, nil)
+	event.ActorID = userID
+	ctl.audit.Record(c.Request.Context(), event)
+
+	count, err := ctl.sessionService.RevokeAllSessions(c.Request.Context(), userID)
+	if err != nil {
+		logrus.WithError(err).WithField("user_id", userID).Error("重置密码后撤销会话失败")
+	}
+	event = auditEvent(c, models.AuditTokenRevoked, userID, map[string]string{
+		"reason":   "password_reset",
+		"sessions": strconv.Itoa(count),
+	})
+	event.ActorID = userID
+	ctl.audit.Record(c.Request.Context(), event)
+
+	utils.Success(c, "auth.password_reset")
+}
+
+// renewExpiredPassword 密码已过期时使用请求中的新密码替换，未提供新密码或新密码不合要求时写入错误响应并返回 false
+func (ctl *AuthController) renewExpiredPassword(c *gin.Context, user *models.User, req *LoginRequest) bool {
+	expired, err := ctl.userService.PasswordExpired(c.Request.Context(), user)
+	if err != nil {
+		utils.RespondError(c, err)
+		return false
+	}
+	if !expired {
+		return true
+	}
+	if req.NewPassword == "" {
+		utils.RespondError(c, services.ErrPasswordExpired)
+		return false
+	}
+
+	if err := ctl.userService.ChangePassword(c.Request.Context(), user.ID, req.Password, req.NewPassword); err != nil {
+		utils.RespondError(c, err)
+		return false
+	}
+	event := auditEvent(c, models.AuditPasswordChanged, user.ID, map[string]string{"reason": "expired"})
+	event.ActorID = user.ID
+	ctl.audit.Record(c.Request.Context(), event)
+	return true
+}
+
+// resetPassword 按请求方式校验重置凭证并设置新密码，返回被重置的用户 ID
+// 短信验证码只能使用一次，先校验新密码再消耗验证码
+func (ctl *AuthController) resetPassword(c *gin.Context, req *ResetPasswordRequest) (string, error) {
+	if req.Token != "" {
+		return ctl.userService.ResetPassword(c.Request.Context(), middleware.GetTenantID(c), req.Token, req.NewPassword)
+	}
+	if req.Phone == "" || req.Code == "" {
+		return "", errResetCredentialRequired
+	}
+
+	phone, err := services.NormalizeDestination(models.ChannelSMS, req.Phone)
+	if err != nil {
+		return "", err
+	}
+	if err := ctl.userService.ValidatePassword(c.Request.Context(), middleware.GetTenantID(c), req.NewPassword); err != nil {
+		return "", err
+	}
+	record, err := ctl.verification.Verify(c.Request.Context(), middleware.GetTenantID(c), models.ChannelSMS, phone, models.PurposePasswordReset, req.Code)
+	if err != nil {
+		return "", err
+	}
+	if err := ctl.userService.ResetPasswordForUser(c.Request.Context(), record.UserID, req.NewPassword); err != nil {
+		return "", err
+	}
+	return record.UserID, nil
+}
+
+// clientInfo 提取请求的客户端信息
+func clientInfo(c *gin.Context) services.ClientInfo {
+	return services.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		ClientIP:  c.ClientIP(),
+		TenantID:  middleware.GetTenantID(c),
+	}
+}
+
+// loginAttempt 构造登录尝试的来源信息
+func loginAttempt(c *gin.Context, account string) services.LoginAttempt {
+	return services.LoginAttempt{
+		TenantID:  middleware.GetTenantID(c),
+		Account:   account,
+		ClientIP:  c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
+
+// respondLocked 账户或 IP 处于锁定状态
+func respondLocked(c *gin.Context, decision *services.LockoutDecision) {
+	c.Header("Retry-After", strconv.Itoa(decision.RetryAfterSeconds()))
+	utils.WriteProblem(c, utils.NewProblem(http.StatusTooManyRequests, "LOGIN_LOCKED", "auth.login_locked").
+		With("retry_after", decision.RetryAfterSeconds()).
+		With("captcha_required", decision.CaptchaRequired))
+}
+
+// respondLoginFailure 凭证校验失败，告知客户端是否需要验证码以及下次可尝试的时间
+func respondLoginFailure(c *gin.Context, err error, decision *services.LockoutDecision) {
+	if decision.Locked {
+		c.Header("Retry-After", strconv.Itoa(decision.RetryAfterSeconds()))
+	}
+	utils.WriteProblem(c, utils.ErrorProblem(err).
+		With("retry_after", decision.RetryAfterSeconds()).
+		With("captcha_required", decision.CaptchaRequired))
+}
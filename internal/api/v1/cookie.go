@@ -0,0 +1,127 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"gin_saas_auth/internal/api/middleware"
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/services"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// authModeCookie X-Auth-Mode 请求头取值，表示令牌写入 Cookie
+const authModeCookie = "cookie"
+
+// refreshCookiePath 刷新令牌 Cookie 只在认证接口中发送，减少暴露范围
+const refreshCookiePath = "/api/v1/auth"
+
+// wantsCookies 判断客户端是否选择 Cookie 会话
+func wantsCookies(c *gin.Context) bool {
+	return c.GetHeader(middleware.HeaderAuthMode) == authModeCookie
+}
+
+// respondSession 返回登录或刷新成功的令牌信息
+// Cookie 模式下令牌写入 HttpOnly Cookie，响应体不包含令牌，只返回供脚本回传的 CSRF 令牌
+func respondSession(c *gin.Context, sessions *services.SessionService, result *services.AuthResult, cookieMode bool) {
+	if !cookieMode {
+		utils.SuccessWithData(c, result)
+		return
+	}
+
+	csrfToken, err := sessions.CSRFToken(result.SessionID)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	setSessionCookies(c, result, csrfToken)
+
+	utils.SuccessWithData(c, gin.H{
+		"user":       result.User,
+		"token_type": "Cookie",
+		"expires_in": result.ExpiresIn,
+		"session_id": result.SessionID,
+		"csrf_token": csrfToken,
+	})
+}
+
+// bindRefreshToken 读取刷新令牌，请求体未提供时从 Cookie 读取，fromCookie 标记令牌来源
+// 从 Cookie 读取时先校验 CSRF 令牌；ok 为 false 时已写入错误响应
+func bindRefreshToken(c *gin.Context, sessions *services.SessionService) (rawToken string, fromCookie, ok bool) {
+	var req RefreshRequest
+	if !utils.BindOptionalJSON(c, &req) {
+		return "", false, false
+	}
+	if req.RefreshToken != "" {
+		return req.RefreshToken, false, true
+	}
+
+	rawToken, err := c.Cookie(config.GlobalConfig.Cookie.RefreshName)
+	if err != nil || rawToken == "" {
+		utils.BadRequest(c, "auth.refresh_token_required")
+		return "", false, false
+	}
+	if err := sessions.VerifyRefreshCSRF(c.Request.Context(), rawToken, middleware.CSRFTokenFromRequest(c)); err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) {
+			clearSessionCookies(c)
+		}
+		utils.RespondError(c, err)
+		return "", false, false
+	}
+	return rawToken, true, true
+}
+
+// setSessionCookies 写入访问令牌、刷新令牌和 CSRF 令牌 Cookie
+func setSessionCookies(c *gin.Context, result *services.AuthResult, csrfToken string) {
+	cfg := config.GlobalConfig.Cookie
+	accessExpires := time.Now().Add(time.Duration(result.ExpiresIn) * time.Second)
+
+	setCookie(c, cfg.AccessName, result.AccessToken, "/", accessExpires, true)
+	setCookie(c, cfg.RefreshName, result.RefreshToken, refreshCookiePath, result.RefreshExpiresAt, true)
+	// CSRF Cookie 需要被前端脚本读取后放入请求头，不能设置 HttpOnly
+	setCookie(c, cfg.CSRFName, csrfToken, "/", result.RefreshExpiresAt, false)
+}
+
+// clearSessionCookies 清除会话相关的 Cookie
+func clearSessionCookies(c *gin.Context) {
+	cfg := config.GlobalConfig.Cookie
+	setCookie(c, cfg.AccessName, "", "/", time.Unix(0, 0), true)
+	setCookie(c, cfg.RefreshName, "", refreshCookiePath, time.Unix(0, 0), true)
+	setCookie(c, cfg.CSRFName, "", "/", time.Unix(0, 0), false)
+}
+
+// setCookie 按配置写入 Cookie，过期时间早于当前时间时删除该 Cookie
+func setCookie(c *gin.Context, name, value, path string, expires time.Time, httpOnly bool) {
+	cfg := config.GlobalConfig.Cookie
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   cfg.Domain,
+		Expires:  expires,
+		Secure:   cfg.Secure,
+		HttpOnly: httpOnly,
+		SameSite: sameSiteMode(cfg.SameSite),
+	}
+	if maxAge := int(time.Until(expires).Seconds()); maxAge > 0 {
+		cookie.MaxAge = maxAge
+	} else {
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(c.Writer, cookie)
+}
+
+// sameSiteMode 将配置转换为 SameSite 属性，无法识别时使用 Lax
+func sameSiteMode(mode string) http.SameSite {
+	switch mode {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
@@ -0,0 +1,284 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gin_saas_auth/internal/api/middleware"
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/services"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// oidcBindingCookie 将登录状态与发起登录的浏览器绑定的 Cookie
+const oidcBindingCookie = "oidc_binding"
+
+// oidcCookiePath 绑定 Cookie 只在外部身份登录接口中发送
+const oidcCookiePath = "/api/v1/auth/oidc"
+
+// LinkIdentityRequest 关联外部账户请求
+type LinkIdentityRequest struct {
+	ReturnTo string `json:"return_to"`
+}
+
+// FederationController 外部身份提供方登录控制器
+type FederationController struct {
+	federation     *services.FederationService
+	tenantService  *services.TenantService
+	sessionService *services.SessionService
+	mfaService     *services.MFAService
+	audit          services.AuditRecorder
+}
+
+// NewFederationController 创建外部身份提供方登录控制器
+func NewFederationController(federation *services.FederationService, tenantService *services.TenantService, sessionService *services.SessionService, mfaService *services.MFAService, audit services.AuditRecorder) *FederationController {
+	return &FederationController{
+		federation:     federation,
+		tenantService:  tenantService,
+		sessionService: sessionService,
+		mfaService:     mfaService,
+		audit:          audit,
+	}
+}
+
+// Providers 查询当前租户可用于登录的提供方，供登录页展示
+func (ctl *FederationController) Providers(c *gin.Context) {
+	providers, err := ctl.federation.ListProviders(c.Request.Context(), middleware.GetTenantID(c), true)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	result := make([]gin.H, 0, len(providers))
+	for _, provider := range providers {
+		result = append(result, gin.H{
+			"name":         provider.Name,
+			"display_name": provider.DisplayName,
+		})
+	}
+	utils.SuccessWithData(c, result)
+}
+
+// Authorize 跳转到提供方登录页
+// return_to 不为空时，登录完成后令牌写入 Cookie 并跳转回该地址，否则回调以 JSON 返回令牌
+func (ctl *FederationController) Authorize(c *gin.Context) {
+	auth, err := ctl.federation.StartLogin(c.Request.Context(), middleware.GetTenantID(c), c.Param("provider"), c.Query("return_to"), "")
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	setBindingCookie(c, auth.Binding, time.Now().Add(config.GlobalConfig.OIDC.StateTTL))
+	c.Redirect(http.StatusFound, auth.URL)
+}
+
+// Link 为当前用户发起外部账户关联，返回需要浏览器打开的授权地址
+func (ctl *FederationController) Link(c *gin.Context) {
+	var req LinkIdentityRequest
+	if !utils.BindOptionalJSON(c, &req) {
+		return
+	}
+
+	claims, _ := middleware.GetClaims(c)
+	auth, err := ctl.federation.StartLogin(c.Request.Context(), middleware.GetTenantID(c), c.Param("provider"), req.ReturnTo, claims.Subject)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	setBindingCookie(c, auth.Binding, time.Now().Add(config.GlobalConfig.OIDC.StateTTL))
+	utils.SuccessWithData(c, gin.H{"authorization_url": auth.URL})
+}
+
+// Callback 处理提供方回调，完成登录或账户关联
+// 回调地址不带租户信息，租户从加密的 state 中还原
+func (ctl *FederationController) Callback(c *gin.Context) {
+	binding, _ := c.Cookie(oidcBindingCookie)
+	setBindingCookie(c, "", time.Unix(0, 0))
+
+	state, err := ctl.federation.OpenState(c.Query("state"), binding)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	if _, err := ctl.tenantService.Resolve(c.Request.Context(), state.TenantID); err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.Set(middleware.ContextKeyTenantID, state.TenantID)
+
+	// 用户在提供方取消授权或提供方返回错误
+	if upstreamError := c.Query("error"); upstreamError != "" {
+		logrus.WithFields(logrus.Fields{
+			"tenant_id":   state.TenantID,
+			"provider":    state.Provider,
+			"error":       upstreamError,
+			"description": c.Query("error_description"),
+		}).Warn("身份提供方返回授权错误")
+		ctl.fail(c, state, services.ErrFederationFailed)
+		return
+	}
+
+	login, err := ctl.federation.Complete(c.Request.Context(), state, c.Query("code"))
+	if err != nil {
+		ctl.fail(c, state, err)
+		return
+	}
+	if login.Linked {
+		ctl.record(c, models.AuditIdentityLinked, login, map[string]string{"created": boolString(login.Created)})
+	}
+
+	if state.LinkUserID != "" {
+		if state.ReturnTo != "" {
+			c.Redirect(http.StatusFound, appendQuery(state.ReturnTo, url.Values{"linked": {login.Provider.Name}}))
+			return
+		}
+		utils.SuccessWithData(c, login.Identity)
+		return
+	}
+
+	if !login.User.IsActive() {
+		ctl.fail(c, state, services.ErrUserDisabled)
+		return
+	}
+	ctl.record(c, models.AuditLoginSucceeded, login, map[string]string{"method": "oidc"})
+
+	mfaEnabled, err := ctl.mfaService.IsEnabled(c.Request.Context(), login.User.ID)
+	if err != nil {
+		ctl.fail(c, state, err)
+		return
+	}
+	if mfaEnabled {
+		challenge, err := ctl.mfaService.StartChallenge(login.User, []string{models.AMRFederated})
+		if err != nil {
+			ctl.fail(c, state, err)
+			return
+		}
+		if state.ReturnTo != "" {
+			// 挑战令牌放在片段中，不会出现在服务端日志和 Referer 中
+			c.Redirect(http.StatusFound, state.ReturnTo+"#"+url.Values{"mfa_token": {challenge.MFAToken}}.Encode())
+			return
+		}
+		utils.SuccessWithData(c, challenge)
+		return
+	}
+
+	result, err := ctl.sessionService.StartSession(c.Request.Context(), login.User, []string{models.AMRFederated}, clientInfo(c))
+	if err != nil {
+		ctl.fail(c, state, err)
+		return
+	}
+	if state.ReturnTo == "" {
+		respondSession(c, ctl.sessionService, result, false)
+		return
+	}
+
+	csrfToken, err := ctl.sessionService.CSRFToken(result.SessionID)
+	if err != nil {
+		ctl.fail(c, state, err)
+		return
+	}
+	setSessionCookies(c, result, csrfToken)
+	c.Redirect(http.StatusFound, state.ReturnTo)
+}
+
+// Identities 查询当前用户已关联的外部账户
+func (ctl *FederationController) Identities(c *gin.Context) {
+	claims, _ := middleware.GetClaims(c)
+	identities, err := ctl.federation.ListIdentities(c.Request.Context(), middleware.GetTenantID(c), claims.Subject)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	utils.SuccessWithData(c, identities)
+}
+
+// Unlink 解除当前用户与外部账户的关联
+func (ctl *FederationController) Unlink(c *gin.Context) {
+	claims, _ := middleware.GetClaims(c)
+	if err := ctl.federation.Unlink(c.Request.Context(), claims.Subject, c.Param("id")); err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	recordAudit(c, ctl.audit, models.AuditIdentityUnlinked, c.Param("id"), nil)
+
+	utils.Success(c, "federation.unlinked")
+}
+
+// fail 回调失败，发起登录时指定了跳转地址则带错误码跳转回前端，否则返回 JSON 错误
+func (ctl *FederationController) fail(c *gin.Context, state *services.OIDCState, err error) {
+	if state.ReturnTo == "" {
+		utils.RespondError(c, err)
+		return
+	}
+
+	code := "login_failed"
+	switch {
+	case errors.Is(err, services.ErrIdentityNotLinked):
+		code = "not_linked"
+	case errors.Is(err, services.ErrIdentityInUse):
+		code = "identity_in_use"
+	case errors.Is(err, services.ErrUserDisabled):
+		code = "user_disabled"
+	case errors.Is(err, services.ErrFederationFailed), errors.Is(err, services.ErrProviderNotFound):
+		// 原因已在服务中记录
+	default:
+		logrus.WithError(err).Error("外部身份登录回调处理失败")
+	}
+	c.Redirect(http.StatusFound, appendQuery(state.ReturnTo, url.Values{"error": {code}}))
+}
+
+// record 记录回调产生的审计事件，回调请求未登录，操作者即对应的本地用户
+func (ctl *FederationController) record(c *gin.Context, eventType string, login *services.FederatedLogin, metadata map[string]string) {
+	metadata["provider"] = login.Provider.Name
+	metadata["subject"] = login.Identity.Subject
+	event := auditEvent(c, eventType, login.User.Email, metadata)
+	event.ActorID = login.User.ID
+	ctl.audit.Record(c.Request.Context(), event)
+}
+
+// setBindingCookie 写入或删除浏览器绑定 Cookie
+// 提供方回调是跨站的顶级跳转，必须使用 SameSite=Lax 才能携带该 Cookie
+func setBindingCookie(c *gin.Context, value string, expires time.Time) {
+	cfg := config.GlobalConfig.Cookie
+	cookie := &http.Cookie{
+		Name:     oidcBindingCookie,
+		Value:    value,
+		Path:     oidcCookiePath,
+		Domain:   cfg.Domain,
+		Expires:  expires,
+		Secure:   cfg.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if maxAge := int(time.Until(expires).Seconds()); maxAge > 0 {
+		cookie.MaxAge = maxAge
+	} else {
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(c.Writer, cookie)
+}
+
+// appendQuery 向跳转地址追加查询参数
+func appendQuery(target string, params url.Values) string {
+	separator := "?"
+	if strings.Contains(target, "?") {
+		separator = "&"
+	}
+	return target + separator + params.Encode()
+}
+
+// boolString 布尔值的字符串形式，用于审计元数据
+func boolString(value bool) string {
+	if value {
+		return "true"
+	}
+	return "false"
+}
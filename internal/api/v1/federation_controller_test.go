@@ -0,0 +1,532 @@
+package v1
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"gin_saas_auth/internal/api/middleware"
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/repository"
+	"gin_saas_auth/internal/services"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// 测试身份提供方的客户端配置
+const (
+	stubClientID     = "gin-saas-auth"
+	stubClientSecret = "s3cret"
+)
+
+// stubAuthorization 测试身份提供方签发授权码时记录的授权请求
+type stubAuthorization struct {
+	nonce       string
+	challenge   string
+	redirectURI string
+	claims      jwt.MapClaims
+}
+
+// stubIdP 基于 httptest 的 OIDC 身份提供方，提供发现文档、签名公钥、授权和令牌接口
+// 授权接口直接签发授权码，令牌接口校验客户端密钥、PKCE 和回调地址后签发 ID 令牌
+type stubIdP struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu       sync.Mutex
+	codes    map[string]stubAuthorization
+	claims   jwt.MapClaims // 下一次授权签发的用户声明
+	issuer   string        // 不为空时覆盖 ID 令牌的 iss，模拟错误的签发方
+	audience string        // 不为空时覆盖 ID 令牌的 aud，模拟签发给其他客户端的令牌
+	serial   int
+}
+
+// newStubIdP 启动测试身份提供方
+func newStubIdP(t *testing.T) *stubIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("生成签名密钥失败: %v", err)
+	}
+
+	idp := &stubIdP{key: key, codes: make(map[string]stubAuthorization)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("/jwks", idp.jwks)
+	mux.HandleFunc("/authorize", idp.authorize)
+	mux.HandleFunc("/token", idp.token)
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// setClaims 设置下一次登录的用户声明
+func (idp *stubIdP) setClaims(claims jwt.MapClaims) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	idp.claims = claims
+}
+
+func (idp *stubIdP) discovery(w http.ResponseWriter, r *http.Request) {
+	issuer := idp.server.URL
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"issuer":                                issuer,
+		"authorization_endpoint":                issuer + "/authorize",
+		"token_endpoint":                        issuer + "/token",
+		"jwks_uri":                              issuer + "/jwks",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (idp *stubIdP) jwks(w http.ResponseWriter, r *http.Request) {
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(idp.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(idp.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (idp *stubIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("response_type") != "code" || query.Get("client_id") != stubClientID || query.Get("code_challenge_method") != "S256" {
+		http.Error(w, "invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	idp.mu.Lock()
+	idp.serial++
+	code := "code-" + strconv.Itoa(idp.serial)
+	idp.codes[code] = stubAuthorization{
+		nonce:       query.Get("nonce"),
+		challenge:   query.Get("code_challenge"),
+		redirectURI: query.Get("redirect_uri"),
+		claims:      idp.claims,
+	}
+	idp.mu.Unlock()
+
+	callback := url.Values{"code": {code}, "state": {query.Get("state")}}
+	http.Redirect(w, r, query.Get("redirect_uri")+"?"+callback.Encode(), http.StatusFound)
+}
+
+func (idp *stubIdP) token(w http.ResponseWriter, r *http.Request) {
+	fail := func(reason string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": reason})
+	}
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		fail("unsupported_grant_type")
+		return
+	}
+	clientID, secret, ok := r.BasicAuth()
+	if !ok || clientID != stubClientID || secret != stubClientSecret {
+		fail("invalid_client")
+		return
+	}
+
+	idp.mu.Lock()
+	auth, found := idp.codes[r.PostForm.Get("code")]
+	delete(idp.codes, r.PostForm.Get("code"))
+	issuer, audience := idp.issuer, idp.audience
+	idp.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	switch {
+	case !found, r.PostForm.Get("redirect_uri") != auth.redirectURI:
+		fail("invalid_grant")
+		return
+	case base64.RawURLEncoding.EncodeToString(sum[:]) != auth.challenge:
+		fail("invalid_grant")
+		return
+	}
+
+	if issuer == "" {
+		issuer = idp.server.URL
+	}
+	if audience == "" {
+		audience = stubClientID
+	}
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   issuer,
+		"aud":   audience,
+		"nonce": auth.nonce,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Minute).Unix(),
+	}
+	for name, value := range auth.claims {
+		claims[name] = value
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "k1"
+	idToken, err := token.SignedString(idp.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"access_token": "stub-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   60,
+		"id_token":     idToken,
+	})
+}
+
+// federationEnv 外部身份登录测试环境，服务使用内存存储
+type federationEnv struct {
+	idp        *stubIdP
+	router     *gin.Engine
+	federation *services.FederationService
+	users      *services.UserService
+	tenantID   string
+}
+
+// newFederationEnv 创建测试环境并为默认租户配置指向测试身份提供方的提供方
+func newFederationEnv(t *testing.T, input services.ProviderInput) *federationEnv {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("AUTH_JWT_SECRET", "federation-test-secret-0123456789abcdef")
+	t.Setenv("AUTH_ARGON2_MEMORY", "1024")
+	t.Setenv("AUTH_ARGON2_ITERATIONS", "1")
+	t.Setenv("OIDC_CALLBACK_URL", "http://auth.example.test/api/v1/auth/oidc/callback")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	ctx := context.Background()
+
+	auditLogger := logrus.New()
+	auditLogger.SetOutput(io.Discard)
+	auditService := services.NewAuditService(repository.NewMemoryAuditRepository(), auditLogger)
+
+	tenantService := services.NewTenantService(repository.NewMemoryTenantRepository(), cfg.Tenant.CacheTTL)
+	if err := tenantService.EnsureDefault(ctx, cfg.Tenant.DefaultTenant); err != nil {
+		t.Fatalf("初始化默认租户失败: %v", err)
+	}
+	userRepo := repository.NewMemoryUserRepository()
+	policies, err := services.NewPasswordPolicyService(cfg, repository.NewMemoryTenantRepository())
+	if err != nil {
+		t.Fatalf("初始化密码策略服务失败: %v", err)
+	}
+	hasher := utils.NewPasswordHasher(utils.Argon2Params{
+		Memory:      cfg.Auth.Argon2Memory,
+		Iterations:  cfg.Auth.Argon2Iterations,
+		Parallelism: cfg.Auth.Argon2Parallelism,
+	})
+	userService, err := services.NewUserService(userRepo, userRepo, userRepo, policies, hasher, cfg.Auth.ResetTokenTTL)
+	if err != nil {
+		t.Fatalf("初始化用户服务失败: %v", err)
+	}
+	tokenService := services.NewTokenService(cfg)
+	sessionService := services.NewSessionService(cfg, repository.NewMemorySessionRepository(), userRepo, tokenService, auditService)
+	mfaService, err := services.NewMFAService(cfg, repository.NewMemoryMFARepository(), userRepo, tokenService)
+	if err != nil {
+		t.Fatalf("初始化多因素认证服务失败: %v", err)
+	}
+
+	idp := newStubIdP(t)
+	client := services.NewOIDCClient(idp.server.Client(), cfg.OIDC.DiscoveryTTL)
+	federation, err := services.NewFederationService(cfg, repository.NewMemoryIdentityProviderRepository(), userService, client)
+	if err != nil {
+		t.Fatalf("初始化外部身份登录服务失败: %v", err)
+	}
+
+	secret := stubClientSecret
+	input.Issuer = idp.server.URL
+	input.ClientID = stubClientID
+	input.ClientSecret = &secret
+	input.Enabled = true
+	if _, err := federation.CreateProvider(ctx, cfg.Tenant.DefaultTenant, "stub", input); err != nil {
+		t.Fatalf("创建身份提供方失败: %v", err)
+	}
+
+	tenantID := cfg.Tenant.DefaultTenant
+	ctl := NewFederationController(federation, tenantService, sessionService, mfaService, auditService)
+	router := gin.New()
+	router.Use(middleware.LocaleMiddleware())
+	public := router.Group("/api/v1/auth", func(c *gin.Context) {
+		c.Set(middleware.ContextKeyTenantID, tenantID)
+	})
+	public.GET("/oidc/:provider/authorize", ctl.Authorize)
+	router.GET("/api/v1/auth/oidc/callback", ctl.Callback)
+
+	return &federationEnv{
+		idp:        idp,
+		router:     router,
+		federation: federation,
+		users:      userService,
+		tenantID:   tenantID,
+	}
+}
+
+// serve 向服务发送请求
+func (env *federationEnv) serve(req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	env.router.ServeHTTP(w, req)
+	return w
+}
+
+// flowStart 发起登录后浏览器持有的绑定 Cookie 和提供方授权地址
+type flowStart struct {
+	binding      string
+	authorizeURL *url.URL
+}
+
+// begin 调用发起登录接口，校验跳转到提供方的授权参数
+func (env *federationEnv) begin(t *testing.T) flowStart {
+	t.Helper()
+	w := env.serve(httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/stub/authorize", nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("发起登录状态码 = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	location, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("解析授权地址失败: %v", err)
+	}
+	if got, want := location.Scheme+"://"+location.Host+location.Path, env.idp.server.URL+"/authorize"; got != want {
+		t.Fatalf("授权地址 = %s, want %s", got, want)
+	}
+	query := location.Query()
+	for _, name := range []string{"state", "nonce", "code_challenge"} {
+		if query.Get(name) == "" {
+			t.Fatalf("授权地址缺少参数 %s: %s", name, location)
+		}
+	}
+	if query.Get("code_challenge_method") != "S256" {
+		t.Fatalf("code_challenge_method = %q, want S256", query.Get("code_challenge_method"))
+	}
+
+	var binding string
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == oidcBindingCookie {
+			binding = cookie.Value
+			if cookie.Path != oidcCookiePath || !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode {
+				t.Fatalf("绑定 Cookie 属性错误: %+v", cookie)
+			}
+		}
+	}
+	if binding == "" {
+		t.Fatal("发起登录未写入绑定 Cookie")
+	}
+	return flowStart{binding: binding, authorizeURL: location}
+}
+
+// authorize 浏览器访问提供方授权地址，返回提供方跳转回服务的回调参数
+func (env *federationEnv) authorize(t *testing.T, start flowStart) url.Values {
+	t.Helper()
+	client := env.idp.server.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := client.Get(start.authorizeURL.String())
+	if err != nil {
+		t.Fatalf("访问提供方授权地址失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("提供方授权状态码 = %d", resp.StatusCode)
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("解析回调地址失败: %v", err)
+	}
+	return location.Query()
+}
+
+// callback 携带绑定 Cookie 调用回调接口
+func (env *federationEnv) callback(params url.Values, binding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/callback?"+params.Encode(), nil)
+	if binding != "" {
+		req.AddCookie(&http.Cookie{Name: oidcBindingCookie, Value: binding})
+	}
+	return env.serve(req)
+}
+
+// sessionResponse 回调以 JSON 返回的会话
+type sessionResponse struct {
+	Data struct {
+		AccessToken string `json:"access_token"`
+		User        struct {
+			ID    string `json:"id"`
+			Email string `json:"email"`
+		} `json:"user"`
+	} `json:"data"`
+}
+
+// login 完成一次完整的登录流程，返回回调响应
+func (env *federationEnv) login(t *testing.T, claims jwt.MapClaims) *httptest.ResponseRecorder {
+	t.Helper()
+	env.idp.setClaims(claims)
+	start := env.begin(t)
+	return env.callback(env.authorize(t, start), start.binding)
+}
+
+// decodeSession 解析登录成功的响应
+func decodeSession(t *testing.T, w *httptest.ResponseRecorder) sessionResponse {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("回调状态码 = %d, body = %s", w.Code, w.Body.String())
+	}
+	var resp sessionResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("解析回调响应失败: %v", err)
+	}
+	if resp.Data.AccessToken == "" || resp.Data.User.ID == "" {
+		t.Fatalf("回调未返回会话: %s", w.Body.String())
+	}
+	return resp
+}
+
+// assertProblem 校验错误响应的状态码和错误码
+func assertProblem(t *testing.T, w *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+	if w.Code != status {
+		t.Fatalf("状态码 = %d, want %d, body = %s", w.Code, status, w.Body.String())
+	}
+	var problem utils.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("解析错误响应失败: %v", err)
+	}
+	if problem.Code != code {
+		t.Fatalf("错误码 = %s, want %s", problem.Code, code)
+	}
+}
+
+func TestFederationSignupAndRepeatLogin(t *testing.T) {
+	env := newFederationEnv(t, services.ProviderInput{AllowSignup: true})
+	claims := jwt.MapClaims{"sub": "idp-user-1", "email": "alice@example.com", "email_verified": true}
+
+	first := decodeSession(t, env.login(t, claims))
+	if first.Data.User.Email != "alice@example.com" {
+		t.Fatalf("注册用户邮箱 = %s", first.Data.User.Email)
+	}
+
+	identities, err := env.federation.ListIdentities(context.Background(), env.tenantID, first.Data.User.ID)
+	if err != nil {
+		t.Fatalf("查询身份关联失败: %v", err)
+	}
+	if len(identities) != 1 || identities[0].Subject != "idp-user-1" || identities[0].Provider != "stub" {
+		t.Fatalf("身份关联 = %+v", identities)
+	}
+
+	// 同一外部账户再次登录时使用已有的关联，不再注册
+	second := decodeSession(t, env.login(t, claims))
+	if second.Data.User.ID != first.Data.User.ID {
+		t.Fatalf("再次登录的用户 = %s, want %s", second.Data.User.ID, first.Data.User.ID)
+	}
+}
+
+func TestFederationAutoLinkByVerifiedEmail(t *testing.T) {
+	env := newFederationEnv(t, services.ProviderInput{AutoLink: true})
+	existing, err := env.users.Register(context.Background(), env.tenantID, "bob@example.com", "Password123!")
+	if err != nil {
+		t.Fatalf("注册本地用户失败: %v", err)
+	}
+
+	// 邮箱未验证时不能自动关联
+	w := env.login(t, jwt.MapClaims{"sub": "idp-user-2", "email": "bob@example.com", "email_verified": false})
+	assertProblem(t, w, http.StatusForbidden, services.ErrIdentityNotLinked.Code)
+
+	resp := decodeSession(t, env.login(t, jwt.MapClaims{"sub": "idp-user-2", "email": "bob@example.com", "email_verified": true}))
+	if resp.Data.User.ID != existing.ID {
+		t.Fatalf("自动关联的用户 = %s, want %s", resp.Data.User.ID, existing.ID)
+	}
+}
+
+func TestFederationCustomEmailClaims(t *testing.T) {
+	env := newFederationEnv(t, services.ProviderInput{
+		EmailClaim:  "upn",
+		TrustEmail:  true,
+		AllowSignup: true,
+	})
+
+	resp := decodeSession(t, env.login(t, jwt.MapClaims{
+		"sub":   "idp-user-3",
+		"email": "ignored@example.com",
+		"upn":   "carol@example.com",
+	}))
+	if resp.Data.User.Email != "carol@example.com" {
+		t.Fatalf("映射的邮箱 = %s, want carol@example.com", resp.Data.User.Email)
+	}
+}
+
+func TestFederationCallbackRejectsStateMismatch(t *testing.T) {
+	env := newFederationEnv(t, services.ProviderInput{AllowSignup: true})
+	env.idp.setClaims(jwt.MapClaims{"sub": "idp-user-4", "email": "dave@example.com", "email_verified": true})
+	victim := env.begin(t)
+	params := env.authorize(t, victim)
+
+	// 其他浏览器发起的登录：绑定 Cookie 与 state 不匹配
+	attacker := env.begin(t)
+	assertProblem(t, env.callback(params, attacker.binding), http.StatusBadRequest, services.ErrInvalidOIDCState.Code)
+
+	// 没有绑定 Cookie
+	assertProblem(t, env.callback(params, ""), http.StatusBadRequest, services.ErrInvalidOIDCState.Code)
+
+	// 篡改 state
+	tampered := url.Values{"code": {params.Get("code")}, "state": {params.Get("state") + "x"}}
+	assertProblem(t, env.callback(tampered, victim.binding), http.StatusBadRequest, services.ErrInvalidOIDCState.Code)
+}
+
+func TestFederationCallbackRejectsPKCEMismatch(t *testing.T) {
+	env := newFederationEnv(t, services.ProviderInput{AllowSignup: true})
+	env.idp.setClaims(jwt.MapClaims{"sub": "idp-user-5", "email": "erin@example.com", "email_verified": true})
+
+	// 授权码签发给另一次登录，与当前登录的 PKCE 校验值不匹配
+	other := env.authorize(t, env.begin(t))
+	current := env.begin(t)
+	params := env.authorize(t, current)
+	params.Set("code", other.Get("code"))
+
+	assertProblem(t, env.callback(params, current.binding), http.StatusUnauthorized, services.ErrFederationFailed.Code)
+}
+
+func TestFederationCallbackRejectsInvalidIDToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		issuer   string
+		audience string
+	}{
+		{name: "wrong issuer", issuer: "https://evil.example.com"},
+		{name: "wrong audience", audience: "another-client"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newFederationEnv(t, services.ProviderInput{AllowSignup: true})
+			env.idp.issuer = tt.issuer
+			env.idp.audience = tt.audience
+
+			w := env.login(t, jwt.MapClaims{"sub": "idp-user-6", "email": "frank@example.com", "email_verified": true})
+			assertProblem(t, w, http.StatusUnauthorized, services.ErrFederationFailed.Code)
+
+			user, err := env.users.FindByDestination(context.Background(), env.tenantID, models.ChannelEmail, "frank@example.com")
+			if err != nil || user != nil {
+				t.Fatalf("ID 令牌无效时不应创建用户: user = %v, err = %v", user, err)
+			}
+		})
+	}
+}
@@ -1,12 +1,15 @@
 package v1
 
 import (
-	"fmt"
 	"net/http"
+	"reflect"
 	"time"
 
 	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/lifecycle"
+	"gin_saas_auth/internal/metrics"
 	"gin_saas_auth/internal/services"
+	"gin_saas_auth/internal/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,19 +23,50 @@ func PingHandler(c *gin.Context) {
 	})
 }
 
-// HealthHandler 健康检查接口
-func HealthHandler(c *gin.Context) {
+// HealthHandler 健康检查接口，组件未全部启动或实例正在关闭时返回 503，使网关和 Consul 不转发新请求
+// 过载保护最近拒绝过请求时返回 429，Consul 将实例标记为 warning，网关按降级权重减少转发
+func HealthHandler(app *lifecycle.Manager, shedder *services.LoadShedder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !app.Ready() {
+			status, message := "starting", "health.starting"
+			if app.Draining() {
+				status, message = "draining", "health.draining"
+			}
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status":     status,
+				"message":    utils.T(c, message),
+				"timestamp":  time.Now().Unix(),
+				"components": app.Status(),
+			})
+			return
+		}
+		if shedder != nil && shedder.Degraded() {
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"status":    "degraded",
+				"message":   utils.T(c, "health.degraded"),
+				"timestamp": time.Now().Unix(),
+				"load":      shedder.Stats(),
+			})
+			return
+		}
+		healthCheck(c, app)
+	}
+}
+
+// healthCheck 返回服务、组件与依赖的健康状态
+func healthCheck(c *gin.Context, app *lifecycle.Manager) {
 	cfg := config.GlobalConfig
 
 	healthStatus := map[string]interface{}{
 		"status":    "healthy",
-		"message":   "认证服务运行正常",
+		"message":   utils.T(c, "health.healthy"),
 		"timestamp": time.Now().Unix(),
 		"service": map[string]interface{}{
 			"name":    cfg.App.Name,
 			"version": cfg.Consul.Meta.Version,
 			"env":     cfg.App.Env,
 		},
+		"components":   app.Status(),
 		"dependencies": map[string]interface{}{},
 	}
 
@@ -60,21 +94,9 @@ func HealthHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, healthStatus)
 }
 
-// MetricsHandler 监控指标接口
+// MetricsHandler 监控指标接口（Prometheus 格式）
 func MetricsHandler(c *gin.Context) {
-	cfg := config.GlobalConfig
-	now := time.Now().Unix()
-
-	metricsText := fmt.Sprintf(`# HELP auth_service_info 认证服务信息
-# TYPE auth_service_info gauge
-auth_service_info{name="%s",version="%s",framework="%s",language="%s",environment="%s"} 1
-
-# HELP service_uptime_seconds 服务启动时间（秒）
-# TYPE service_uptime_seconds gauge
-service_uptime_seconds %d
-`, cfg.App.Name, cfg.Consul.Meta.Version, cfg.Consul.Meta.Framework, cfg.Consul.Meta.Language, cfg.App.Env, now)
-
-	c.String(http.StatusOK, metricsText)
+	metrics.Handler().ServeHTTP(c.Writer, c.Request)
 }
 
 // StatsHandler 服务统计信息接口
@@ -88,8 +110,8 @@ func StatsHandler(c *gin.Context) {
 	})
 }
 
-// ConsulInfoHandler Consul 配置信息接口
+// ConsulInfoHandler Consul 配置信息接口，访问令牌已脱敏
 func ConsulInfoHandler(c *gin.Context) {
 	cfg := config.GlobalConfig
-	c.JSON(http.StatusOK, cfg.Consul)
+	c.JSON(http.StatusOK, redactConfig(reflect.ValueOf(cfg.Consul), ""))
 }
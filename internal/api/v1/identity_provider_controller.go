@@ -0,0 +1,186 @@
+package v1
+
+import (
+	"gin_saas_auth/internal/api/middleware"
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/services"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdentityProviderRequest 创建或更新身份提供方请求
+// client_secret 只写不读，更新时省略表示保留原密钥，传空字符串表示清除
+type IdentityProviderRequest struct {
+	Name               string   `json:"name"`
+	DisplayName        string   `json:"display_name"`
+	Issuer             string   `json:"issuer" binding:"required,url"`
+	ClientID           string   `json:"client_id" binding:"required"`
+	ClientSecret       *string  `json:"client_secret"`
+	Scopes             []string `json:"scopes"`
+	EmailClaim         string   `json:"email_claim"`
+	EmailVerifiedClaim string   `json:"email_verified_claim"`
+	TrustEmail         bool     `json:"trust_email"`
+	AutoLink           bool     `json:"auto_link"`
+	AllowSignup        bool     `json:"allow_signup"`
+	Enabled            *bool    `json:"enabled"` // 默认启用
+}
+
+// identityProviderResponse 身份提供方响应，附带需要在提供方登记的回调地址
+type identityProviderResponse struct {
+	*models.IdentityProvider
+	HasClientSecret bool   `json:"has_client_secret"`
+	RedirectURI     string `json:"redirect_uri"`
+}
+
+// identityProviderListSpec 身份提供方列表的排序和过滤字段
+var identityProviderListSpec = utils.ListSpec[*models.IdentityProvider]{
+	Fields: map[string]utils.ListField[*models.IdentityProvider]{
+		"name": {
+			Type:     utils.FieldString,
+			Value:    func(p *models.IdentityProvider) any { return p.Name },
+			Sortable: true,
+			Filters:  []utils.FilterOp{utils.FilterEq, utils.FilterIn, utils.FilterContains},
+		},
+		"issuer": {
+			Type:    utils.FieldString,
+			Value:   func(p *models.IdentityProvider) any { return p.Issuer },
+			Filters: []utils.FilterOp{utils.FilterEq, utils.FilterContains},
+		},
+		"enabled": {
+			Type:    utils.FieldBool,
+			Value:   func(p *models.IdentityProvider) any { return p.Enabled },
+			Filters: []utils.FilterOp{utils.FilterEq},
+		},
+		"created_at": {
+			Type:     utils.FieldTime,
+			Value:    func(p *models.IdentityProvider) any { return p.CreatedAt },
+			Sortable: true,
+		},
+	},
+	Key:         func(p *models.IdentityProvider) string { return p.ID },
+	DefaultSort: "name",
+}
+
+// IdentityProviderController 租户身份提供方管理控制器
+type IdentityProviderController struct {
+	federation *services.FederationService
+	audit      services.AuditRecorder
+}
+
+// NewIdentityProviderController 创建身份提供方管理控制器
+func NewIdentityProviderController(federation *services.FederationService, audit services.AuditRecorder) *IdentityProviderController {
+	return &IdentityProviderController{federation: federation, audit: audit}
+}
+
+// List 分页查询当前租户的身份提供方
+// 查询参数: page, page_size, cursor, sort (name, created_at), name, issuer, enabled
+func (ctl *IdentityProviderController) List(c *gin.Context) {
+	query, ok := utils.ParseListQuery(c, identityProviderListSpec)
+	if !ok {
+		return
+	}
+
+	providers, err := ctl.federation.ListProviders(c.Request.Context(), middleware.GetTenantID(c), false)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	page, info := identityProviderListSpec.Apply(providers, query)
+	result := make([]*identityProviderResponse, 0, len(page))
+	for _, provider := range page {
+		result = append(result, newIdentityProviderResponse(provider))
+	}
+	utils.SuccessWithPage(c, result, info)
+}
+
+// Get 查询身份提供方
+func (ctl *IdentityProviderController) Get(c *gin.Context) {
+	provider, err := ctl.federation.GetProvider(c.Request.Context(), middleware.GetTenantID(c), c.Param("name"))
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	utils.SuccessWithData(c, newIdentityProviderResponse(provider))
+}
+
+// Create 为当前租户创建身份提供方
+func (ctl *IdentityProviderController) Create(c *gin.Context) {
+	var req IdentityProviderRequest
+	if !utils.BindJSON(c, &req) {
+		return
+	}
+
+	provider, err := ctl.federation.CreateProvider(c.Request.Context(), middleware.GetTenantID(c), req.Name, req.input())
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	recordAudit(c, ctl.audit, models.AuditProviderCreated, provider.Name, map[string]string{"issuer": provider.Issuer})
+	utils.SuccessResponse(c, 201, "identity_provider.created", newIdentityProviderResponse(provider))
+}
+
+// Update 更新身份提供方配置，名称不可修改
+func (ctl *IdentityProviderController) Update(c *gin.Context) {
+	var req IdentityProviderRequest
+	if !utils.BindJSON(c, &req) {
+		return
+	}
+
+	provider, err := ctl.federation.UpdateProvider(c.Request.Context(), middleware.GetTenantID(c), c.Param("name"), req.input())
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	recordAudit(c, ctl.audit, models.AuditProviderUpdated, provider.Name, map[string]string{
+		"issuer":  provider.Issuer,
+		"enabled": boolString(provider.Enabled),
+	})
+	utils.SuccessWithData(c, newIdentityProviderResponse(provider))
+}
+
+// Delete 删除身份提供方，已关联的外部账户一并解除
+func (ctl *IdentityProviderController) Delete(c *gin.Context) {
+	name := c.Param("name")
+	if err := ctl.federation.DeleteProvider(c.Request.Context(), middleware.GetTenantID(c), name); err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	recordAudit(c, ctl.audit, models.AuditProviderDeleted, name, nil)
+	utils.Success(c, "identity_provider.deleted")
+}
+
+// input 转换为服务层参数
+func (req *IdentityProviderRequest) input() services.ProviderInput {
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	return services.ProviderInput{
+		DisplayName:        req.DisplayName,
+		Issuer:             req.Issuer,
+		ClientID:           req.ClientID,
+		ClientSecret:       req.ClientSecret,
+		Scopes:             req.Scopes,
+		EmailClaim:         req.EmailClaim,
+		EmailVerifiedClaim: req.EmailVerifiedClaim,
+		TrustEmail:         req.TrustEmail,
+		AutoLink:           req.AutoLink,
+		AllowSignup:        req.AllowSignup,
+		Enabled:            enabled,
+	}
+}
+
+// newIdentityProviderResponse 构造身份提供方响应
+func newIdentityProviderResponse(provider *models.IdentityProvider) *identityProviderResponse {
+	return &identityProviderResponse{
+		IdentityProvider: provider,
+		HasClientSecret:  provider.HasClientSecret(),
+		RedirectURI:      config.GlobalConfig.OIDC.CallbackURL,
+	}
+}
@@ -0,0 +1,178 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"gin_saas_auth/internal/api/middleware"
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/services"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// IntrospectRequest 令牌校验请求
+type IntrospectRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Audience string `json:"audience"` // 校验交换令牌时要求的受众，通常为调用方自身
+}
+
+// TokenExchangeRequest 令牌交换请求（RFC 8693），使用表单编码
+type TokenExchangeRequest struct {
+	GrantType          string   `form:"grant_type" binding:"required"`
+	SubjectToken       string   `form:"subject_token" binding:"required"`
+	SubjectTokenType   string   `form:"subject_token_type" binding:"required"`
+	RequestedTokenType string   `form:"requested_token_type"`
+	Audience           []string `form:"audience"`
+	Scope              string   `form:"scope"` // 以空格分隔的权限
+	ActorToken         string   `form:"actor_token"`
+}
+
+// InternalController 供内部服务调用的接口，请求需携带 HMAC 签名
+type InternalController struct {
+	tokenService    *services.TokenService
+	exchangeService *services.TokenExchangeService
+	audit           services.AuditRecorder
+}
+
+// NewInternalController 创建内部接口控制器
+func NewInternalController(tokenService *services.TokenService, exchangeService *services.TokenExchangeService, audit services.AuditRecorder) *InternalController {
+	return &InternalController{
+		tokenService:    tokenService,
+		exchangeService: exchangeService,
+		audit:           audit,
+	}
+}
+
+// Introspect 校验访问令牌并返回其声明，供其他服务判断调用者身份
+// 令牌无效时同样返回 200，通过 active 字段区分
+func (ctl *InternalController) Introspect(c *gin.Context) {
+	var req IntrospectRequest
+	if !utils.BindJSON(c, &req) {
+		return
+	}
+
+	claims, err := ctl.tokenService.ParseAccessToken(req.Token)
+	if err != nil {
+		// 交换令牌只对其目标受众有效
+		claims, err = ctl.tokenService.ParseExchangedToken(req.Token)
+		if err == nil && (req.Audience == "" || !hasAudience(claims, req.Audience)) {
+			err = services.ErrInvalidToken
+		}
+	}
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"caller": c.GetString(middleware.ContextKeySignatureKeyID),
+			"reason": err.Error(),
+		}).Debug("内部令牌校验未通过")
+		utils.SuccessWithData(c, gin.H{"active": false})
+		return
+	}
+
+	result := gin.H{
+		"active": true,
+		"claims": claims,
+	}
+	// 调用方通过双向 TLS 连接时一并返回其证书身份，便于调用方核对
+	if identity, ok := middleware.GetClientIdentity(c); ok {
+		result["caller_cert"] = identity
+	}
+	utils.SuccessWithData(c, result)
+}
+
+// Exchange 令牌交换（RFC 8693），将用户令牌换取面向其他服务的降权令牌
+// 调用方由请求签名的密钥 ID 标识并记录到新令牌的 act 声明；
+// 响应和错误格式遵循 RFC 6749，便于直接使用标准 OAuth 客户端
+func (ctl *InternalController) Exchange(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	var req TokenExchangeRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondOAuthError(c, http.StatusBadRequest, "invalid_request", "请求参数错误: "+err.Error())
+		return
+	}
+	if req.GrantType != services.GrantTypeTokenExchange {
+		respondOAuthError(c, http.StatusBadRequest, "unsupported_grant_type", "不支持的授权类型")
+		return
+	}
+	// 调用方身份由请求签名确定，不支持另行传入 actor_token
+	if req.ActorToken != "" {
+		respondOAuthError(c, http.StatusBadRequest, "invalid_request", "不支持 actor_token，调用方由请求签名确定")
+		return
+	}
+
+	actor := c.GetString(middleware.ContextKeySignatureKeyID)
+	result, err := ctl.exchangeService.Exchange(c.Request.Context(), actor, services.TokenExchangeRequest{
+		SubjectToken:       req.SubjectToken,
+		SubjectTokenType:   req.SubjectTokenType,
+		RequestedTokenType: req.RequestedTokenType,
+		Audience:           req.Audience,
+		Scopes:             strings.Fields(req.Scope),
+	})
+	if err != nil {
+		respondExchangeError(c, actor, err)
+		return
+	}
+
+	event := auditEvent(c, models.AuditTokenIssued, result.Subject.SessionID, map[string]string{
+		"grant":    "token_exchange",
+		"actor":    actor,
+		"audience": strings.Join(req.Audience, " "),
+		"scope":    strings.Join(result.Scopes, " "),
+	})
+	event.TenantID = result.Subject.TenantID
+	event.ActorID = result.Subject.Subject
+	ctl.audit.Record(c.Request.Context(), event)
+
+	c.JSON(http.StatusOK, gin.H{
+		"access_token":      result.AccessToken,
+		"issued_token_type": result.IssuedTokenType,
+		"token_type":        "Bearer",
+		"expires_in":        result.ExpiresIn,
+		"scope":             strings.Join(result.Scopes, " "),
+	})
+}
+
+// respondExchangeError 将令牌交换错误映射为 RFC 6749 错误响应
+func respondExchangeError(c *gin.Context, actor string, err error) {
+	logrus.WithFields(logrus.Fields{
+		"caller": actor,
+		"reason": err.Error(),
+	}).Warn("令牌交换被拒绝")
+
+	switch {
+	case errors.Is(err, services.ErrExchangeNotAllowed):
+		respondOAuthError(c, http.StatusForbidden, "unauthorized_client", err.Error())
+	case errors.Is(err, services.ErrUnsupportedTokenType),
+		errors.Is(err, services.ErrInvalidSubjectToken):
+		respondOAuthError(c, http.StatusBadRequest, "invalid_request", err.Error())
+	case errors.Is(err, services.ErrInvalidAudience):
+		respondOAuthError(c, http.StatusBadRequest, "invalid_target", err.Error())
+	case errors.Is(err, services.ErrInvalidScope):
+		respondOAuthError(c, http.StatusBadRequest, "invalid_scope", err.Error())
+	default:
+		logrus.WithError(err).Error("令牌交换失败")
+		respondOAuthError(c, http.StatusInternalServerError, "server_error", "服务器内部错误")
+	}
+}
+
+// respondOAuthError 返回 RFC 6749 格式的错误响应
+func respondOAuthError(c *gin.Context, status int, code, description string) {
+	c.JSON(status, gin.H{
+		"error":             code,
+		"error_description": description,
+	})
+}
+
+// hasAudience 判断令牌受众是否包含指定值
+func hasAudience(claims *services.AccessClaims, audience string) bool {
+	for _, aud := range claims.Audience {
+		if aud == audience {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package v1
+
+import (
+	"errors"
+
+	"gin_saas_auth/internal/api/middleware"
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/services"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MFAVerifyRequest 登录第二步校验请求
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"` // 动态码或恢复码
+}
+
+// MFACodeRequest 需要动态码确认的操作请求
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// MFAController 多因素认证控制器
+type MFAController struct {
+	mfaService     *services.MFAService
+	userService    *services.UserService
+	sessionService *services.SessionService
+	lockout        *services.LockoutService
+	audit          services.AuditRecorder
+}
+
+// NewMFAController 创建多因素认证控制器
+func NewMFAController(mfaService *services.MFAService, userService *services.UserService, sessionService *services.SessionService, lockout *services.LockoutService, audit services.AuditRecorder) *MFAController {
+	return &MFAController{
+		mfaService:     mfaService,
+		userService:    userService,
+		sessionService: sessionService,
+		lockout:        lockout,
+		audit:          audit,
+	}
+}
+
+// Verify 使用登录返回的 mfa_token 和动态码（或恢复码）完成登录
+func (ctl *MFAController) Verify(c *gin.Context) {
+	var req MFAVerifyRequest
+	if !utils.BindJSON(c, &req) {
+		return
+	}
+
+	// 动态码只有六位，按用户统计失败次数防止穷举
+	var account string
+	if userID := ctl.mfaService.ChallengeUserID(req.MFAToken); userID != "" {
+		account = "mfa:" + userID
+	}
+	attempt := loginAttempt(c, account)
+	if decision := ctl.lockout.Check(c.Request.Context(), attempt); decision.Locked {
+		respondLocked(c, decision)
+		return
+	}
+
+	user, amr, err := ctl.mfaService.CompleteChallenge(c.Request.Context(), attempt.TenantID, req.MFAToken, req.Code)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMFACode) {
+			respondLoginFailure(c, err, ctl.lockout.RecordFailure(c.Request.Context(), attempt))
+			return
+		}
+		utils.RespondError(c, err)
+		return
+	}
+	ctl.lockout.RecordSuccess(c.Request.Context(), attempt, user.ID)
+
+	result, err := ctl.sessionService.StartSession(c.Request.Context(), user, amr, clientInfo(c))
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	respondSession(c, ctl.sessionService, result, wantsCookies(c))
+}
+
+// Status 查询当前用户的多因素认证状态
+func (ctl *MFAController) Status(c *gin.Context) {
+	claims, _ := middleware.GetClaims(c)
+
+	status, err := ctl.mfaService.Status(c.Request.Context(), claims.Subject)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	utils.SuccessWithData(c, status)
+}
+
+// EnrollTOTP 生成 TOTP 密钥和二维码链接
+func (ctl *MFAController) EnrollTOTP(c *gin.Context) {
+	claims, _ := middleware.GetClaims(c)
+
+	user, err := ctl.userService.GetUser(c.Request.Context(), claims.Subject)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	setup, err := ctl.mfaService.Enroll(c.Request.Context(), user)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	utils.SuccessWithData(c, setup)
+}
+
+// ConfirmTOTP 校验动态码完成绑定，返回恢复码（仅展示一次）
+func (ctl *MFAController) ConfirmTOTP(c *gin.Context) {
+	var req MFACodeRequest
+	if !utils.BindJSON(c, &req) {
+		return
+	}
+
+	claims, _ := middleware.GetClaims(c)
+	codes, err := ctl.mfaService.Confirm(c.Request.Context(), claims.Subject, req.Code)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	recordAudit(c, ctl.audit, models.AuditMFAEnabled, claims.Subject, map[string]string{"method": "totp"})
+
+	utils.SuccessWithData(c, gin.H{"recovery_codes": codes})
+}
+
+// DisableTOTP 校验动态码或恢复码后关闭 TOTP
+func (ctl *MFAController) DisableTOTP(c *gin.Context) {
+	var req MFACodeRequest
+	if !utils.BindJSON(c, &req) {
+		return
+	}
+
+	claims, _ := middleware.GetClaims(c)
+	if err := ctl.mfaService.Disable(c.Request.Context(), claims.Subject, req.Code); err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	recordAudit(c, ctl.audit, models.AuditMFADisabled, claims.Subject, map[string]string{"method": "totp"})
+
+	utils.Success(c, "mfa.disabled")
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，旧恢复码全部失效
+func (ctl *MFAController) RegenerateRecoveryCodes(c *gin.Context) {
+	var req MFACodeRequest
+	if !utils.BindJSON(c, &req) {
+		return
+	}
+
+	claims, _ := middleware.GetClaims(c)
+	codes, err := ctl.mfaService.RegenerateRecoveryCodes(c.Request.Context(), claims.Subject, req.Code)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	recordAudit(c, ctl.audit, models.AuditRecoveryCodesReset, claims.Subject, nil)
+
+	utils.SuccessWithData(c, gin.H{"recovery_codes": codes})
+}
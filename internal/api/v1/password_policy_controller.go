@@ -0,0 +1,92 @@
+package v1
+
+import (
+	"strconv"
+
+	"gin_saas_auth/internal/api/middleware"
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/services"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PasswordPolicyRequest 更新租户密码策略请求
+type PasswordPolicyRequest struct {
+	MinLength     int  `json:"min_length" binding:"required"`
+	MaxLength     int  `json:"max_length" binding:"required"`
+	RequireUpper  bool `json:"require_upper"`
+	RequireLower  bool `json:"require_lower"`
+	RequireDigit  bool `json:"require_digit"`
+	RequireSymbol bool `json:"require_symbol"`
+	CheckBreached bool `json:"check_breached"`
+	HistorySize   int  `json:"history_size"`
+	MaxAgeDays    int  `json:"max_age_days"`
+}
+
+// PasswordPolicyController 租户密码策略控制器
+type PasswordPolicyController struct {
+	policies *services.PasswordPolicyService
+	audit    services.AuditRecorder
+}
+
+// NewPasswordPolicyController 创建租户密码策略控制器
+func NewPasswordPolicyController(policies *services.PasswordPolicyService, audit services.AuditRecorder) *PasswordPolicyController {
+	return &PasswordPolicyController{policies: policies, audit: audit}
+}
+
+// Get 查询当前租户生效的密码策略，供注册和修改密码页面提示
+func (ctl *PasswordPolicyController) Get(c *gin.Context) {
+	policy, err := ctl.policies.Get(c.Request.Context(), middleware.GetTenantID(c))
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	utils.SuccessWithData(c, policy)
+}
+
+// Update 设置当前租户的密码策略，只对之后设置的密码生效
+func (ctl *PasswordPolicyController) Update(c *gin.Context) {
+	var req PasswordPolicyRequest
+	if !utils.BindJSON(c, &req) {
+		return
+	}
+
+	tenantID := middleware.GetTenantID(c)
+	policy, err := ctl.policies.Update(c.Request.Context(), tenantID, &models.PasswordPolicy{
+		MinLength:     req.MinLength,
+		MaxLength:     req.MaxLength,
+		RequireUpper:  req.RequireUpper,
+		RequireLower:  req.RequireLower,
+		RequireDigit:  req.RequireDigit,
+		RequireSymbol: req.RequireSymbol,
+		CheckBreached: req.CheckBreached,
+		HistorySize:   req.HistorySize,
+		MaxAgeDays:    req.MaxAgeDays,
+	})
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	recordAudit(c, ctl.audit, models.AuditPasswordPolicySet, tenantID, map[string]string{
+		"min_length":     strconv.Itoa(policy.MinLength),
+		"history_size":   strconv.Itoa(policy.HistorySize),
+		"max_age_days":   strconv.Itoa(policy.MaxAgeDays),
+		"check_breached": boolString(policy.CheckBreached),
+	})
+	utils.SuccessWithData(c, policy)
+}
+
+// Reset 删除当前租户的自定义密码策略，恢复全局默认策略
+func (ctl *PasswordPolicyController) Reset(c *gin.Context) {
+	tenantID := middleware.GetTenantID(c)
+	policy, err := ctl.policies.Reset(c.Request.Context(), tenantID)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	recordAudit(c, ctl.audit, models.AuditPasswordPolicyReset, tenantID, nil)
+	utils.SuccessWithData(c, policy)
+}
@@ -0,0 +1,51 @@
+package v1
+
+import (
+	"gin_saas_auth/internal/api/middleware"
+	"gin_saas_auth/internal/services"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// RBACController 访问控制控制器
+type RBACController struct {
+	rbacService *services.RBACService
+}
+
+// NewRBACController 创建访问控制控制器
+func NewRBACController(rbacService *services.RBACService) *RBACController {
+	return &RBACController{rbacService: rbacService}
+}
+
+// MyPermissions 查询当前用户在当前租户下的角色和权限
+func (ctl *RBACController) MyPermissions(c *gin.Context) {
+	claims, _ := middleware.GetClaims(c)
+	tenantID := middleware.GetTenantID(c)
+
+	result := gin.H{
+		"user_id":     claims.Subject,
+		"tenant_id":   tenantID,
+		"roles":       ctl.rbacService.Roles(claims.Subject, tenantID),
+		"permissions": ctl.rbacService.Permissions(claims.Subject, tenantID),
+	}
+	// 使用 API 密钥时实际可用的权限还受密钥权限范围限制
+	if claims.IsAPIKey() {
+		result["scopes"] = claims.Scopes
+	}
+	utils.SuccessWithData(c, result)
+}
+
+// ReloadPolicy 重新加载策略文件
+func (ctl *RBACController) ReloadPolicy(c *gin.Context) {
+	claims, _ := middleware.GetClaims(c)
+	if err := ctl.rbacService.Reload(c.Request.Context(), claims.Subject); err != nil {
+		// 失败原因包含文件路径和解析细节，只记录在日志中
+		logrus.WithError(err).Error("重新加载 RBAC 策略失败")
+		utils.RespondError(c, err)
+		return
+	}
+
+	utils.Success(c, "rbac.reloaded")
+}
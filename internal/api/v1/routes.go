@@ -3,33 +3,75 @@ package v1
 
 import (
 	"gin_saas_auth/internal/api/middleware"
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/lifecycle"
+	"gin_saas_auth/internal/services"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
+// RouterDeps 路由依赖的控制器与服务
+type RouterDeps struct {
+	Lifecycle                  *lifecycle.Manager
+	LoadShedder                *services.LoadShedder // 为空时不做过载保护
+	TokenService               *services.TokenService
+	AuthController             *AuthController
+	SessionController          *SessionController
+	MFAController              *MFAController
+	VerificationController     *VerificationController
+	FederationController       *FederationController
+	RateLimiter                *services.RateLimiter // 为空时不限流
+	APIKeyRateLimiter          *services.RateLimiter // API 密钥自身的请求上限，不受全局限流开关影响
+	RBACService                *services.RBACService
+	RBACController             *RBACController
+	TenantService              *services.TenantService
+	TenantController           *TenantController
+	IdentityProviderController *IdentityProviderController
+	PasswordPolicyController   *PasswordPolicyController
+	APIKeyService              *services.APIKeyService
+	APIKeyController           *APIKeyController
+	AuditController            *AuditController
+	SignatureService           *services.SignatureService
+	InternalController         *InternalController
+}
+
 // SetupRouter 设置路由
-func SetupRouter(domain string) *gin.Engine {
+func SetupRouter(domain string, deps *RouterDeps) *gin.Engine {
 	// 创建Gin引擎
 	r := gin.New()
+	// 处理器直接以 gin.Context 作为 context 传给下游时，也能感知请求超时和取消
+	r.ContextWithFallback = true
 
 	// 添加中间件
+	r.Use(middleware.RequestIDMiddleware())
+	r.Use(middleware.LocaleMiddleware())
 	r.Use(middleware.LoggerMiddleware())
 	r.Use(gin.Recovery())
+	r.Use(middleware.ClientCertMiddleware())
 	r.Use(middleware.CORSMiddleware())
+	r.Use(middleware.MetricsMiddleware())
 
 	// 添加Swagger文档路由 - 动态配置host
 	url := ginSwagger.URL("http://" + domain + "/swagger/doc.json")
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, url))
 
 	// 健康检查和监控接口
-	r.GET("/health", HealthHandler)
+	r.GET("/health", HealthHandler(deps.Lifecycle, deps.LoadShedder))
 	r.GET("/ping", PingHandler)
-	r.GET("/metrics", MetricsHandler)
+	// 启用运维管理端口时监控指标只在管理端口提供
+	if !config.GlobalConfig.IsAdminEnabled() {
+		r.GET("/metrics", MetricsHandler)
+	}
 
 	// API路由分组
 	api := r.Group("/api")
+	api.Use(
+		middleware.TimeoutMiddleware(config.GlobalConfig.RequestTimeout),
+		middleware.BodyLimitMiddleware(config.GlobalConfig.BodyLimit),
+		middleware.LoadShedMiddleware(deps.LoadShedder),
+	)
 	{
 		// v1 版本分组
 		v1Group := api.Group("/v1")
@@ -37,11 +79,191 @@ func SetupRouter(domain string) *gin.Engine {
 			// 服务统计信息
 			v1Group.GET("/stats", StatsHandler)
 
-			// Consul 相关接口
-			consulGroup := v1Group.Group("/consul")
+			// Consul 相关接口，启用运维管理端口时只在管理端口提供
+			if !config.GlobalConfig.IsAdminEnabled() {
+				consulGroup := v1Group.Group("/consul")
+				{
+					consulGroup.GET("/info", ConsulInfoHandler)
+				}
+			}
+
+			// 以下接口均按租户隔离
+			tenantScoped := middleware.TenantMiddleware(deps.TenantService, deps.TokenService)
+			// 面向机器客户端的接口同时接受 API 密钥
+			tokenOrAPIKey := middleware.TokenOrAPIKeyMiddleware(deps.TokenService, deps.APIKeyService, deps.APIKeyRateLimiter)
+
+			// 限流规则：租户总量在租户解析之后计数，其余规则按分组叠加
+			rateLimits := config.GlobalConfig.RateLimit
+			limit := func(name, algorithm string, spec config.RateLimitSpec, key middleware.RateLimitKeyFunc) gin.HandlerFunc {
+				rule := services.RateLimitRule{Name: name, Algorithm: algorithm, Limit: spec.Limit, Window: spec.Window}
+				return middleware.RateLimitMiddleware(deps.RateLimiter, rule, key)
+			}
+			perTenant := limit("tenant", services.RateLimitTokenBucket, rateLimits.Tenant, middleware.RateLimitByTenant)
+			perIP := limit("auth", services.RateLimitSlidingWindow, rateLimits.Auth, middleware.RateLimitByIP)
+			credential := limit("credential", services.RateLimitSlidingWindow, rateLimits.Credential, middleware.RateLimitByIP)
+			perUser := limit("user", services.RateLimitTokenBucket, rateLimits.User, middleware.RateLimitByUser)
+			perPrincipal := limit("api", services.RateLimitTokenBucket, rateLimits.API, middleware.RateLimitByPrincipal)
+
+			// 认证相关接口
+			authGroup := v1Group.Group("/auth")
+			authGroup.Use(tenantScoped, perTenant)
+			{
+				// 无需登录的接口按客户端 IP 限流
+				public := authGroup.Group("")
+				public.Use(perIP)
+				{
+					public.POST("/register", credential, deps.AuthController.Register)
+					public.POST("/login", credential, deps.AuthController.Login)
+					public.POST("/refresh", deps.AuthController.Refresh)
+					public.POST("/logout", deps.AuthController.Logout)
+					public.POST("/mfa/verify", credential, deps.MFAController.Verify)
+					public.POST("/password/forgot", credential, deps.AuthController.ForgotPassword)
+					public.POST("/password/reset", credential, deps.AuthController.ResetPassword)
+					public.GET("/password/policy", deps.PasswordPolicyController.Get)
+					public.POST("/verify/email/send", credential, deps.VerificationController.SendEmailCode)
+					public.POST("/verify/email", credential, deps.VerificationController.ConfirmEmail)
+					public.POST("/login/code/send", credential, deps.VerificationController.SendLoginCode)
+					public.POST("/login/code", credential, deps.VerificationController.LoginWithCode)
+					public.GET("/oidc/providers", deps.FederationController.Providers)
+					public.GET("/oidc/:provider/authorize", deps.FederationController.Authorize)
+				}
+
+				// 需要登录的接口
+				authed := authGroup.Group("")
+				authed.Use(middleware.AuthMiddleware(deps.TokenService), perUser)
+				{
+					authed.GET("/me", deps.AuthController.Me)
+					authed.POST("/password/change", deps.AuthController.ChangePassword)
+
+					// 绑定手机号
+					authed.POST("/phone", credential, deps.VerificationController.SendPhoneCode)
+					authed.POST("/phone/confirm", deps.VerificationController.ConfirmPhone)
+
+					// 外部账户关联
+					authed.POST("/oidc/:provider/link", deps.FederationController.Link)
+					authed.GET("/identities", deps.FederationController.Identities)
+					authed.DELETE("/identities/:id", deps.FederationController.Unlink)
+
+					// 会话管理
+					authed.GET("/sessions", deps.SessionController.List)
+					authed.DELETE("/sessions", deps.SessionController.RevokeAll)
+					authed.DELETE("/sessions/:id", deps.SessionController.Revoke)
+
+					// 多因素认证
+					authed.GET("/mfa", deps.MFAController.Status)
+					authed.POST("/mfa/totp", deps.MFAController.EnrollTOTP)
+					authed.POST("/mfa/totp/confirm", deps.MFAController.ConfirmTOTP)
+					authed.DELETE("/mfa/totp", deps.MFAController.DisableTOTP)
+					authed.POST("/mfa/recovery-codes", deps.MFAController.RegenerateRecoveryCodes)
+				}
+			}
+
+			// 外部身份提供方回调，租户从 state 中还原，不经过租户解析
+			v1Group.GET("/auth/oidc/callback", perIP, deps.FederationController.Callback)
+
+			// 访问控制接口
+			rbacGroup := v1Group.Group("/rbac")
+			rbacGroup.Use(tenantScoped, perTenant, tokenOrAPIKey, perPrincipal)
+			{
+				rbacGroup.GET("/me", deps.RBACController.MyPermissions)
+				rbacGroup.POST("/reload",
+					middleware.RequirePermission(deps.RBACService, "rbac:reload"),
+					deps.RBACController.ReloadPolicy,
+				)
+			}
+
+			// 租户管理接口可查看和操作任意租户，只接受租户为 * 的平台级角色绑定
+			tenantGroup := v1Group.Group("/tenants")
+			tenantGroup.Use(tenantScoped, perTenant, tokenOrAPIKey, perPrincipal)
+			{
+				canRead := middleware.RequireSystemPermission(deps.RBACService, "tenants:read")
+				canWrite := middleware.RequireSystemPermission(deps.RBACService, "tenants:write")
+				tenantGroup.GET("", canRead, deps.TenantController.List)
+				tenantGroup.POST("", canWrite, deps.TenantController.Create)
+				tenantGroup.POST("/:id/suspend", canWrite, deps.TenantController.Suspend)
+				tenantGroup.POST("/:id/activate", canWrite, deps.TenantController.Activate)
+			}
+
+			// 租户身份提供方管理接口
+			idpGroup := v1Group.Group("/identity-providers")
+			idpGroup.Use(tenantScoped, perTenant, tokenOrAPIKey, perPrincipal)
 			{
-				consulGroup.GET("/info", ConsulInfoHandler)
+				canRead := middleware.RequirePermission(deps.RBACService, "idp:read")
+				canWrite := middleware.RequirePermission(deps.RBACService, "idp:write")
+				idpGroup.GET("", canRead, deps.IdentityProviderController.List)
+				idpGroup.POST("", canWrite, deps.IdentityProviderController.Create)
+				idpGroup.GET("/:name", canRead, deps.IdentityProviderController.Get)
+				idpGroup.PUT("/:name", canWrite, deps.IdentityProviderController.Update)
+				idpGroup.DELETE("/:name", canWrite, deps.IdentityProviderController.Delete)
 			}
+
+			// 租户密码策略管理接口
+			passwordPolicyGroup := v1Group.Group("/password-policy")
+			passwordPolicyGroup.Use(tenantScoped, perTenant, tokenOrAPIKey, perPrincipal)
+			{
+				canWrite := middleware.RequirePermission(deps.RBACService, "password_policy:write")
+				passwordPolicyGroup.GET("", deps.PasswordPolicyController.Get)
+				passwordPolicyGroup.PUT("", canWrite, deps.PasswordPolicyController.Update)
+				passwordPolicyGroup.DELETE("", canWrite, deps.PasswordPolicyController.Reset)
+			}
+
+			// API 密钥管理接口，只能使用用户访问令牌操作
+			apiKeyGroup := v1Group.Group("/api-keys")
+			apiKeyGroup.Use(tenantScoped, perTenant, middleware.AuthMiddleware(deps.TokenService), perUser)
+			{
+				apiKeyGroup.GET("", deps.APIKeyController.List)
+				apiKeyGroup.POST("", deps.APIKeyController.Create)
+				apiKeyGroup.GET("/:id", deps.APIKeyController.Get)
+				apiKeyGroup.DELETE("/:id", deps.APIKeyController.Revoke)
+			}
+
+			// 审计日志接口
+			auditGroup := v1Group.Group("/audit")
+			auditGroup.Use(tenantScoped, perTenant, tokenOrAPIKey, perPrincipal, middleware.RequirePermission(deps.RBACService, "audit:read"))
+			{
+				auditGroup.GET("/events", deps.AuditController.List)
+				auditGroup.GET("/verify", deps.AuditController.Verify)
+			}
+		}
+	}
+
+	// 内部服务接口，使用 HMAC 请求签名认证，不经过租户解析
+	internal := r.Group("/internal/v1")
+	internal.Use(
+		middleware.TimeoutMiddleware(config.GlobalConfig.RequestTimeout),
+		middleware.BodyLimitMiddleware(config.GlobalConfig.BodyLimit),
+		middleware.LoadShedMiddleware(deps.LoadShedder),
+		middleware.SignatureMiddleware(deps.SignatureService),
+	)
+	{
+		internal.POST("/introspect", deps.InternalController.Introspect)
+		internal.POST("/token", deps.InternalController.Exchange)
+	}
+
+	return r
+}
+
+// SetupAdminRouter 设置运维管理端口路由，该端口不经网关对外暴露
+// 健康检查与监控指标供 Consul 和 Prometheus 访问，其余接口需要携带管理令牌，只监听本机地址且未配置令牌时除外
+func SetupAdminRouter(cfg *config.Config, app *lifecycle.Manager, shedder *services.LoadShedder) *gin.Engine {
+	r := gin.New()
+	r.Use(middleware.RequestIDMiddleware())
+	r.Use(middleware.LocaleMiddleware())
+	r.Use(gin.Recovery())
+
+	r.GET("/health", HealthHandler(app, shedder))
+	r.GET("/metrics", MetricsHandler)
+
+	admin := r.Group("")
+	admin.Use(middleware.AdminTokenMiddleware(cfg.Admin.Token, cfg.IsAdminLoopback()))
+	{
+		admin.GET("/consul/info", ConsulInfoHandler)
+		admin.GET("/config", ConfigHandler)
+		admin.GET("/log-level", LogLevelHandler)
+		admin.PUT("/log-level", SetLogLevelHandler)
+		if cfg.Admin.Pprof {
+			admin.GET("/debug/pprof/*profile", PprofHandler)
+			admin.POST("/debug/pprof/*profile", PprofHandler)
 		}
 	}
 
@@ -0,0 +1,66 @@
+package v1
+
+import (
+	"strconv"
+
+	"gin_saas_auth/internal/api/middleware"
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/services"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionController 会话管理控制器
+type SessionController struct {
+	sessionService *services.SessionService
+	audit          services.AuditRecorder
+}
+
+// NewSessionController 创建会话管理控制器
+func NewSessionController(sessionService *services.SessionService, audit services.AuditRecorder) *SessionController {
+	return &SessionController{sessionService: sessionService, audit: audit}
+}
+
+// List 查询当前用户的有效会话
+func (ctl *SessionController) List(c *gin.Context) {
+	claims, _ := middleware.GetClaims(c)
+
+	sessions, err := ctl.sessionService.ListSessions(c.Request.Context(), claims.Subject, claims.SessionID)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	utils.SuccessWithData(c, sessions)
+}
+
+// Revoke 撤销当前用户的指定会话
+func (ctl *SessionController) Revoke(c *gin.Context) {
+	claims, _ := middleware.GetClaims(c)
+
+	if err := ctl.sessionService.RevokeSession(c.Request.Context(), claims.Subject, c.Param("id")); err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	recordAudit(c, ctl.audit, models.AuditTokenRevoked, c.Param("id"), map[string]string{"reason": "session_revoked"})
+
+	utils.Success(c, "session.revoked")
+}
+
+// RevokeAll 撤销当前用户的所有会话
+func (ctl *SessionController) RevokeAll(c *gin.Context) {
+	claims, _ := middleware.GetClaims(c)
+
+	count, err := ctl.sessionService.RevokeAllSessions(c.Request.Context(), claims.Subject)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	recordAudit(c, ctl.audit, models.AuditTokenRevoked, claims.Subject, map[string]string{
+		"reason":   "all_sessions_revoked",
+		"sessions": strconv.Itoa(count),
+	})
+
+	utils.SuccessWithData(c, gin.H{"revoked": count})
+}
@@ -0,0 +1,114 @@
+package v1
+
+import (
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/services"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateTenantRequest 创建租户请求
+type CreateTenantRequest struct {
+	ID   string `json:"id" binding:"required"`
+	Name string `json:"name"`
+}
+
+// tenantListSpec 租户列表的排序和过滤字段
+var tenantListSpec = utils.ListSpec[*models.Tenant]{
+	Fields: map[string]utils.ListField[*models.Tenant]{
+		"id": {
+			Type:     utils.FieldString,
+			Value:    func(t *models.Tenant) any { return t.ID },
+			Sortable: true,
+			Filters:  []utils.FilterOp{utils.FilterEq, utils.FilterIn, utils.FilterContains},
+		},
+		"name": {
+			Type:     utils.FieldString,
+			Value:    func(t *models.Tenant) any { return t.Name },
+			Sortable: true,
+			Filters:  []utils.FilterOp{utils.FilterEq, utils.FilterContains},
+		},
+		"status": {
+			Type:    utils.FieldString,
+			Value:   func(t *models.Tenant) any { return string(t.Status) },
+			Filters: []utils.FilterOp{utils.FilterEq, utils.FilterNe, utils.FilterIn},
+		},
+		"created_at": {
+			Type:     utils.FieldTime,
+			Value:    func(t *models.Tenant) any { return t.CreatedAt },
+			Sortable: true,
+			Filters:  []utils.FilterOp{utils.FilterGte, utils.FilterLt},
+		},
+	},
+	Key:         func(t *models.Tenant) string { return t.ID },
+	DefaultSort: "created_at",
+}
+
+// TenantController 租户管理控制器
+type TenantController struct {
+	tenantService *services.TenantService
+	audit         services.AuditRecorder
+}
+
+// NewTenantController 创建租户管理控制器
+func NewTenantController(tenantService *services.TenantService, audit services.AuditRecorder) *TenantController {
+	return &TenantController{tenantService: tenantService, audit: audit}
+}
+
+// List 分页查询租户
+// 查询参数: page, page_size, cursor, sort (id, name, created_at), id, name, status, created_at[gte|lt]
+func (ctl *TenantController) List(c *gin.Context) {
+	query, ok := utils.ParseListQuery(c, tenantListSpec)
+	if !ok {
+		return
+	}
+
+	tenants, err := ctl.tenantService.List(c.Request.Context())
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	page, info := tenantListSpec.Apply(tenants, query)
+	utils.SuccessWithPage(c, page, info)
+}
+
+// Create 创建租户
+func (ctl *TenantController) Create(c *gin.Context) {
+	var req CreateTenantRequest
+	if !utils.BindJSON(c, &req) {
+		return
+	}
+
+	tenant, err := ctl.tenantService.Create(c.Request.Context(), req.ID, req.Name)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	recordAudit(c, ctl.audit, models.AuditTenantCreated, tenant.ID, map[string]string{"name": tenant.Name})
+	utils.SuccessResponse(c, 201, "tenant.created", tenant)
+}
+
+// Suspend 停用租户，停用后该租户下的所有请求都会被拒绝
+func (ctl *TenantController) Suspend(c *gin.Context) {
+	ctl.updateStatus(c, models.TenantStatusSuspended)
+}
+
+// Activate 重新启用租户
+func (ctl *TenantController) Activate(c *gin.Context) {
+	ctl.updateStatus(c, models.TenantStatusActive)
+}
+
+// updateStatus 更新租户状态
+func (ctl *TenantController) updateStatus(c *gin.Context, status models.TenantStatus) {
+	tenant, err := ctl.tenantService.UpdateStatus(c.Request.Context(), c.Param("id"), status)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	recordAudit(c, ctl.audit, models.AuditTenantStatusChanged, tenant.ID, map[string]string{"status": string(tenant.Status)})
+
+	utils.SuccessWithData(c, tenant)
+}
@@ -0,0 +1,344 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gin_saas_auth/internal/api/middleware"
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/services"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// errDestinationRequired 邮箱和手机号均未提供或同时提供
+var errDestinationRequired = utils.NewAppError("DESTINATION_REQUIRED", http.StatusBadRequest, "请提供邮箱或手机号其中之一")
+
+// EmailRequest 指定邮箱的请求
+type EmailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// DestinationRequest 指定邮箱或手机号之一的请求
+type DestinationRequest struct {
+	Email string `json:"email" binding:"omitempty,email"`
+	Phone string `json:"phone"`
+}
+
+// VerifyCodeRequest 校验验证码请求，邮箱或手机号二选一
+type VerifyCodeRequest struct {
+	Email string `json:"email" binding:"omitempty,email"`
+	Phone string `json:"phone"`
+	Code  string `json:"code" binding:"required"`
+}
+
+// PhoneRequest 绑定手机号请求
+type PhoneRequest struct {
+	Phone string `json:"phone" binding:"required"`
+}
+
+// PhoneCodeRequest 确认绑定手机号请求
+type PhoneCodeRequest struct {
+	Phone string `json:"phone" binding:"required"`
+	Code  string `json:"code" binding:"required"`
+}
+
+// VerificationController 邮箱、手机验证与验证码登录控制器
+type VerificationController struct {
+	verification   *services.VerificationService
+	userService    *services.UserService
+	sessionService *services.SessionService
+	mfaService     *services.MFAService
+	lockout        *services.LockoutService
+	audit          services.AuditRecorder
+}
+
+// NewVerificationController 创建验证控制器
+func NewVerificationController(verification *services.VerificationService, userService *services.UserService, sessionService *services.SessionService, mfaService *services.MFAService, lockout *services.LockoutService, audit services.AuditRecorder) *VerificationController {
+	return &VerificationController{
+		verification:   verification,
+		userService:    userService,
+		sessionService: sessionService,
+		mfaService:     mfaService,
+		lockout:        lockout,
+		audit:          audit,
+	}
+}
+
+// SendEmailCode 重新发送注册验证邮件
+// 无论邮箱是否存在或是否已验证都返回相同响应，避免泄露账户信息
+func (ctl *VerificationController) SendEmailCode(c *gin.Context) {
+	var req EmailRequest
+	if !utils.BindJSON(c, &req) {
+		return
+	}
+
+	tenantID := middleware.GetTenantID(c)
+	email, _ := services.NormalizeDestination(models.ChannelEmail, req.Email)
+	user, err := ctl.userService.FindByDestination(c.Request.Context(), tenantID, models.ChannelEmail, email)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	send := services.VerificationRequest{
+		TenantID:    tenantID,
+		Channel:     models.ChannelEmail,
+		Destination: email,
+		Purpose:     models.PurposeVerify,
+	}
+	if user != nil && !user.IsEmailVerified() {
+		send.UserID = user.ID
+	}
+	if retryAfter, err := ctl.verification.SendCode(c.Request.Context(), send); err != nil {
+		respondSendError(c, err, retryAfter)
+		return
+	}
+
+	utils.Success(c, "verification.email_requested")
+}
+
+// ConfirmEmail 使用邮件中的验证码完成邮箱验证
+func (ctl *VerificationController) ConfirmEmail(c *gin.Context) {
+	var req VerifyCodeRequest
+	if !utils.BindJSON(c, &req) {
+		return
+	}
+	if req.Email == "" {
+		utils.BadRequest(c, "verification.email_required")
+		return
+	}
+
+	email, _ := services.NormalizeDestination(models.ChannelEmail, req.Email)
+	record, err := ctl.verification.Verify(c.Request.Context(), middleware.GetTenantID(c), models.ChannelEmail, email, models.PurposeVerify, req.Code)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	if err := ctl.userService.MarkEmailVerified(c.Request.Context(), record.UserID); err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	// 验证邮箱的请求未登录，操作者即验证码所属的用户
+	event := auditEvent(c, models.AuditEmailVerified, email, nil)
+	event.ActorID = record.UserID
+	ctl.audit.Record(c.Request.Context(), event)
+
+	utils.Success(c, "verification.email_verified")
+}
+
+// SendPhoneCode 向待绑定的手机号发送验证码
+func (ctl *VerificationController) SendPhoneCode(c *gin.Context) {
+	var req PhoneRequest
+	if !utils.BindJSON(c, &req) {
+		return
+	}
+
+	phone, err := services.NormalizeDestination(models.ChannelSMS, req.Phone)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	claims, _ := middleware.GetClaims(c)
+	tenantID := middleware.GetTenantID(c)
+	owner, err := ctl.userService.FindByDestination(c.Request.Context(), tenantID, models.ChannelSMS, phone)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	if owner != nil && owner.ID != claims.Subject {
+		utils.RespondError(c, services.ErrPhoneInUse)
+		return
+	}
+
+	retryAfter, err := ctl.verification.SendCode(c.Request.Context(), services.VerificationRequest{
+		TenantID:    tenantID,
+		UserID:      claims.Subject,
+		Channel:     models.ChannelSMS,
+		Destination: phone,
+		Purpose:     models.PurposeVerify,
+	})
+	if err != nil {
+		respondSendError(c, err, retryAfter)
+		return
+	}
+
+	utils.Success(c, "verification.code_sent")
+}
+
+// ConfirmPhone 校验验证码后绑定手机号，已绑定的手机号将被替换
+func (ctl *VerificationController) ConfirmPhone(c *gin.Context) {
+	var req PhoneCodeRequest
+	if !utils.BindJSON(c, &req) {
+		return
+	}
+
+	phone, err := services.NormalizeDestination(models.ChannelSMS, req.Phone)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	claims, _ := middleware.GetClaims(c)
+	record, err := ctl.verification.Verify(c.Request.Context(), middleware.GetTenantID(c), models.ChannelSMS, phone, models.PurposeVerify, req.Code)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	// 其他用户随后为同一手机号申请了验证码时，最近的验证码不属于当前用户
+	if record.UserID != claims.Subject {
+		utils.RespondError(c, services.ErrInvalidVerificationCode)
+		return
+	}
+	if err := ctl.userService.SetPhone(c.Request.Context(), claims.Subject, phone); err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	recordAudit(c, ctl.audit, models.AuditPhoneVerified, phone, nil)
+
+	utils.Success(c, "verification.phone_bound")
+}
+
+// SendLoginCode 发送登录验证码，手机号须已绑定
+// 无论账户是否存在都返回相同响应，避免泄露账户信息
+func (ctl *VerificationController) SendLoginCode(c *gin.Context) {
+	var req DestinationRequest
+	if !utils.BindJSON(c, &req) {
+		return
+	}
+
+	channel, destination, err := bindDestination(req.Email, req.Phone)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	tenantID := middleware.GetTenantID(c)
+	user, err := ctl.userService.FindByDestination(c.Request.Context(), tenantID, channel, destination)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	send := services.VerificationRequest{
+		TenantID:    tenantID,
+		Channel:     channel,
+		Destination: destination,
+		Purpose:     models.PurposeLogin,
+	}
+	if user != nil && user.IsActive() {
+		send.UserID = user.ID
+	}
+	if retryAfter, err := ctl.verification.SendCode(c.Request.Context(), send); err != nil {
+		respondSendError(c, err, retryAfter)
+		return
+	}
+
+	utils.Success(c, "verification.login_code_requested")
+}
+
+// LoginWithCode 使用邮箱或短信验证码登录
+// 与密码登录共用防暴力破解计数；已启用动态码认证的用户同样需要完成 MFA 挑战
+func (ctl *VerificationController) LoginWithCode(c *gin.Context) {
+	var req VerifyCodeRequest
+	if !utils.BindJSON(c, &req) {
+		return
+	}
+
+	channel, destination, err := bindDestination(req.Email, req.Phone)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	attempt := loginAttempt(c, destination)
+	if decision := ctl.lockout.Check(c.Request.Context(), attempt); decision.Locked {
+		respondLocked(c, decision)
+		return
+	}
+
+	record, err := ctl.verification.Verify(c.Request.Context(), attempt.TenantID, channel, destination, models.PurposeLogin, req.Code)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidVerificationCode) {
+			respondLoginFailure(c, err, ctl.lockout.RecordFailure(c.Request.Context(), attempt))
+			return
+		}
+		utils.RespondError(c, err)
+		return
+	}
+
+	user, err := ctl.userService.GetUser(c.Request.Context(), record.UserID)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	if !user.IsActive() {
+		utils.RespondError(c, services.ErrUserDisabled)
+		return
+	}
+	ctl.lockout.RecordSuccess(c.Request.Context(), attempt, user.ID)
+
+	// 收到邮件验证码即证明邮箱归属
+	amr := []string{models.AMROTP}
+	if channel == models.ChannelSMS {
+		amr = []string{models.AMRSMS}
+	} else if !user.IsEmailVerified() {
+		if err := ctl.userService.MarkEmailVerified(c.Request.Context(), user.ID); err != nil {
+			logrus.WithError(err).WithField("user_id", user.ID).Warn("验证码登录后标记邮箱已验证失败")
+		}
+	}
+
+	mfaEnabled, err := ctl.mfaService.IsEnabled(c.Request.Context(), user.ID)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	if mfaEnabled {
+		challenge, err := ctl.mfaService.StartChallenge(user, amr)
+		if err != nil {
+			utils.RespondError(c, err)
+			return
+		}
+		utils.SuccessWithData(c, challenge)
+		return
+	}
+
+	result, err := ctl.sessionService.StartSession(c.Request.Context(), user, amr, clientInfo(c))
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	respondSession(c, ctl.sessionService, result, wantsCookies(c))
+}
+
+// bindDestination 从邮箱或手机号中选出验证码渠道和规范化后的目标地址，二者必须且只能提供一个
+func bindDestination(email, phone string) (string, string, error) {
+	switch {
+	case email != "" && phone == "":
+		destination, err := services.NormalizeDestination(models.ChannelEmail, email)
+		return models.ChannelEmail, destination, err
+	case phone != "" && email == "":
+		destination, err := services.NormalizeDestination(models.ChannelSMS, phone)
+		return models.ChannelSMS, destination, err
+	}
+	return "", "", errDestinationRequired
+}
+
+// respondSendError 发送验证码失败，发送过于频繁时告知客户端等待时间
+func respondSendError(c *gin.Context, err error, retryAfter time.Duration) {
+	if !errors.Is(err, services.ErrVerificationThrottled) {
+		utils.RespondError(c, err)
+		return
+	}
+
+	seconds := int((retryAfter + time.Second - 1) / time.Second)
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	utils.WriteProblem(c, utils.ErrorProblem(err).With("retry_after", seconds))
+}
@@ -1,9 +1,11 @@
 package config
 
 import (
+	"fmt"
 	"net"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -26,6 +28,63 @@ type Config struct {
 
 	// 服务配置
 	Service ServiceConfig
+
+	// 认证配置
+	Auth AuthConfig
+
+	// 数据库配置
+	Database DatabaseConfig
+
+	// 访问控制配置
+	RBAC RBACConfig
+
+	// 多租户配置
+	Tenant TenantConfig
+
+	// 内部服务请求签名配置
+	Signing SigningConfig
+
+	// Redis 配置，CONSUL_DEPEND_REDIS=true 时启用
+	Redis RedisConfig
+
+	// 登录防暴力破解配置
+	Lockout LockoutConfig
+
+	// HTTPS 与客户端证书配置
+	TLS TLSConfig
+
+	// 浏览器 Cookie 会话配置
+	Cookie CookieConfig
+
+	// 邮箱与手机验证码配置
+	Verification VerificationConfig
+
+	// SMTP 邮件发送配置
+	SMTP SMTPConfig
+
+	// 外部身份提供方（OIDC）登录配置，提供方本身按租户在管理接口中配置
+	OIDC OIDCConfig
+
+	// 默认密码策略，租户可在管理接口中覆盖
+	Password PasswordConfig
+
+	// 组件启动与优雅关闭配置
+	Lifecycle LifecycleConfig
+
+	// 运维管理端口配置
+	Admin AdminConfig
+
+	// 请求限流配置
+	RateLimit RateLimitConfig
+
+	// 过载保护配置
+	LoadShed LoadShedConfig
+
+	// 请求处理超时配置
+	RequestTimeout RequestTimeoutConfig
+
+	// 请求体大小限制配置
+	BodyLimit BodyLimitConfig
 }
 
 // AppConfig 应用基础配置
@@ -100,6 +159,243 @@ type ServiceConfig struct {
 	HealthCheckAddress string // Consul 健康检查专用地址（容器内部地址）
 }
 
+// AuthConfig 认证相关配置
+type AuthConfig struct {
+	JWTSecret      string
+	JWTIssuer      string
+	AccessTokenTTL time.Duration
+	ResetTokenTTL  time.Duration
+
+	// 刷新令牌每次使用都会轮换，有效期按最后一次轮换计算，但不超过会话最长存活时间
+	RefreshTokenTTL    time.Duration
+	SessionMaxLifetime time.Duration
+
+	// 多因素认证
+	MFAIssuer        string        // 认证器应用中显示的签发方名称
+	MFAEncryptionKey string        // TOTP 密钥加密密钥，为空时由 JWT 密钥派生
+	MFAChallengeTTL  time.Duration // 登录时等待输入动态码的有效期
+
+	// argon2id 密码哈希参数，调整后用户下次登录时会自动重新哈希
+	Argon2Memory      uint32 // 单位 KiB
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+
+	// API 密钥
+	APIKeyDefaultRateLimit int // 创建密钥时未指定限流值时使用的每分钟请求上限，0 表示不限制
+	APIKeyMaxPerUser       int // 每个用户最多持有的有效密钥数量
+
+	// 令牌交换（RFC 8693），调用方以内部签名密钥 ID 标识
+	ExchangeTokenTTL  time.Duration       // 交换后令牌的有效期，不超过原令牌的剩余有效期
+	ExchangeAudiences map[string][]string // 调用方可申请的目标受众，* 表示不限；未配置的调用方不能交换令牌
+}
+
+// DatabaseConfig 数据库相关配置
+type DatabaseConfig struct {
+	Driver          string
+	DSN             string // 为空时使用内存存储
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// RBACConfig 访问控制相关配置
+type RBACConfig struct {
+	PolicyFile     string        // JSON 策略文件路径，为空时所有需要权限的接口都拒绝访问
+	ReloadInterval time.Duration // 检查策略文件变更的间隔，为 0 时不自动重新加载
+}
+
+// TenantConfig 多租户相关配置
+type TenantConfig struct {
+	Header        string        // 指定租户的请求头
+	DefaultTenant string        // 无法从请求中识别租户时使用的默认租户，为空时拒绝请求
+	CacheTTL      time.Duration // 租户信息缓存时间
+}
+
+// SigningConfig 内部服务间 HMAC 请求签名配置
+type SigningConfig struct {
+	Keys    map[string]string // 密钥 ID 到共享密钥的映射，为空时内部接口拒绝所有请求
+	MaxSkew time.Duration     // 允许的时间偏差，同时决定防重放窗口
+}
+
+// RedisConfig Redis 连接配置
+type RedisConfig struct {
+	Addr      string
+	Password  string
+	DB        int
+	KeyPrefix string // 所有键的前缀，多个服务共用 Redis 时避免冲突
+}
+
+// LockoutConfig 登录防暴力破解配置
+// 失败次数按账户（租户+邮箱）和客户端 IP 分别统计，最后一次失败后超过统计窗口即清零
+type LockoutConfig struct {
+	Window time.Duration // 失败次数统计窗口
+
+	// 账户维度：超过免惩罚次数后按指数退避短暂锁定，达到阈值后长时间锁定
+	FreeAttempts    int
+	BackoffBase     time.Duration
+	BackoffMax      time.Duration
+	Threshold       int
+	Duration        time.Duration
+	CaptchaAfter    int // 失败次数达到该值后要求验证码，0 表示不要求
+	IPThreshold     int // IP 维度锁定阈值，0 表示不按 IP 锁定
+	IPCaptchaAfter  int // IP 维度要求验证码的失败次数，0 表示不要求
+	IPLockoutPeriod time.Duration
+}
+
+// TLSConfig HTTPS 与双向 TLS 配置
+// 证书和 CA 文件变更后自动重新加载，无需重启服务
+type TLSConfig struct {
+	CertFile       string        // 服务端证书，与 KeyFile 同时配置时启用 HTTPS
+	KeyFile        string        // 服务端私钥
+	ClientCAFile   string        // 校验客户端证书的 CA 证书包
+	ClientAuth     string        // 客户端证书校验方式: none, optional, require
+	ReloadInterval time.Duration // 检查证书文件变更的间隔，为 0 时不自动重新加载
+	// Consul 健康检查跳过证书校验，服务使用私有 CA 签发的证书时需要开启
+	CheckSkipVerify bool
+}
+
+// TLS 客户端证书校验方式
+const (
+	ClientAuthNone     = "none"     // 不请求客户端证书
+	ClientAuthOptional = "optional" // 客户端提供证书时校验，未提供时放行
+	ClientAuthRequire  = "require"  // 必须提供有效的客户端证书
+)
+
+// CookieConfig 浏览器 Cookie 会话配置
+// 令牌保存在 HttpOnly Cookie 中，写请求需通过 CSRF 请求头回传 CSRF Cookie 的值
+type CookieConfig struct {
+	AccessName  string // 访问令牌 Cookie 名称
+	RefreshName string // 刷新令牌 Cookie 名称，仅发送到认证接口
+	CSRFName    string // CSRF 令牌 Cookie 名称，前端脚本可读
+	CSRFHeader  string // 回传 CSRF 令牌的请求头
+	Domain      string // 为空时仅当前域名可用
+	Secure      bool
+	SameSite    string // lax, strict, none；none 时必须同时开启 Secure
+}
+
+// VerificationConfig 邮箱与手机验证码配置
+// 发送频率按目标地址统计，与租户无关，避免同一邮箱或手机号被借助多个租户轰炸
+type VerificationConfig struct {
+	CodeLength     int           // 验证码位数
+	CodeTTL        time.Duration // 验证码有效期
+	MaxAttempts    int           // 单个验证码允许的校验次数，超过后需重新获取
+	ResendInterval time.Duration // 同一目标两次发送的最小间隔
+	MaxSends       int           // 统计窗口内同一目标的最大发送次数，0 表示不限制
+	SendWindow     time.Duration
+	RequireEmail   bool   // 邮箱未验证的用户不能使用密码登录
+	LinkBaseURL    string // 前端验证页面地址，配置后邮件中附带一键验证链接
+
+	EmailSender string // 邮件发送方式: log, smtp, none
+	SMSSender   string // 短信发送方式: log, none
+	OutboxFile  string // log 发送方式写入的文件，为空时输出到日志
+}
+
+// 消息发送方式
+const (
+	SenderLog  = "log"  // 写入文件或日志，用于本地开发和测试
+	SenderSMTP = "smtp" // 通过 SMTP 发送邮件
+	SenderNone = "none" // 不启用该渠道
+)
+
+// SMTPConfig SMTP 邮件发送配置
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	TLSMode  string // starttls, tls（隐式 TLS，通常为 465 端口）, none
+	Timeout  time.Duration
+}
+
+// OIDCConfig 外部身份提供方登录配置
+type OIDCConfig struct {
+	CallbackURL   string        // 在身份提供方登记的回调地址，所有提供方共用
+	ReturnOrigins []string      // 登录完成后允许跳转的前端地址来源，为空时只能以 JSON 返回令牌
+	StateTTL      time.Duration // 从跳转到回调的最长时间
+	EncryptionKey string        // 加密客户端密钥和 state 的密钥，为空时由 JWT 密钥派生
+	HTTPTimeout   time.Duration // 请求身份提供方的超时时间
+	DiscoveryTTL  time.Duration // 提供方元数据和签名公钥的缓存时间
+}
+
+// LifecycleConfig 组件启动与优雅关闭配置
+// 收到退出信号后先将健康检查置为不可用并从 Consul 注销，等待网关感知后再处理完进行中的请求
+type LifecycleConfig struct {
+	StartTimeout     time.Duration // 单个组件启动的最长时间
+	PropagationDelay time.Duration // 注销后等待网关停止转发新请求的时间
+	DrainTimeout     time.Duration // 等待进行中请求处理完成的最长时间
+	StopTimeout      time.Duration // 单个后台任务或依赖停止的最长时间
+}
+
+// AdminConfig 运维管理端口配置
+// 启用后监控指标、健康检查、pprof、日志级别和配置查看在独立端口提供，不经网关对外暴露
+type AdminConfig struct {
+	Enabled bool
+	Host    string // 监听地址，默认只监听本机
+	Port    int
+	Token   string // 访问 pprof、日志级别和配置查看接口所需的 Bearer 令牌，为空时只允许监听本机地址的管理端口访问
+	Pprof   bool   // 是否提供 /debug/pprof 性能分析接口
+}
+
+// RateLimitConfig 请求限流配置，各路由分组的规则在 SetupRouter 中按以下额度设置
+type RateLimitConfig struct {
+	Enabled    bool
+	Backend    string        // memory 或 redis，为空时依赖 Redis 则使用 redis
+	Auth       RateLimitSpec // 认证接口，按客户端 IP 滑动窗口计数
+	Credential RateLimitSpec // 登录、注册、找回密码、发送验证码等凭证接口，按客户端 IP 滑动窗口计数
+	User       RateLimitSpec // 登录用户接口，按用户令牌桶
+	API        RateLimitSpec // 管理接口，按 API 密钥或用户令牌桶
+	Tenant     RateLimitSpec // 租户内全部请求，按租户令牌桶
+}
+
+// RateLimitSpec 限流额度：每个窗口内允许的请求数，Limit 为 0 表示不限制
+type RateLimitSpec struct {
+	Limit  int
+	Window time.Duration
+}
+
+// LoadShedConfig 过载保护配置
+// 全局并发上限按请求耗时自适应调整（梯度算法），超过上限的请求短暂排队，排队已满或等待超时时直接拒绝
+type LoadShedConfig struct {
+	Enabled          bool
+	InitialLimit     int           // 初始并发上限
+	MinLimit         int           // 并发上限的下限
+	MaxLimit         int           // 并发上限的上限
+	TargetLatency    time.Duration // 目标处理耗时，超过后逐步降低并发上限
+	MaxQueue         int           // 最多排队的请求数
+	QueueTimeout     time.Duration // 排队最长等待时间
+	RouteMaxInFlight int           // 单个路由的并发上限，0 表示不限制
+	RetryAfter       time.Duration // 拒绝时建议客户端重试的等待时间
+	DegradedHold     time.Duration // 最近一次拒绝请求后，健康检查报告降级状态的持续时间
+}
+
+// RequestTimeoutConfig 请求处理超时配置
+// 超时后取消请求上下文，尚未返回结果或因取消而失败的请求返回 504
+type RequestTimeoutConfig struct {
+	Default time.Duration            // 默认超时，未配置时使用 CONSUL_TIMEOUT_MS，0 表示不限制
+	Routes  map[string]time.Duration // 按路由覆盖，键为 "方法 路由模板" 或 "路由模板"
+}
+
+// BodyLimitConfig 请求体大小限制配置，超过限制的请求返回 413
+type BodyLimitConfig struct {
+	Default int64            // 默认上限（字节），0 表示不限制
+	Routes  map[string]int64 // 按路由覆盖，键为 "方法 路由模板" 或 "路由模板"
+}
+
+// PasswordConfig 默认密码策略
+type PasswordConfig struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	CheckBreached bool   // 拒绝出现在泄露密码库中的密码
+	BreachedFile  string // 泄露密码库文件，每行一个 SHA-1 摘要，可带 :次数 后缀
+	HistorySize   int    // 不能与最近 N 次使用过的密码相同（包括当前密码），0 表示不限制
+	MaxAgeDays    int    // 密码最长使用天数，过期后登录时必须修改，0 表示不过期
+}
+
 var GlobalConfig *Config
 
 // LoadConfig 加载配置
@@ -186,9 +482,188 @@ func LoadConfig() (*Config, error) {
 		Service: ServiceConfig{
 			Address:            getServiceAddress(),
 			Port:               getServicePort(getEnv("APP_PORT", "8080")),
-			Scheme:             getEnv("SERVICE_SCHEME", "http"),
+			Scheme:             getEnv("SERVICE_SCHEME", defaultScheme()),
 			HealthCheckAddress: getEnv("CONSUL_HEALTH_CHECK_ADDRESS", ""),
 		},
+		Auth: AuthConfig{
+			JWTSecret:          getEnv("AUTH_JWT_SECRET", ""),
+			JWTIssuer:          getEnv("AUTH_JWT_ISSUER", getEnv("APP_NAME", "auth-service")),
+			AccessTokenTTL:     parseDuration("AUTH_ACCESS_TOKEN_TTL", "15m"),
+			ResetTokenTTL:      parseDuration("AUTH_RESET_TOKEN_TTL", "30m"),
+			RefreshTokenTTL:    parseDuration("AUTH_REFRESH_TOKEN_TTL", "720h"),
+			SessionMaxLifetime: parseDuration("AUTH_SESSION_MAX_LIFETIME", "2160h"),
+			MFAIssuer:          getEnv("AUTH_MFA_ISSUER", getEnv("APP_NAME", "auth-service")),
+			MFAEncryptionKey:   getEnv("AUTH_MFA_ENCRYPTION_KEY", ""),
+			MFAChallengeTTL:    parseDuration("AUTH_MFA_CHALLENGE_TTL", "5m"),
+			Argon2Memory:       uint32(getInt("AUTH_ARGON2_MEMORY", 64*1024)),
+			Argon2Iterations:   uint32(getInt("AUTH_ARGON2_ITERATIONS", 3)),
+			Argon2Parallelism:  uint8(getInt("AUTH_ARGON2_PARALLELISM", 2)),
+
+			APIKeyDefaultRateLimit: getInt("AUTH_API_KEY_RATE_LIMIT", 600),
+			APIKeyMaxPerUser:       getInt("AUTH_API_KEY_MAX_PER_USER", 20),
+
+			ExchangeTokenTTL:  parseDuration("AUTH_EXCHANGE_TOKEN_TTL", "5m"),
+			ExchangeAudiences: parseKeyLists("AUTH_EXCHANGE_AUDIENCES"),
+		},
+		Database: DatabaseConfig{
+			Driver:          getEnv("DB_DRIVER", "postgres"),
+			DSN:             getEnv("DB_DSN", ""),
+			MaxOpenConns:    getInt("DB_MAX_OPEN_CONNS", 20),
+			MaxIdleConns:    getInt("DB_MAX_IDLE_CONNS", 5),
+			ConnMaxLifetime: parseDuration("DB_CONN_MAX_LIFETIME", "30m"),
+		},
+		RBAC: RBACConfig{
+			PolicyFile:     getEnv("RBAC_POLICY_FILE", ""),
+			ReloadInterval: parseDuration("RBAC_RELOAD_INTERVAL", "30s"),
+		},
+		Tenant: TenantConfig{
+			Header:        getEnv("TENANT_HEADER", "X-Tenant-ID"),
+			DefaultTenant: getEnv("TENANT_DEFAULT", "default"),
+			CacheTTL:      parseDuration("TENANT_CACHE_TTL", "30s"),
+		},
+		Signing: SigningConfig{
+			Keys:    parseKeyValues("SIGNING_KEYS"),
+			MaxSkew: parseDuration("SIGNING_MAX_SKEW", "5m"),
+		},
+		Redis: RedisConfig{
+			Addr:      getEnv("REDIS_ADDR", "127.0.0.1:6379"),
+			Password:  getEnv("REDIS_PASSWORD", ""),
+			DB:        getInt("REDIS_DB", 0),
+			KeyPrefix: getEnv("REDIS_KEY_PREFIX", getEnv("APP_NAME", "auth-service")+":"),
+		},
+		Lockout: LockoutConfig{
+			Window:          parseDuration("LOCKOUT_WINDOW", "15m"),
+			FreeAttempts:    getInt("LOCKOUT_FREE_ATTEMPTS", 3),
+			BackoffBase:     parseDuration("LOCKOUT_BACKOFF_BASE", "1s"),
+			BackoffMax:      parseDuration("LOCKOUT_BACKOFF_MAX", "5m"),
+			Threshold:       getInt("LOCKOUT_THRESHOLD", 10),
+			Duration:        parseDuration("LOCKOUT_DURATION", "15m"),
+			CaptchaAfter:    getInt("LOCKOUT_CAPTCHA_AFTER", 5),
+			IPThreshold:     getInt("LOCKOUT_IP_THRESHOLD", 100),
+			IPCaptchaAfter:  getInt("LOCKOUT_IP_CAPTCHA_AFTER", 30),
+			IPLockoutPeriod: parseDuration("LOCKOUT_IP_DURATION", "15m"),
+		},
+		TLS: TLSConfig{
+			CertFile:        getEnv("TLS_CERT_FILE", ""),
+			KeyFile:         getEnv("TLS_KEY_FILE", ""),
+			ClientCAFile:    getEnv("TLS_CLIENT_CA_FILE", ""),
+			ClientAuth:      getEnv("TLS_CLIENT_AUTH", defaultClientAuth()),
+			ReloadInterval:  parseDuration("TLS_RELOAD_INTERVAL", "1m"),
+			CheckSkipVerify: getBool("CONSUL_CHECK_TLS_SKIP_VERIFY", false),
+		},
+		Cookie: CookieConfig{
+			AccessName:  getEnv("COOKIE_ACCESS_NAME", "access_token"),
+			RefreshName: getEnv("COOKIE_REFRESH_NAME", "refresh_token"),
+			CSRFName:    getEnv("COOKIE_CSRF_NAME", "csrf_token"),
+			CSRFHeader:  getEnv("CSRF_HEADER", "X-CSRF-Token"),
+			Domain:      getEnv("COOKIE_DOMAIN", ""),
+			Secure:      getBool("COOKIE_SECURE", true),
+			SameSite:    strings.ToLower(getEnv("COOKIE_SAMESITE", "lax")),
+		},
+		Verification: VerificationConfig{
+			CodeLength:     getInt("VERIFY_CODE_LENGTH", 6),
+			CodeTTL:        parseDuration("VERIFY_CODE_TTL", "10m"),
+			MaxAttempts:    getInt("VERIFY_MAX_ATTEMPTS", 5),
+			ResendInterval: parseDuration("VERIFY_RESEND_INTERVAL", "60s"),
+			MaxSends:       getInt("VERIFY_MAX_SENDS", 5),
+			SendWindow:     parseDuration("VERIFY_SEND_WINDOW", "1h"),
+			RequireEmail:   getBool("VERIFY_REQUIRE_EMAIL", false),
+			LinkBaseURL:    getEnv("VERIFY_LINK_BASE_URL", ""),
+			EmailSender:    strings.ToLower(getEnv("VERIFY_EMAIL_SENDER", SenderLog)),
+			SMSSender:      strings.ToLower(getEnv("VERIFY_SMS_SENDER", SenderLog)),
+			OutboxFile:     getEnv("VERIFY_OUTBOX_FILE", ""),
+		},
+		SMTP: SMTPConfig{
+			Host:     getEnv("SMTP_HOST", ""),
+			Port:     getInt("SMTP_PORT", 587),
+			Username: getEnv("SMTP_USERNAME", ""),
+			Password: getEnv("SMTP_PASSWORD", ""),
+			From:     getEnv("SMTP_FROM", ""),
+			TLSMode:  strings.ToLower(getEnv("SMTP_TLS", "starttls")),
+			Timeout:  parseDuration("SMTP_TIMEOUT", "10s"),
+		},
+		OIDC: OIDCConfig{
+			CallbackURL:   getEnv("OIDC_CALLBACK_URL", ""),
+			ReturnOrigins: parseList("OIDC_RETURN_ORIGINS"),
+			StateTTL:      parseDuration("OIDC_STATE_TTL", "10m"),
+			EncryptionKey: getEnv("OIDC_ENCRYPTION_KEY", ""),
+			HTTPTimeout:   parseDuration("OIDC_HTTP_TIMEOUT", "10s"),
+			DiscoveryTTL:  parseDuration("OIDC_DISCOVERY_TTL", "1h"),
+		},
+		Password: PasswordConfig{
+			MinLength:     getInt("PASSWORD_MIN_LENGTH", 8),
+			MaxLength:     getInt("PASSWORD_MAX_LENGTH", 128),
+			RequireUpper:  getBool("PASSWORD_REQUIRE_UPPER", false),
+			RequireLower:  getBool("PASSWORD_REQUIRE_LOWER", false),
+			RequireDigit:  getBool("PASSWORD_REQUIRE_DIGIT", false),
+			RequireSymbol: getBool("PASSWORD_REQUIRE_SYMBOL", false),
+			CheckBreached: getBool("PASSWORD_CHECK_BREACHED", false),
+			BreachedFile:  getEnv("PASSWORD_BREACHED_FILE", ""),
+			HistorySize:   getInt("PASSWORD_HISTORY_SIZE", 0),
+			MaxAgeDays:    getInt("PASSWORD_MAX_AGE_DAYS", 0),
+		},
+		Lifecycle: LifecycleConfig{
+			StartTimeout:     parseDuration("STARTUP_TIMEOUT", "15s"),
+			PropagationDelay: parseDuration("SHUTDOWN_PROPAGATION_DELAY", "5s"),
+			DrainTimeout:     parseDuration("SHUTDOWN_DRAIN_TIMEOUT", "20s"),
+			StopTimeout:      parseDuration("SHUTDOWN_STOP_TIMEOUT", "5s"),
+		},
+		Admin: AdminConfig{
+			Enabled: getBool("ADMIN_ENABLED", false),
+			Host:    getEnv("ADMIN_HOST", "127.0.0.1"),
+			Port:    getInt("ADMIN_PORT", 9090),
+			Token:   getEnv("ADMIN_TOKEN", ""),
+			Pprof:   getBool("ADMIN_PPROF", false),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:    getBool("RATE_LIMIT_ENABLED", true),
+			Backend:    strings.ToLower(getEnv("RATE_LIMIT_BACKEND", "")),
+			Auth:       parseRateLimit("RATE_LIMIT_AUTH", "60/1m"),
+			Credential: parseRateLimit("RATE_LIMIT_CREDENTIAL", "10/1m"),
+			User:       parseRateLimit("RATE_LIMIT_USER", "120/1m"),
+			API:        parseRateLimit("RATE_LIMIT_API", "600/1m"),
+			Tenant:     parseRateLimit("RATE_LIMIT_TENANT", "6000/1m"),
+		},
+		LoadShed: LoadShedConfig{
+			Enabled:          getBool("LOAD_SHED_ENABLED", true),
+			InitialLimit:     getInt("LOAD_SHED_INITIAL_LIMIT", 100),
+			MinLimit:         getInt("LOAD_SHED_MIN_LIMIT", 10),
+			MaxLimit:         getInt("LOAD_SHED_MAX_LIMIT", 1000),
+			TargetLatency:    parseDuration("LOAD_SHED_TARGET_LATENCY", "500ms"),
+			MaxQueue:         getInt("LOAD_SHED_MAX_QUEUE", 50),
+			QueueTimeout:     parseDuration("LOAD_SHED_QUEUE_TIMEOUT", "200ms"),
+			RouteMaxInFlight: getInt("LOAD_SHED_ROUTE_MAX_INFLIGHT", 0),
+			RetryAfter:       parseDuration("LOAD_SHED_RETRY_AFTER", "1s"),
+			DegradedHold:     parseDuration("LOAD_SHED_DEGRADED_HOLD", "30s"),
+		},
+		RequestTimeout: RequestTimeoutConfig{
+			Routes: parseRouteTimeouts("REQUEST_TIMEOUT_ROUTES"),
+		},
+		BodyLimit: BodyLimitConfig{
+			Default: getByteSize("REQUEST_BODY_LIMIT", "1MB"),
+			Routes:  parseRouteBodyLimits("REQUEST_BODY_LIMIT_ROUTES"),
+		},
+	}
+
+	// 请求超时默认与网关转发超时（CONSUL_TIMEOUT_MS）一致
+	timeoutMs, err := strconv.Atoi(config.Consul.Meta.TimeoutMs)
+	if err != nil {
+		logrus.Warnf("无法解析 CONSUL_TIMEOUT_MS: %s, 默认不限制请求处理时间", config.Consul.Meta.TimeoutMs)
+	}
+	config.RequestTimeout.Default = parseDuration("REQUEST_TIMEOUT", (time.Duration(timeoutMs) * time.Millisecond).String())
+	if config.Server.WriteTimeout > 0 && config.RequestTimeout.Default > config.Server.WriteTimeout {
+		logrus.Warnf("请求超时 %s 大于 SERVER_WRITE_TIMEOUT %s，超时响应可能来不及返回", config.RequestTimeout.Default, config.Server.WriteTimeout)
+	}
+
+	// 回调地址默认指向本服务对外地址
+	if config.OIDC.CallbackURL == "" {
+		config.OIDC.CallbackURL = config.GetServiceURL() + "/api/v1/auth/oidc/callback"
+	}
+
+	// 启用 HTTPS 后服务注册和健康检查必须使用 https，否则 Consul 检查会持续失败
+	if config.IsTLSEnabled() && config.Service.Scheme != "https" {
+		logrus.Warnf("已启用 HTTPS，SERVICE_SCHEME=%s 已改为 https", config.Service.Scheme)
+		config.Service.Scheme = "https"
 	}
 
 	GlobalConfig = config
@@ -221,6 +696,21 @@ func parseDuration(key, defaultValue string) time.Duration {
 	return duration
 }
 
+// getInt 获取整数类型环境变量
+func getInt(key string, defaultValue int) int {
+	valueStr := getEnv(key, "")
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.Atoi(valueStr)
+	if err != nil {
+		logrus.Warnf("无法解析环境变量 %s 的整数值: %s, 使用默认值: %d", key, valueStr, defaultValue)
+		return defaultValue
+	}
+	return value
+}
+
 // getBool 获取布尔类型环境变量
 func getBool(key string, defaultValue bool) bool {
 	valueStr := getEnv(key, "")
@@ -239,6 +729,159 @@ func getBool(key string, defaultValue bool) bool {
 	}
 }
 
+// parseKeyValues 解析 "key1:value1,key2:value2" 格式的环境变量
+func parseKeyValues(key string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(getEnv(key, ""), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, ":")
+		if !ok || strings.TrimSpace(k) == "" || strings.TrimSpace(v) == "" {
+			logrus.Warnf("无法解析环境变量 %s 中的配置项: %s，已忽略", key, pair)
+			continue
+		}
+		result[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return result
+}
+
+// defaultScheme 配置了服务端证书时默认使用 https
+func defaultScheme() string {
+	if getEnv("TLS_CERT_FILE", "") != "" && getEnv("TLS_KEY_FILE", "") != "" {
+		return "https"
+	}
+	return "http"
+}
+
+// defaultClientAuth 配置了客户端 CA 时默认校验客户端提供的证书
+func defaultClientAuth() string {
+	if getEnv("TLS_CLIENT_CA_FILE", "") != "" {
+		return ClientAuthOptional
+	}
+	return ClientAuthNone
+}
+
+// parseList 解析逗号分隔的列表环境变量
+func parseList(key string) []string {
+	var result []string
+	for _, item := range strings.Split(getEnv(key, ""), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// parseRateLimit 解析 "次数/窗口" 格式的限流额度，例如 10/1m，0 表示不限制
+func parseRateLimit(key, defaultValue string) RateLimitSpec {
+	parse := func(value string) (RateLimitSpec, bool) {
+		limitStr, windowStr, ok := strings.Cut(value, "/")
+		if !ok {
+			return RateLimitSpec{}, false
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(limitStr))
+		if err != nil || limit < 0 {
+			return RateLimitSpec{}, false
+		}
+		window, err := time.ParseDuration(strings.TrimSpace(windowStr))
+		if err != nil || window <= 0 {
+			return RateLimitSpec{}, false
+		}
+		return RateLimitSpec{Limit: limit, Window: window}, true
+	}
+
+	valueStr := getEnv(key, defaultValue)
+	if valueStr == "0" {
+		return RateLimitSpec{}
+	}
+	if spec, ok := parse(valueStr); ok {
+		return spec
+	}
+	logrus.Warnf("无法解析环境变量 %s 的限流额度: %s, 使用默认值: %s", key, valueStr, defaultValue)
+	spec, _ := parse(defaultValue)
+	return spec
+}
+
+// parseRouteTimeouts 解析 "GET /api/v1/audit/logs=60s,/api/v1/auth/login=5s" 格式的路由超时，方法可省略
+func parseRouteTimeouts(key string) map[string]time.Duration {
+	result := make(map[string]time.Duration)
+	for _, item := range parseList(key) {
+		route, value, ok := strings.Cut(item, "=")
+		route = strings.Join(strings.Fields(route), " ")
+		timeout, err := time.ParseDuration(strings.TrimSpace(value))
+		if !ok || route == "" || err != nil || timeout < 0 {
+			logrus.Warnf("无法解析环境变量 %s 中的路由超时: %s，已忽略", key, item)
+			continue
+		}
+		result[route] = timeout
+	}
+	return result
+}
+
+// parseRouteBodyLimits 解析 "POST /api/v1/tenants=256KB,/api/v1/auth/login=16KB" 格式的路由请求体上限，方法可省略
+func parseRouteBodyLimits(key string) map[string]int64 {
+	result := make(map[string]int64)
+	for _, item := range parseList(key) {
+		route, value, ok := strings.Cut(item, "=")
+		route = strings.Join(strings.Fields(route), " ")
+		size, err := parseByteSize(value)
+		if !ok || route == "" || err != nil {
+			logrus.Warnf("无法解析环境变量 %s 中的请求体上限: %s，已忽略", key, item)
+			continue
+		}
+		result[route] = size
+	}
+	return result
+}
+
+// getByteSize 获取字节大小类型环境变量
+func getByteSize(key, defaultValue string) int64 {
+	valueStr := getEnv(key, defaultValue)
+	size, err := parseByteSize(valueStr)
+	if err != nil {
+		defaultSize, _ := parseByteSize(defaultValue)
+		logrus.Warnf("无法解析环境变量 %s 的大小: %s, 使用默认值: %s", key, valueStr, defaultValue)
+		return defaultSize
+	}
+	return size
+}
+
+// parseByteSize 解析 512、64KB、1MB 格式的字节大小，单位按 1024 进位，不区分大小写
+func parseByteSize(value string) (int64, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.size
+			break
+		}
+	}
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("无效的大小: %s", value)
+	}
+	return size * multiplier, nil
+}
+
+// parseKeyLists 解析 "key1:a|b,key2:c" 格式的环境变量，值以竖线分隔为列表
+func parseKeyLists(key string) map[string][]string {
+	result := make(map[string][]string)
+	for k, v := range parseKeyValues(key) {
+		for _, item := range strings.Split(v, "|") {
+			if item = strings.TrimSpace(item); item != "" {
+				result[k] = append(result[k], item)
+			}
+		}
+	}
+	return result
+}
+
 // getServiceAddress 获取服务地址，优先级：SERVICE_ADDRESS > hostname > localhost
 func getServiceAddress() string {
 	if addr := os.Getenv("SERVICE_ADDRESS"); addr != "" {
@@ -315,8 +958,26 @@ func (c *Config) GetHealthCheckURL() string {
 	return c.GetServiceURL() + "/health"
 }
 
+// GetAdminAddr 获取运维管理端口监听地址
+func (c *Config) GetAdminAddr() string {
+	return c.Admin.Host + ":" + strconv.Itoa(c.Admin.Port)
+}
+
+// GetAdminURL 获取运维管理端口的访问地址，优先使用健康检查专用地址
+func (c *Config) GetAdminURL() string {
+	address := c.Service.HealthCheckAddress
+	if address == "" {
+		address = c.Service.Address
+	}
+	return "http://" + address + ":" + strconv.Itoa(c.Admin.Port)
+}
+
 // GetConsulHealthCheckURL 获取 Consul 专用健康检查 URL（使用容器内部地址）
 func (c *Config) GetConsulHealthCheckURL() string {
+	// 启用运维管理端口时由管理端口提供健康检查
+	if c.IsAdminEnabled() {
+		return c.GetAdminURL() + "/health"
+	}
 	// 如果配置了专用的健康检查地址，使用该地址
 	if c.Service.HealthCheckAddress != "" {
 		return c.Service.Scheme + "://" + c.Service.HealthCheckAddress + ":" + strconv.Itoa(c.Service.Port) + "/health"
@@ -327,9 +988,41 @@ func (c *Config) GetConsulHealthCheckURL() string {
 
 // GetMetricsURL 获取 metrics URL
 func (c *Config) GetMetricsURL() string {
+	if c.IsAdminEnabled() {
+		return c.GetAdminURL() + "/metrics"
+	}
 	return c.GetServiceURL() + "/metrics"
 }
 
+// IsAdminEnabled 判断是否启用运维管理端口
+func (c *Config) IsAdminEnabled() bool {
+	return c.Admin.Enabled
+}
+
+// IsAdminLoopback 判断运维管理端口是否只监听本机地址
+func (c *Config) IsAdminLoopback() bool {
+	if c.Admin.Host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(c.Admin.Host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// IsDatabaseEnabled 判断是否配置了数据库
+func (c *Config) IsDatabaseEnabled() bool {
+	return c.Database.DSN != ""
+}
+
+// IsRedisEnabled 判断是否依赖 Redis（与 Consul 元数据中声明的依赖保持一致）
+func (c *Config) IsRedisEnabled() bool {
+	return c.Consul.Meta.DependRedis == "true"
+}
+
+// IsTLSEnabled 判断是否启用 HTTPS
+func (c *Config) IsTLSEnabled() bool {
+	return c.TLS.CertFile != "" && c.TLS.KeyFile != ""
+}
+
 // IsConsulEnabled 判断是否启用 Consul
 func (c *Config) IsConsulEnabled() bool {
 	return c.Consul.Enabled
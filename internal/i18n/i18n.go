@@ -0,0 +1,157 @@
+// i18n/i18n.go
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLanguage 默认语言，客户端未声明或声明的语言都不支持时使用
+const DefaultLanguage = "zh"
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// bundles 各语言的消息目录，键为消息键，值为消息模板
+var bundles = mustLoadBundles()
+
+// Params 消息模板参数，模板中以 {名称} 引用
+type Params map[string]any
+
+// Message 待本地化的消息
+type Message struct {
+	Key    string
+	Params Params
+}
+
+// NewMessage 创建待本地化的消息
+func NewMessage(key string, params ...Params) Message {
+	return Message{Key: key, Params: mergeParams(params)}
+}
+
+// Localize 按语言输出消息
+func (m Message) Localize(lang string) string {
+	return T(lang, m.Key, m.Params)
+}
+
+// T 按语言翻译消息键，该语言缺少时使用默认语言，都缺少时原样返回消息键
+func T(lang, key string, params ...Params) string {
+	if message, ok := Lookup(lang, key, params...); ok {
+		return message
+	}
+	return key
+}
+
+// Lookup 按语言查找消息键，该语言缺少时使用默认语言，都缺少时返回 false
+func Lookup(lang, key string, params ...Params) (string, bool) {
+	template, ok := bundles[lang][key]
+	if !ok {
+		template, ok = bundles[DefaultLanguage][key]
+	}
+	if !ok {
+		return "", false
+	}
+	return format(template, mergeParams(params)), true
+}
+
+// Negotiate 按 Accept-Language 请求头选择支持的语言，按 q 值从高到低匹配主语言标签（zh-CN 匹配 zh）
+func Negotiate(acceptLanguage string) string {
+	type candidate struct {
+		lang    string
+		quality float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, paramsStr, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+		quality := 1.0
+		if name, value, ok := strings.Cut(strings.TrimSpace(paramsStr), "="); ok && strings.TrimSpace(name) == "q" {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if quality <= 0 {
+			continue
+		}
+		base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		candidates = append(candidates, candidate{lang: base, quality: quality})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+
+	for _, c := range candidates {
+		if c.lang == "*" {
+			return DefaultLanguage
+		}
+		if _, supported := bundles[c.lang]; supported {
+			return c.lang
+		}
+	}
+	return DefaultLanguage
+}
+
+// format 将模板中的 {名称} 替换为参数值，未提供的参数保持原样
+func format(template string, params Params) string {
+	if len(params) == 0 || !strings.Contains(template, "{") {
+		return template
+	}
+	replacements := make([]string, 0, len(params)*2)
+	for name, value := range params {
+		replacements = append(replacements, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(replacements...).Replace(template)
+}
+
+// mergeParams 合并多组模板参数
+func mergeParams(params []Params) Params {
+	switch len(params) {
+	case 0:
+		return nil
+	case 1:
+		return params[0]
+	}
+	merged := make(Params)
+	for _, p := range params {
+		for name, value := range p {
+			merged[name] = value
+		}
+	}
+	return merged
+}
+
+// mustLoadBundles 载入内嵌的消息目录，文件名（不含扩展名）即语言标签
+// 目录随程序一起编译，格式错误属于开发错误，直接 panic
+func mustLoadBundles() map[string]map[string]string {
+	entries, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(fmt.Sprintf("读取消息目录失败: %v", err))
+	}
+
+	result := make(map[string]map[string]string, len(entries))
+	for _, entry := range entries {
+		data, err := localeFiles.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("读取消息目录 %s 失败: %v", entry.Name(), err))
+		}
+		bundle := make(map[string]string)
+		if err := json.Unmarshal(data, &bundle); err != nil {
+			panic(fmt.Sprintf("解析消息目录 %s 失败: %v", entry.Name(), err))
+		}
+		result[strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))] = bundle
+	}
+	if _, exists := result[DefaultLanguage]; !exists {
+		panic("缺少默认语言的消息目录: " + DefaultLanguage)
+	}
+	return result
+}
@@ -0,0 +1,98 @@
+// lifecycle/component.go
+package lifecycle
+
+import (
+	"context"
+	"sync"
+)
+
+// Component 受生命周期管理的组件
+// Start 在声明的依赖全部启动后调用，应在组件可以提供服务后返回，长期运行的任务放在后台执行；
+// Stop 在依赖它的组件全部停止后调用，应在 ctx 到期前释放资源
+type Component interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// Drainer 可选接口，实例开始关闭时立即调用，早于任何组件停止，用于尽快拒绝新工作
+type Drainer interface {
+	Drain()
+}
+
+// Hooks 由函数构成的组件，未设置的函数视为成功
+type Hooks struct {
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+// Start 调用 OnStart
+func (h Hooks) Start(ctx context.Context) error {
+	if h.OnStart == nil {
+		return nil
+	}
+	return h.OnStart(ctx)
+}
+
+// Stop 调用 OnStop
+func (h Hooks) Stop(ctx context.Context) error {
+	if h.OnStop == nil {
+		return nil
+	}
+	return h.OnStop(ctx)
+}
+
+// Closer 只需在关闭时释放的依赖，例如数据库连接池
+// ping 不为空时启动阶段用它确认依赖可用
+func Closer(ping func(ctx context.Context) error, close func() error) Component {
+	return Hooks{
+		OnStart: ping,
+		OnStop:  func(context.Context) error { return close() },
+	}
+}
+
+// worker 后台任务组件
+type worker struct {
+	run    func(ctx context.Context)
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Worker 将运行到 ctx 取消为止的后台任务包装为组件，停止时取消 ctx 并等待任务返回
+func Worker(run func(ctx context.Context)) Component {
+	return &worker{run: run}
+}
+
+// Start 在后台启动任务
+func (w *worker) Start(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	w.mu.Lock()
+	w.cancel, w.done = cancel, done
+	w.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		w.run(ctx)
+	}()
+	return nil
+}
+
+// Stop 取消任务并等待其返回
+func (w *worker) Stop(ctx context.Context) error {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	w.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,72 @@
+// lifecycle/http_server.go
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// httpServer 将 http.Server 作为组件管理
+type httpServer struct {
+	server           *http.Server
+	propagationDelay time.Duration
+}
+
+// HTTPServer 创建 HTTP 服务组件，server.TLSConfig 不为空时以 HTTPS 提供服务
+// 启动时同步监听端口，端口被占用等错误在启动阶段返回；
+// 停止时先等待 propagationDelay 让网关停止转发新请求，再等待进行中的请求完成，ctx 到期后强制断开剩余连接
+func HTTPServer(server *http.Server, propagationDelay time.Duration) Component {
+	return &httpServer{server: server, propagationDelay: propagationDelay}
+}
+
+// Start 监听端口并在后台处理请求
+func (s *httpServer) Start(context.Context) error {
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return fmt.Errorf("监听 %s 失败: %w", s.server.Addr, err)
+	}
+
+	go func() {
+		var err error
+		if s.server.TLSConfig != nil {
+			err = s.server.ServeTLS(listener, "", "")
+		} else {
+			err = s.server.Serve(listener)
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.Fatalf("服务器运行失败: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Drain 关闭长连接复用，使客户端后续请求重新建立连接到其他实例
+func (s *httpServer) Drain() {
+	s.server.SetKeepAlivesEnabled(false)
+}
+
+// Stop 等待网关感知实例下线后，停止接收新连接并等待进行中的请求完成
+func (s *httpServer) Stop(ctx context.Context) error {
+	if s.propagationDelay > 0 {
+		logrus.WithField("delay", s.propagationDelay.String()).Info("等待网关停止转发新请求")
+		select {
+		case <-time.After(s.propagationDelay):
+		case <-ctx.Done():
+		}
+	}
+
+	logrus.Info("正在等待进行中的请求完成")
+	drainStart := time.Now()
+	if err := s.server.Shutdown(ctx); err != nil {
+		s.server.Close()
+		return fmt.Errorf("等待请求完成超时，已强制断开剩余连接: %w", err)
+	}
+	logrus.WithField("elapsed", time.Since(drainStart).String()).Info("进行中的请求已全部完成")
+	return nil
+}
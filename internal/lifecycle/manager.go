@@ -0,0 +1,267 @@
+// lifecycle/manager.go
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// State 组件状态
+type State string
+
+const (
+	StatePending  State = "pending"  // 已注册，尚未启动
+	StateStarting State = "starting" // 启动中
+	StateRunning  State = "running"  // 运行中
+	StateStopping State = "stopping" // 停止中
+	StateStopped  State = "stopped"  // 已停止
+	StateFailed   State = "failed"   // 启动或停止失败
+)
+
+// ErrInvalidDependency 组件依赖声明错误：名称重复、依赖不存在或存在循环依赖
+var ErrInvalidDependency = errors.New("组件依赖声明错误")
+
+// Options 组件注册选项
+type Options struct {
+	DependsOn    []string      // 依赖的组件名称，依赖全部启动后才启动本组件，本组件停止后才停止依赖
+	StartTimeout time.Duration // 启动超时，0 表示使用管理器默认值
+	StopTimeout  time.Duration // 停止超时，0 表示使用管理器默认值
+}
+
+// ComponentStatus 组件状态，用于健康检查
+type ComponentStatus struct {
+	Name  string `json:"name"`
+	State State  `json:"state"`
+	Error string `json:"error,omitempty"`
+}
+
+// entry 已注册的组件
+type entry struct {
+	name      string
+	component Component
+	opts      Options
+	state     State
+	err       error
+}
+
+// Manager 组件生命周期管理器
+// 按依赖关系依次启动组件，关闭时按实际启动顺序的逆序停止；所有组件运行中且未开始关闭时实例才就绪
+type Manager struct {
+	mu           sync.RWMutex
+	entries      []*entry
+	started      []*entry
+	draining     bool
+	startTimeout time.Duration
+	stopTimeout  time.Duration
+}
+
+// NewManager 创建生命周期管理器，startTimeout 和 stopTimeout 为组件未单独指定时的默认超时
+func NewManager(startTimeout, stopTimeout time.Duration) *Manager {
+	return &Manager{startTimeout: startTimeout, stopTimeout: stopTimeout}
+}
+
+// Add 注册组件，依赖关系在 Start 时校验
+func (m *Manager) Add(name string, component Component, opts Options) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, &entry{name: name, component: component, opts: opts, state: StatePending})
+}
+
+// Start 按依赖顺序启动全部组件，任一组件启动失败时停止已启动的组件并返回错误
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.RLock()
+	order, err := m.resolveOrder()
+	m.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	for _, e := range order {
+		m.setState(e, StateStarting, nil)
+		begin := time.Now()
+		err := call(ctx, timeoutOr(e.opts.StartTimeout, m.startTimeout), e.component.Start)
+		if err != nil {
+			m.setState(e, StateFailed, err)
+			logrus.WithError(err).WithField("component", e.name).Error("组件启动失败，正在停止已启动的组件")
+			m.stopAll()
+			return fmt.Errorf("启动组件 %s 失败: %w", e.name, err)
+		}
+
+		m.mu.Lock()
+		e.state = StateRunning
+		m.started = append(m.started, e)
+		m.mu.Unlock()
+		logrus.WithFields(logrus.Fields{
+			"component": e.name,
+			"elapsed":   time.Since(begin).String(),
+		}).Info("组件已启动")
+	}
+	return nil
+}
+
+// Shutdown 开始关闭：实例立即变为未就绪并通知实现 Drainer 的组件，然后按启动顺序的逆序停止组件
+func (m *Manager) Shutdown() {
+	m.mu.Lock()
+	m.draining = true
+	started := append([]*entry(nil), m.started...)
+	m.mu.Unlock()
+	logrus.Info("实例已标记为未就绪，健康检查将返回不可用")
+
+	for _, e := range started {
+		if drainer, ok := e.component.(Drainer); ok {
+			drainer.Drain()
+		}
+	}
+	m.stopAll()
+}
+
+// Ready 实例是否可以接收流量
+func (m *Manager) Ready() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.draining {
+		return false
+	}
+	for _, e := range m.entries {
+		if e.state != StateRunning {
+			return false
+		}
+	}
+	return true
+}
+
+// Draining 实例是否已开始关闭
+func (m *Manager) Draining() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.draining
+}
+
+// Status 按注册顺序返回各组件状态
+func (m *Manager) Status() []ComponentStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]ComponentStatus, 0, len(m.entries))
+	for _, e := range m.entries {
+		status := ComponentStatus{Name: e.name, State: e.state}
+		if e.err != nil {
+			status.Error = e.err.Error()
+		}
+		result = append(result, status)
+	}
+	return result
+}
+
+// stopAll 按启动顺序的逆序停止已启动的组件，单个组件停止失败不影响其余组件
+func (m *Manager) stopAll() {
+	m.mu.Lock()
+	started := m.started
+	m.started = nil
+	m.mu.Unlock()
+
+	for i := len(started) - 1; i >= 0; i-- {
+		e := started[i]
+		m.setState(e, StateStopping, nil)
+		begin := time.Now()
+		err := call(context.Background(), timeoutOr(e.opts.StopTimeout, m.stopTimeout), e.component.Stop)
+		fields := logrus.Fields{"component": e.name, "elapsed": time.Since(begin).String()}
+		if err != nil {
+			m.setState(e, StateFailed, err)
+			logrus.WithError(err).WithFields(fields).Error("组件停止失败")
+			continue
+		}
+		m.setState(e, StateStopped, nil)
+		logrus.WithFields(fields).Info("组件已停止")
+	}
+}
+
+// resolveOrder 按依赖关系计算启动顺序，没有依赖关系的组件保持注册顺序
+func (m *Manager) resolveOrder() ([]*entry, error) {
+	byName := make(map[string]*entry, len(m.entries))
+	for _, e := range m.entries {
+		if _, exists := byName[e.name]; exists {
+			return nil, fmt.Errorf("%w: 组件 %s 重复注册", ErrInvalidDependency, e.name)
+		}
+		byName[e.name] = e
+	}
+	for _, e := range m.entries {
+		for _, dep := range e.opts.DependsOn {
+			if _, exists := byName[dep]; !exists {
+				return nil, fmt.Errorf("%w: 组件 %s 依赖的 %s 未注册", ErrInvalidDependency, e.name, dep)
+			}
+		}
+	}
+
+	order := make([]*entry, 0, len(m.entries))
+	placed := make(map[string]bool, len(m.entries))
+	for len(order) < len(m.entries) {
+		progressed := false
+		for _, e := range m.entries {
+			if placed[e.name] || !dependenciesPlaced(e, placed) {
+				continue
+			}
+			order = append(order, e)
+			placed[e.name] = true
+			progressed = true
+		}
+		if !progressed {
+			var blocked []string
+			for _, e := range m.entries {
+				if !placed[e.name] {
+					blocked = append(blocked, e.name)
+				}
+			}
+			return nil, fmt.Errorf("%w: 组件之间存在循环依赖 %v", ErrInvalidDependency, blocked)
+		}
+	}
+	return order, nil
+}
+
+// setState 更新组件状态
+func (m *Manager) setState(e *entry, state State, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e.state = state
+	e.err = err
+}
+
+// dependenciesPlaced 组件的依赖是否都已排入启动顺序
+func dependenciesPlaced(e *entry, placed map[string]bool) bool {
+	for _, dep := range e.opts.DependsOn {
+		if !placed[dep] {
+			return false
+		}
+	}
+	return true
+}
+
+// call 在超时限制内调用组件方法，组件忽略 ctx 时也能按时返回
+func call(parent context.Context, timeout time.Duration, fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- fn(ctx) }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("超过 %s 未完成: %w", timeout, ctx.Err())
+	}
+}
+
+// timeoutOr 组件未指定超时时使用默认值
+func timeoutOr(timeout, fallback time.Duration) time.Duration {
+	if timeout > 0 {
+		return timeout
+	}
+	return fallback
+}
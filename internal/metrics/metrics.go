@@ -0,0 +1,118 @@
+// metrics/metrics.go
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"gin_saas_auth/internal/config"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	// Registry 服务专用的指标注册表
+	Registry *prometheus.Registry
+
+	// HTTPRequestsTotal HTTP 请求总数
+	HTTPRequestsTotal *prometheus.CounterVec
+	// HTTPRequestDuration HTTP 请求耗时
+	HTTPRequestDuration *prometheus.HistogramVec
+	// RateLimitedTotal 被限流拒绝的请求数
+	RateLimitedTotal *prometheus.CounterVec
+	// LoadShedTotal 过载保护拒绝的请求数
+	LoadShedTotal *prometheus.CounterVec
+	// ConcurrencyLimit 当前自适应并发上限
+	ConcurrencyLimit prometheus.Gauge
+	// InFlightRequests 正在处理的请求数
+	InFlightRequests prometheus.Gauge
+	// RequestTimeoutsTotal 处理超时返回 504 的请求数
+	RequestTimeoutsTotal *prometheus.CounterVec
+)
+
+// startTime 服务启动时间
+var startTime = time.Now()
+
+// Init 初始化指标，指标名前缀使用 Consul Meta 中的 metrics_namespace
+func Init(cfg *config.Config) {
+	namespace := cfg.Consul.Meta.MetricsNamespace
+
+	Registry = prometheus.NewRegistry()
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	serviceInfo := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "info",
+		Help:      "认证服务信息",
+	}, []string{"name", "version", "framework", "language", "environment"})
+	serviceInfo.WithLabelValues(
+		cfg.App.Name,
+		cfg.Consul.Meta.Version,
+		cfg.Consul.Meta.Framework,
+		cfg.Consul.Meta.Language,
+		cfg.App.Env,
+	).Set(1)
+
+	uptime := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "service_uptime_seconds",
+		Help: "服务运行时间（秒）",
+	}, func() float64 {
+		return time.Since(startTime).Seconds()
+	})
+
+	HTTPRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP 请求总数",
+	}, []string{"method", "route", "status", "tenant"})
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP 请求耗时（秒）",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "tenant"})
+
+	RateLimitedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_total",
+		Help:      "被限流拒绝的请求数",
+	}, []string{"rule", "tenant"})
+
+	LoadShedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "load_shed_total",
+		Help:      "过载保护拒绝的请求数",
+	}, []string{"reason"})
+
+	ConcurrencyLimit = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "concurrency_limit",
+		Help:      "当前自适应并发上限",
+	})
+
+	InFlightRequests = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "in_flight_requests",
+		Help:      "正在处理的请求数",
+	})
+
+	RequestTimeoutsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "request_timeouts_total",
+		Help:      "处理超时返回 504 的请求数",
+	}, []string{"method", "route"})
+
+	Registry.MustRegister(serviceInfo, uptime, HTTPRequestsTotal, HTTPRequestDuration, RateLimitedTotal,
+		LoadShedTotal, ConcurrencyLimit, InFlightRequests, RequestTimeoutsTotal)
+}
+
+// Handler Prometheus 指标采集接口
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,62 @@
+// models/audit.go
+package models
+
+import "time"
+
+// 审计事件类型
+const (
+	AuditLoginSucceeded = "auth.login.succeeded"
+	AuditLoginFailed    = "auth.login.failed"
+	AuditLoginBlocked   = "auth.login.blocked"
+	AuditAccountLocked  = "auth.account.locked"
+	AuditIPLocked       = "auth.ip.locked"
+
+	AuditTokenIssued        = "auth.token.issued"
+	AuditTokenRevoked       = "auth.token.revoked"
+	AuditTokenReuseDetected = "auth.token.reuse_detected"
+	AuditPasswordChanged    = "auth.password.changed"
+	AuditPasswordReset      = "auth.password.reset"
+	AuditMFAEnabled         = "auth.mfa.enabled"
+	AuditMFADisabled        = "auth.mfa.disabled"
+	AuditRecoveryCodesReset = "auth.mfa.recovery_codes_regenerated"
+	AuditVerificationSent   = "auth.verification.sent"
+	AuditEmailVerified      = "auth.email.verified"
+	AuditPhoneVerified      = "auth.phone.verified"
+	AuditIdentityLinked     = "auth.identity.linked"
+	AuditIdentityUnlinked   = "auth.identity.unlinked"
+
+	AuditAPIKeyCreated = "apikey.created"
+	AuditAPIKeyRevoked = "apikey.revoked"
+
+	AuditTenantCreated       = "tenant.created"
+	AuditTenantStatusChanged = "tenant.status_changed"
+	AuditPasswordPolicySet   = "tenant.password_policy.updated"
+	AuditPasswordPolicyReset = "tenant.password_policy.reset"
+
+	AuditProviderCreated = "idp.created"
+	AuditProviderUpdated = "idp.updated"
+	AuditProviderDeleted = "idp.deleted"
+
+	AuditPolicyReloaded     = "config.rbac_policy.reloaded"
+	AuditPolicyReloadFailed = "config.rbac_policy.reload_failed"
+
+	AuditConsulRegistered   = "service.consul.registered"
+	AuditConsulDeregistered = "service.consul.deregistered"
+)
+
+// AuditEvent 安全审计事件
+// 事件按序号组成哈希链：Hash 覆盖事件内容和上一事件的 Hash，任何修改或删除都会导致后续校验失败
+type AuditEvent struct {
+	Seq        int64             `json:"seq"`
+	ID         string            `json:"id"`
+	Type       string            `json:"type"`
+	TenantID   string            `json:"tenant_id,omitempty"`
+	ActorID    string            `json:"actor_id,omitempty"` // 执行操作的用户 ID，未认证或系统操作时为空
+	Subject    string            `json:"subject,omitempty"`  // 操作对象，例如登录使用的邮箱、会话 ID
+	ClientIP   string            `json:"client_ip,omitempty"`
+	UserAgent  string            `json:"user_agent,omitempty"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	OccurredAt time.Time         `json:"occurred_at"`
+	PrevHash   string            `json:"prev_hash"`
+	Hash       string            `json:"hash"`
+}
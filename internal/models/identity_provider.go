@@ -0,0 +1,42 @@
+// models/identity_provider.go
+package models
+
+import "time"
+
+// IdentityProvider 租户配置的上游 OIDC 身份提供方
+// 客户端密钥加密保存，只能写入不能读出
+type IdentityProvider struct {
+	ID                    string    `json:"id"`
+	TenantID              string    `json:"tenant_id"`
+	Name                  string    `json:"name"` // 租户内唯一的标识，用于登录地址
+	DisplayName           string    `json:"display_name"`
+	Issuer                string    `json:"issuer"`
+	ClientID              string    `json:"client_id"`
+	ClientSecretEncrypted string    `json:"-"`
+	Scopes                []string  `json:"scopes"`
+	EmailClaim            string    `json:"email_claim"`          // 读取邮箱的声明，默认 email
+	EmailVerifiedClaim    string    `json:"email_verified_claim"` // 读取邮箱验证状态的声明，默认 email_verified
+	TrustEmail            bool      `json:"trust_email"`          // 提供方不返回验证状态时，视其邮箱为已验证
+	AutoLink              bool      `json:"auto_link"`            // 按已验证邮箱自动关联已有的本地用户
+	AllowSignup           bool      `json:"allow_signup"`         // 首次登录时自动创建本地用户
+	Enabled               bool      `json:"enabled"`
+	CreatedAt             time.Time `json:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at"`
+}
+
+// HasClientSecret 是否配置了客户端密钥，未配置时作为公开客户端只使用 PKCE
+func (p *IdentityProvider) HasClientSecret() bool {
+	return p.ClientSecretEncrypted != ""
+}
+
+// FederatedIdentity 本地用户与外部身份（提供方 + subject）的关联
+type FederatedIdentity struct {
+	ID          string     `json:"id"`
+	TenantID    string     `json:"tenant_id"`
+	ProviderID  string     `json:"provider_id"`
+	Subject     string     `json:"subject"`
+	UserID      string     `json:"user_id"`
+	Email       string     `json:"email,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+}
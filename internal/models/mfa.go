@@ -0,0 +1,37 @@
+// models/mfa.go
+package models
+
+import "time"
+
+// 认证方式引用值（RFC 8176），写入访问令牌的 amr 声明
+const (
+	// AMRPassword 密码认证
+	AMRPassword = "pwd"
+	// AMROTP 一次性动态码认证
+	AMROTP = "otp"
+	// AMRSMS 短信验证码认证
+	AMRSMS = "sms"
+	// AMRFederated 外部身份提供方认证
+	AMRFederated = "fed"
+	// AMRMFA 已完成多因素认证
+	AMRMFA = "mfa"
+)
+
+// TOTPEnrollment 用户的 TOTP 绑定信息
+type TOTPEnrollment struct {
+	UserID          string     `json:"user_id"`
+	SecretEncrypted string     `json:"-"`
+	Confirmed       bool       `json:"confirmed"`
+	ConfirmedAt     *time.Time `json:"confirmed_at,omitempty"`
+	// LastUsedStep 最近一次校验成功的时间步，同一时间步的动态码不可重复使用
+	LastUsedStep int64     `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// RecoveryCode 恢复码（仅保存摘要，每个只能使用一次）
+type RecoveryCode struct {
+	UserID    string     `json:"user_id"`
+	CodeHash  string     `json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,30 @@
+// models/password_policy.go
+package models
+
+import "time"
+
+// PasswordPolicy 租户密码策略，租户未配置时使用全局默认策略
+type PasswordPolicy struct {
+	TenantID      string    `json:"tenant_id"`
+	MinLength     int       `json:"min_length"`
+	MaxLength     int       `json:"max_length"`
+	RequireUpper  bool      `json:"require_upper"`
+	RequireLower  bool      `json:"require_lower"`
+	RequireDigit  bool      `json:"require_digit"`
+	RequireSymbol bool      `json:"require_symbol"`
+	CheckBreached bool      `json:"check_breached"`
+	HistorySize   int       `json:"history_size"` // 不能与最近 N 次使用过的密码相同（包括当前密码）
+	MaxAgeDays    int       `json:"max_age_days"` // 0 表示不过期
+	Custom        bool      `json:"custom"`       // 是否为租户自定义策略
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// MaxAge 密码最长使用时间，0 表示不过期
+func (p *PasswordPolicy) MaxAge() time.Duration {
+	return time.Duration(p.MaxAgeDays) * 24 * time.Hour
+}
+
+// PasswordExpired 判断在 changedAt 设置的密码是否已过期
+func (p *PasswordPolicy) PasswordExpired(changedAt, now time.Time) bool {
+	return p.MaxAgeDays > 0 && now.Sub(changedAt) >= p.MaxAge()
+}
@@ -0,0 +1,28 @@
+// models/rbac.go
+package models
+
+// Policy RBAC 策略文件结构
+type Policy struct {
+	// Roles 角色定义，key 为角色名
+	Roles map[string]Role `json:"roles"`
+	// Bindings 角色绑定
+	Bindings []RoleBinding `json:"bindings"`
+}
+
+// Role 角色定义
+type Role struct {
+	Description string `json:"description,omitempty"`
+	// Permissions 权限列表，格式为 资源:操作，支持 users:* 与 * 通配
+	Permissions []string `json:"permissions"`
+	// Inherits 继承的其他角色
+	Inherits []string `json:"inherits,omitempty"`
+}
+
+// RoleBinding 将角色授予某个主体
+type RoleBinding struct {
+	// Subject 用户 ID，* 表示所有已登录用户
+	Subject string `json:"subject"`
+	Role    string `json:"role"`
+	// Tenant 生效的租户 ID，* 表示所有租户，不能为空
+	Tenant string `json:"tenant"`
+}
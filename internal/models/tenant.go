@@ -0,0 +1,29 @@
+// models/tenant.go
+package models
+
+import "time"
+
+// TenantStatus 租户状态
+type TenantStatus string
+
+const (
+	// TenantStatusActive 正常
+	TenantStatusActive TenantStatus = "active"
+	// TenantStatusSuspended 已停用，所有请求都会被拒绝
+	TenantStatusSuspended TenantStatus = "suspended"
+)
+
+// Tenant 租户
+type Tenant struct {
+	// ID 租户标识，同时用作子域名，仅允许小写字母、数字和连字符
+	ID        string       `json:"id"`
+	Name      string       `json:"name"`
+	Status    TenantStatus `json:"status"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// IsActive 判断租户是否可用
+func (t *Tenant) IsActive() bool {
+	return t.Status == TenantStatusActive
+}
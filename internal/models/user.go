@@ -0,0 +1,48 @@
+// models/user.go
+package models
+
+import "time"
+
+// UserStatus 用户状态
+type UserStatus string
+
+const (
+	// UserStatusActive 正常
+	UserStatusActive UserStatus = "active"
+	// UserStatusDisabled 已禁用
+	UserStatusDisabled UserStatus = "disabled"
+)
+
+// User 用户账户
+type User struct {
+	ID                string     `json:"id"`
+	TenantID          string     `json:"tenant_id"`
+	Email             string     `json:"email"`
+	EmailVerifiedAt   *time.Time `json:"email_verified_at,omitempty"`
+	Phone             string     `json:"phone,omitempty"` // E.164 格式，只保存验证通过的手机号
+	PhoneVerifiedAt   *time.Time `json:"phone_verified_at,omitempty"`
+	PasswordHash      string     `json:"-"`
+	Status            UserStatus `json:"status"`
+	PasswordChangedAt time.Time  `json:"password_changed_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// IsActive 判断用户是否可登录
+func (u *User) IsActive() bool {
+	return u.Status == UserStatusActive
+}
+
+// IsEmailVerified 判断邮箱是否已验证
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// PasswordResetToken 密码重置令牌（仅保存摘要）
+type PasswordResetToken struct {
+	TokenHash string     `json:"-"`
+	UserID    string     `json:"user_id"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
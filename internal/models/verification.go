@@ -0,0 +1,36 @@
+// models/verification.go
+package models
+
+import "time"
+
+// 验证码发送渠道
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+)
+
+// 验证码用途，不同用途的验证码互不通用
+const (
+	// PurposeVerify 验证邮箱或手机号归属（注册验证、绑定手机号）
+	PurposeVerify = "verify"
+	// PurposePasswordReset 重置密码
+	PurposePasswordReset = "password_reset"
+	// PurposeLogin 验证码登录
+	PurposeLogin = "login"
+)
+
+// VerificationCode 发送到邮箱或手机的一次性验证码（仅保存摘要）
+// 同一租户、渠道、目标和用途只有最近一次发送的验证码有效
+type VerificationCode struct {
+	ID          string     `json:"id"`
+	TenantID    string     `json:"tenant_id"`
+	UserID      string     `json:"user_id"`
+	Channel     string     `json:"channel"`
+	Destination string     `json:"destination"`
+	Purpose     string     `json:"purpose"`
+	CodeHash    string     `json:"-"`
+	Attempts    int        `json:"attempts"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	ConsumedAt  *time.Time `json:"consumed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
@@ -0,0 +1,25 @@
+// repository/api_key_repository.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gin_saas_auth/internal/models"
+)
+
+// APIKeyRepository API 密钥存储接口
+type APIKeyRepository interface {
+	// CreateAPIKey 创建密钥，前缀重复时返回 ErrDuplicate
+	CreateAPIKey(ctx context.Context, key *models.APIKey) error
+	// GetAPIKey 根据 ID 查询密钥
+	GetAPIKey(ctx context.Context, id string) (*models.APIKey, error)
+	// GetAPIKeyByPrefix 根据前缀查询密钥
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+	// ListAPIKeysByUser 查询用户的所有密钥（包括已撤销的），按创建时间倒序
+	ListAPIKeysByUser(ctx context.Context, userID string) ([]*models.APIKey, error)
+	// TouchAPIKey 更新密钥最近使用时间
+	TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error
+	// RevokeAPIKey 撤销密钥，密钥不存在或已撤销时返回 ErrNotFound
+	RevokeAPIKey(ctx context.Context, id string, revokedAt time.Time) error
+}
@@ -0,0 +1,22 @@
+// repository/attempt_store.go
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// AttemptStore 登录失败计数与锁定状态存储接口
+// 多实例部署时需要使用共享存储（Redis），否则各实例分别计数
+type AttemptStore interface {
+	// IncrementFailures 失败次数加一并返回累计次数，计数在最后一次失败 window 之后过期
+	IncrementFailures(ctx context.Context, key string, window time.Duration) (int, error)
+	// GetFailures 查询当前失败次数
+	GetFailures(ctx context.Context, key string) (int, error)
+	// Lock 锁定指定时长，已有更长的锁定时保留原锁定
+	Lock(ctx context.Context, key string, duration time.Duration) error
+	// LockRemaining 查询剩余锁定时间，未锁定时返回 0
+	LockRemaining(ctx context.Context, key string) (time.Duration, error)
+	// Reset 清除失败次数和锁定状态
+	Reset(ctx context.Context, key string) error
+}
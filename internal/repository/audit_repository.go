@@ -0,0 +1,28 @@
+// repository/audit_repository.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gin_saas_auth/internal/models"
+)
+
+// AuditFilter 审计事件查询条件，零值字段表示不过滤
+type AuditFilter struct {
+	TenantID string
+	ActorID  string
+	Type     string
+	From     time.Time
+	To       time.Time
+	AfterSeq int64 // 游标分页，只返回序号大于该值的事件
+	Limit    int
+}
+
+// AuditRepository 审计事件存储接口，只允许追加
+type AuditRepository interface {
+	// AppendAuditEvent 原子地读取链尾事件（无事件时为 nil），由 seal 填充序号和哈希后写入
+	AppendAuditEvent(ctx context.Context, event *models.AuditEvent, seal func(prev *models.AuditEvent)) error
+	// ListAuditEvents 按序号升序查询审计事件
+	ListAuditEvents(ctx context.Context, filter AuditFilter) ([]*models.AuditEvent, error)
+}
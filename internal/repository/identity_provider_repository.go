@@ -0,0 +1,34 @@
+// repository/identity_provider_repository.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gin_saas_auth/internal/models"
+)
+
+// IdentityProviderRepository 外部身份提供方及身份关联存储接口
+type IdentityProviderRepository interface {
+	// CreateProvider 创建提供方，租户内名称重复时返回 ErrDuplicate
+	CreateProvider(ctx context.Context, provider *models.IdentityProvider) error
+	// GetProvider 根据租户和名称查询提供方
+	GetProvider(ctx context.Context, tenantID, name string) (*models.IdentityProvider, error)
+	// ListProviders 查询租户的所有提供方，按名称排序
+	ListProviders(ctx context.Context, tenantID string) ([]*models.IdentityProvider, error)
+	// UpdateProvider 更新提供方配置
+	UpdateProvider(ctx context.Context, provider *models.IdentityProvider) error
+	// DeleteProvider 删除提供方及其所有身份关联
+	DeleteProvider(ctx context.Context, id string) error
+
+	// CreateIdentity 创建身份关联，同一提供方的 subject 已关联时返回 ErrDuplicate
+	CreateIdentity(ctx context.Context, identity *models.FederatedIdentity) error
+	// GetIdentity 根据提供方和外部 subject 查询身份关联
+	GetIdentity(ctx context.Context, providerID, subject string) (*models.FederatedIdentity, error)
+	// ListIdentitiesByUser 查询用户的所有身份关联，按创建时间排序
+	ListIdentitiesByUser(ctx context.Context, userID string) ([]*models.FederatedIdentity, error)
+	// TouchIdentity 更新最近登录时间
+	TouchIdentity(ctx context.Context, id string, loginAt time.Time) error
+	// DeleteIdentity 删除用户的身份关联，不存在或不属于该用户时返回 ErrNotFound
+	DeleteIdentity(ctx context.Context, userID, id string) error
+}
@@ -0,0 +1,118 @@
+// repository/memory_api_key_repository.go
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"gin_saas_auth/internal/models"
+)
+
+// MemoryAPIKeyRepository 基于内存的 API 密钥存储，适用于本地开发和测试
+type MemoryAPIKeyRepository struct {
+	mu       sync.RWMutex
+	keys     map[string]*models.APIKey
+	byPrefix map[string]string // key: prefix, value: key id
+}
+
+// NewMemoryAPIKeyRepository 创建内存 API 密钥存储
+func NewMemoryAPIKeyRepository() *MemoryAPIKeyRepository {
+	return &MemoryAPIKeyRepository{
+		keys:     make(map[string]*models.APIKey),
+		byPrefix: make(map[string]string),
+	}
+}
+
+// CreateAPIKey 创建密钥
+func (r *MemoryAPIKeyRepository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.byPrefix[key.Prefix]; exists {
+		return ErrDuplicate
+	}
+	if _, exists := r.keys[key.ID]; exists {
+		return ErrDuplicate
+	}
+	r.keys[key.ID] = copyAPIKey(key)
+	r.byPrefix[key.Prefix] = key.ID
+	return nil
+}
+
+// GetAPIKey 根据 ID 查询密钥
+func (r *MemoryAPIKeyRepository) GetAPIKey(ctx context.Context, id string) (*models.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, exists := r.keys[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return copyAPIKey(key), nil
+}
+
+// GetAPIKeyByPrefix 根据前缀查询密钥
+func (r *MemoryAPIKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, exists := r.byPrefix[prefix]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return copyAPIKey(r.keys[id]), nil
+}
+
+// ListAPIKeysByUser 查询用户的所有密钥
+func (r *MemoryAPIKeyRepository) ListAPIKeysByUser(ctx context.Context, userID string) ([]*models.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*models.APIKey
+	for _, key := range r.keys {
+		if key.UserID == userID {
+			result = append(result, copyAPIKey(key))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+// TouchAPIKey 更新密钥最近使用时间
+func (r *MemoryAPIKeyRepository) TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, exists := r.keys[id]
+	if !exists {
+		return ErrNotFound
+	}
+	t := usedAt
+	key.LastUsedAt = &t
+	return nil
+}
+
+// RevokeAPIKey 撤销密钥
+func (r *MemoryAPIKeyRepository) RevokeAPIKey(ctx context.Context, id string, revokedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, exists := r.keys[id]
+	if !exists || key.RevokedAt != nil {
+		return ErrNotFound
+	}
+	t := revokedAt
+	key.RevokedAt = &t
+	return nil
+}
+
+// copyAPIKey 深拷贝密钥，避免调用方修改存储中的切片
+func copyAPIKey(key *models.APIKey) *models.APIKey {
+	copied := *key
+	copied.Scopes = append([]string(nil), key.Scopes...)
+	return &copied
+}
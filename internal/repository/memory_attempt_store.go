@@ -0,0 +1,117 @@
+// repository/memory_attempt_store.go
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// attemptEntry 单个键的失败计数与锁定状态
+type attemptEntry struct {
+	failures    int
+	expiresAt   time.Time
+	lockedUntil time.Time
+}
+
+// MemoryAttemptStore 基于内存的失败计数存储，适用于单实例部署和测试
+type MemoryAttemptStore struct {
+	mu        sync.Mutex
+	entries   map[string]*attemptEntry
+	lastSweep time.Time
+}
+
+// NewMemoryAttemptStore 创建内存失败计数存储
+func NewMemoryAttemptStore() *MemoryAttemptStore {
+	return &MemoryAttemptStore{
+		entries: make(map[string]*attemptEntry),
+	}
+}
+
+// IncrementFailures 失败次数加一
+func (s *MemoryAttemptStore) IncrementFailures(ctx context.Context, key string, window time.Duration) (int, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweepLocked(now)
+	entry := s.entryLocked(key)
+	if now.After(entry.expiresAt) {
+		entry.failures = 0
+	}
+	entry.failures++
+	entry.expiresAt = now.Add(window)
+	return entry.failures, nil
+}
+
+// GetFailures 查询当前失败次数
+func (s *MemoryAttemptStore) GetFailures(ctx context.Context, key string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, exists := s.entries[key]
+	if !exists || time.Now().After(entry.expiresAt) {
+		return 0, nil
+	}
+	return entry.failures, nil
+}
+
+// Lock 锁定指定时长
+func (s *MemoryAttemptStore) Lock(ctx context.Context, key string, duration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.entryLocked(key)
+	until := time.Now().Add(duration)
+	if until.After(entry.lockedUntil) {
+		entry.lockedUntil = until
+	}
+	return nil
+}
+
+// LockRemaining 查询剩余锁定时间
+func (s *MemoryAttemptStore) LockRemaining(ctx context.Context, key string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, exists := s.entries[key]
+	if !exists {
+		return 0, nil
+	}
+	if remaining := time.Until(entry.lockedUntil); remaining > 0 {
+		return remaining, nil
+	}
+	return 0, nil
+}
+
+// Reset 清除失败次数和锁定状态
+func (s *MemoryAttemptStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// entryLocked 获取或创建键对应的状态，调用方需持有锁
+func (s *MemoryAttemptStore) entryLocked(key string) *attemptEntry {
+	entry, exists := s.entries[key]
+	if !exists {
+		entry = &attemptEntry{}
+		s.entries[key] = entry
+	}
+	return entry
+}
+
+// sweepLocked 每分钟清理一次计数和锁定均已过期的键，调用方需持有锁
+func (s *MemoryAttemptStore) sweepLocked(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	for key, entry := range s.entries {
+		if now.After(entry.expiresAt) && now.After(entry.lockedUntil) {
+			delete(s.entries, key)
+		}
+	}
+	s.lastSweep = now
+}
@@ -0,0 +1,82 @@
+// repository/memory_audit_repository.go
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"gin_saas_auth/internal/models"
+)
+
+// MemoryAuditRepository 基于内存的审计事件存储，适用于本地开发和测试
+type MemoryAuditRepository struct {
+	mu     sync.RWMutex
+	events []*models.AuditEvent
+}
+
+// NewMemoryAuditRepository 创建内存审计事件存储
+func NewMemoryAuditRepository() *MemoryAuditRepository {
+	return &MemoryAuditRepository{}
+}
+
+// AppendAuditEvent 追加审计事件
+func (r *MemoryAuditRepository) AppendAuditEvent(ctx context.Context, event *models.AuditEvent, seal func(prev *models.AuditEvent)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var prev *models.AuditEvent
+	if n := len(r.events); n > 0 {
+		prev = copyAuditEvent(r.events[n-1])
+	}
+	seal(prev)
+	r.events = append(r.events, copyAuditEvent(event))
+	return nil
+}
+
+// ListAuditEvents 按序号升序查询审计事件
+func (r *MemoryAuditRepository) ListAuditEvents(ctx context.Context, filter AuditFilter) ([]*models.AuditEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// 事件按序号追加，二分定位游标起点
+	start := sort.Search(len(r.events), func(i int) bool {
+		return r.events[i].Seq > filter.AfterSeq
+	})
+
+	result := []*models.AuditEvent{}
+	for _, event := range r.events[start:] {
+		if filter.Limit > 0 && len(result) >= filter.Limit {
+			break
+		}
+		if filter.TenantID != "" && event.TenantID != filter.TenantID {
+			continue
+		}
+		if filter.ActorID != "" && event.ActorID != filter.ActorID {
+			continue
+		}
+		if filter.Type != "" && event.Type != filter.Type {
+			continue
+		}
+		if !filter.From.IsZero() && event.OccurredAt.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !event.OccurredAt.Before(filter.To) {
+			continue
+		}
+		result = append(result, copyAuditEvent(event))
+	}
+	return result, nil
+}
+
+// copyAuditEvent 深拷贝审计事件
+func copyAuditEvent(event *models.AuditEvent) *models.AuditEvent {
+	copied := *event
+	if event.Metadata != nil {
+		copied.Metadata = make(map[string]string, len(event.Metadata))
+		for k, v := range event.Metadata {
+			copied.Metadata[k] = v
+		}
+	}
+	return &copied
+}
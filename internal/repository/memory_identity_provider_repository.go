@@ -0,0 +1,198 @@
+// repository/memory_identity_provider_repository.go
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"gin_saas_auth/internal/models"
+)
+
+// MemoryIdentityProviderRepository 基于内存的身份提供方存储，适用于本地开发和测试
+type MemoryIdentityProviderRepository struct {
+	mu         sync.RWMutex
+	providers  map[string]*models.IdentityProvider  // key: provider id
+	identities map[string]*models.FederatedIdentity // key: identity id
+}
+
+// NewMemoryIdentityProviderRepository 创建内存身份提供方存储
+func NewMemoryIdentityProviderRepository() *MemoryIdentityProviderRepository {
+	return &MemoryIdentityProviderRepository{
+		providers:  make(map[string]*models.IdentityProvider),
+		identities: make(map[string]*models.FederatedIdentity),
+	}
+}
+
+// CreateProvider 创建提供方
+func (r *MemoryIdentityProviderRepository) CreateProvider(ctx context.Context, provider *models.IdentityProvider) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.providers[provider.ID]; exists {
+		return ErrDuplicate
+	}
+	if r.findProvider(provider.TenantID, provider.Name) != nil {
+		return ErrDuplicate
+	}
+	r.providers[provider.ID] = copyIdentityProvider(provider)
+	return nil
+}
+
+// GetProvider 根据租户和名称查询提供方
+func (r *MemoryIdentityProviderRepository) GetProvider(ctx context.Context, tenantID, name string) (*models.IdentityProvider, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	provider := r.findProvider(tenantID, name)
+	if provider == nil {
+		return nil, ErrNotFound
+	}
+	return copyIdentityProvider(provider), nil
+}
+
+// ListProviders 查询租户的所有提供方
+func (r *MemoryIdentityProviderRepository) ListProviders(ctx context.Context, tenantID string) ([]*models.IdentityProvider, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*models.IdentityProvider
+	for _, provider := range r.providers {
+		if provider.TenantID == tenantID {
+			result = append(result, copyIdentityProvider(provider))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// UpdateProvider 更新提供方配置，名称不可修改
+func (r *MemoryIdentityProviderRepository) UpdateProvider(ctx context.Context, provider *models.IdentityProvider) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.providers[provider.ID]
+	if !exists {
+		return ErrNotFound
+	}
+	updated := copyIdentityProvider(provider)
+	updated.TenantID = existing.TenantID
+	updated.Name = existing.Name
+	updated.CreatedAt = existing.CreatedAt
+	r.providers[provider.ID] = updated
+	return nil
+}
+
+// DeleteProvider 删除提供方及其所有身份关联
+func (r *MemoryIdentityProviderRepository) DeleteProvider(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.providers[id]; !exists {
+		return ErrNotFound
+	}
+	delete(r.providers, id)
+	for identityID, identity := range r.identities {
+		if identity.ProviderID == id {
+			delete(r.identities, identityID)
+		}
+	}
+	return nil
+}
+
+// CreateIdentity 创建身份关联
+func (r *MemoryIdentityProviderRepository) CreateIdentity(ctx context.Context, identity *models.FederatedIdentity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.identities[identity.ID]; exists {
+		return ErrDuplicate
+	}
+	for _, existing := range r.identities {
+		if existing.ProviderID == identity.ProviderID && existing.Subject == identity.Subject {
+			return ErrDuplicate
+		}
+	}
+	copied := *identity
+	r.identities[identity.ID] = &copied
+	return nil
+}
+
+// GetIdentity 根据提供方和外部 subject 查询身份关联
+func (r *MemoryIdentityProviderRepository) GetIdentity(ctx context.Context, providerID, subject string) (*models.FederatedIdentity, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, identity := range r.identities {
+		if identity.ProviderID == providerID && identity.Subject == subject {
+			copied := *identity
+			return &copied, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// ListIdentitiesByUser 查询用户的所有身份关联
+func (r *MemoryIdentityProviderRepository) ListIdentitiesByUser(ctx context.Context, userID string) ([]*models.FederatedIdentity, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*models.FederatedIdentity
+	for _, identity := range r.identities {
+		if identity.UserID == userID {
+			copied := *identity
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+// TouchIdentity 更新最近登录时间
+func (r *MemoryIdentityProviderRepository) TouchIdentity(ctx context.Context, id string, loginAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	identity, exists := r.identities[id]
+	if !exists {
+		return ErrNotFound
+	}
+	t := loginAt
+	identity.LastLoginAt = &t
+	return nil
+}
+
+// DeleteIdentity 删除用户的身份关联
+func (r *MemoryIdentityProviderRepository) DeleteIdentity(ctx context.Context, userID, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	identity, exists := r.identities[id]
+	if !exists || identity.UserID != userID {
+		return ErrNotFound
+	}
+	delete(r.identities, id)
+	return nil
+}
+
+// findProvider 按租户和名称查找提供方，调用方须持有锁
+func (r *MemoryIdentityProviderRepository) findProvider(tenantID, name string) *models.IdentityProvider {
+	for _, provider := range r.providers {
+		if provider.TenantID == tenantID && provider.Name == name {
+			return provider
+		}
+	}
+	return nil
+}
+
+// copyIdentityProvider 深拷贝提供方，避免调用方修改存储中的切片
+func copyIdentityProvider(provider *models.IdentityProvider) *models.IdentityProvider {
+	copied := *provider
+	copied.Scopes = append([]string(nil), provider.Scopes...)
+	return &copied
+}
@@ -0,0 +1,133 @@
+// repository/memory_mfa_repository.go
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gin_saas_auth/internal/models"
+)
+
+// MemoryMFARepository 基于内存的多因素认证存储，适用于本地开发和测试
+type MemoryMFARepository struct {
+	mu            sync.RWMutex
+	enrollments   map[string]*models.TOTPEnrollment
+	recoveryCodes map[string][]*models.RecoveryCode
+}
+
+// NewMemoryMFARepository 创建内存多因素认证存储
+func NewMemoryMFARepository() *MemoryMFARepository {
+	return &MemoryMFARepository{
+		enrollments:   make(map[string]*models.TOTPEnrollment),
+		recoveryCodes: make(map[string][]*models.RecoveryCode),
+	}
+}
+
+// GetTOTP 查询用户的 TOTP 绑定
+func (r *MemoryMFARepository) GetTOTP(ctx context.Context, userID string) (*models.TOTPEnrollment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	enrollment, exists := r.enrollments[userID]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	copied := *enrollment
+	return &copied, nil
+}
+
+// SaveTOTP 保存或覆盖用户的 TOTP 绑定
+func (r *MemoryMFARepository) SaveTOTP(ctx context.Context, enrollment *models.TOTPEnrollment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copied := *enrollment
+	r.enrollments[enrollment.UserID] = &copied
+	return nil
+}
+
+// ConfirmTOTP 将 TOTP 绑定标记为已确认
+func (r *MemoryMFARepository) ConfirmTOTP(ctx context.Context, userID string, step int64, confirmedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	enrollment, exists := r.enrollments[userID]
+	if !exists {
+		return ErrNotFound
+	}
+	at := confirmedAt
+	enrollment.Confirmed = true
+	enrollment.ConfirmedAt = &at
+	enrollment.LastUsedStep = step
+	return nil
+}
+
+// AdvanceTOTPStep 更新最近使用的时间步
+func (r *MemoryMFARepository) AdvanceTOTPStep(ctx context.Context, userID string, step int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	enrollment, exists := r.enrollments[userID]
+	if !exists || step <= enrollment.LastUsedStep {
+		return ErrNotFound
+	}
+	enrollment.LastUsedStep = step
+	return nil
+}
+
+// DeleteTOTP 删除用户的 TOTP 绑定及全部恢复码
+func (r *MemoryMFARepository) DeleteTOTP(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.enrollments[userID]; !exists {
+		return ErrNotFound
+	}
+	delete(r.enrollments, userID)
+	delete(r.recoveryCodes, userID)
+	return nil
+}
+
+// ReplaceRecoveryCodes 替换用户的全部恢复码
+func (r *MemoryMFARepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codes []*models.RecoveryCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copied := make([]*models.RecoveryCode, 0, len(codes))
+	for _, code := range codes {
+		c := *code
+		copied = append(copied, &c)
+	}
+	r.recoveryCodes[userID] = copied
+	return nil
+}
+
+// ConsumeRecoveryCode 使用一个恢复码
+func (r *MemoryMFARepository) ConsumeRecoveryCode(ctx context.Context, userID, codeHash string, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, code := range r.recoveryCodes[userID] {
+		if code.CodeHash == codeHash && code.UsedAt == nil {
+			at := usedAt
+			code.UsedAt = &at
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// CountRecoveryCodes 统计用户未使用的恢复码数量
+func (r *MemoryMFARepository) CountRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, code := range r.recoveryCodes[userID] {
+		if code.UsedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
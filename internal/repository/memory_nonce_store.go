@@ -0,0 +1,49 @@
+// repository/memory_nonce_store.go
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryNonceStore 基于内存的随机数存储，只能识别重放到同一实例的请求，适用于单实例部署和测试
+type MemoryNonceStore struct {
+	mu        sync.Mutex
+	nonces    map[string]time.Time // value: 过期时间
+	lastSweep time.Time
+}
+
+// NewMemoryNonceStore 创建内存随机数存储
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{
+		nonces: make(map[string]time.Time),
+	}
+}
+
+// Remember 记录随机数
+func (s *MemoryNonceStore) Remember(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweepLocked(now)
+	if expiresAt, exists := s.nonces[key]; exists && now.Before(expiresAt) {
+		return false, nil
+	}
+	s.nonces[key] = now.Add(ttl)
+	return true, nil
+}
+
+// sweepLocked 每分钟清理一次已过期的随机数，调用方需持有锁
+func (s *MemoryNonceStore) sweepLocked(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	for key, expiresAt := range s.nonces {
+		if now.After(expiresAt) {
+			delete(s.nonces, key)
+		}
+	}
+	s.lastSweep = now
+}
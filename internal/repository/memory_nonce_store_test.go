@@ -0,0 +1,39 @@
+// repository/memory_nonce_store_test.go
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryNonceStoreRemember(t *testing.T) {
+	store := NewMemoryNonceStore()
+	ctx := context.Background()
+
+	for i, want := range []bool{true, false} {
+		fresh, err := store.Remember(ctx, "svc:nonce-1", time.Minute)
+		if err != nil {
+			t.Fatalf("记录随机数失败: %v", err)
+		}
+		if fresh != want {
+			t.Fatalf("第 %d 次记录 fresh = %v, want %v", i+1, fresh, want)
+		}
+	}
+	if fresh, _ := store.Remember(ctx, "svc:nonce-2", time.Minute); !fresh {
+		t.Fatal("不同随机数应记录成功")
+	}
+}
+
+func TestMemoryNonceStoreExpires(t *testing.T) {
+	store := NewMemoryNonceStore()
+	ctx := context.Background()
+
+	if fresh, _ := store.Remember(ctx, "svc:nonce", 10*time.Millisecond); !fresh {
+		t.Fatal("首次记录应成功")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if fresh, _ := store.Remember(ctx, "svc:nonce", time.Minute); !fresh {
+		t.Fatal("过期后应可重新记录")
+	}
+}
@@ -0,0 +1,130 @@
+// repository/memory_rate_limit_store.go
+package repository
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// tokenBucket 令牌桶状态
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	expiresAt time.Time
+}
+
+// slidingWindow 滑动窗口状态，index 为当前窗口序号
+type slidingWindow struct {
+	index     int64
+	previous  int
+	current   int
+	expiresAt time.Time
+}
+
+// MemoryRateLimitStore 基于内存的限流计数存储，适用于单实例部署和测试
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	windows   map[string]*slidingWindow
+	lastSweep time.Time
+}
+
+// NewMemoryRateLimitStore 创建内存限流计数存储
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: make(map[string]*tokenBucket),
+		windows: make(map[string]*slidingWindow),
+	}
+}
+
+// TokenBucket 令牌桶限流
+func (s *MemoryRateLimitStore) TokenBucket(ctx context.Context, key string, limit int, window time.Duration) (*RateLimitResult, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweepLocked(now)
+	capacity := float64(limit)
+	perToken := float64(window) / capacity
+
+	bucket, exists := s.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{tokens: capacity, updatedAt: now}
+		s.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(capacity, bucket.tokens+float64(now.Sub(bucket.updatedAt))/perToken)
+	bucket.updatedAt = now
+	bucket.expiresAt = now.Add(window)
+
+	result := &RateLimitResult{}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration(math.Ceil((1 - bucket.tokens) * perToken))
+	}
+	result.Remaining = int(bucket.tokens)
+	result.Reset = time.Duration(math.Ceil((capacity - bucket.tokens) * perToken))
+	return result, nil
+}
+
+// SlidingWindow 滑动窗口限流
+func (s *MemoryRateLimitStore) SlidingWindow(ctx context.Context, key string, limit int, window time.Duration) (*RateLimitResult, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweepLocked(now)
+	index := now.UnixNano() / int64(window)
+	elapsed := time.Duration(now.UnixNano() - index*int64(window))
+
+	state, exists := s.windows[key]
+	if !exists {
+		state = &slidingWindow{index: index}
+		s.windows[key] = state
+	}
+	switch {
+	case state.index == index-1:
+		state.previous, state.current = state.current, 0
+	case state.index < index-1:
+		state.previous, state.current = 0, 0
+	}
+	state.index = index
+	state.expiresAt = now.Add(2 * window)
+
+	weight := float64(window-elapsed) / float64(window)
+	estimated := float64(state.previous)*weight + float64(state.current)
+	if estimated+1 > float64(limit) {
+		return &RateLimitResult{
+			Reset:      window - elapsed,
+			RetryAfter: slidingWindowRetryAfter(limit, window, elapsed, state.previous, state.current),
+		}, nil
+	}
+
+	state.current++
+	return &RateLimitResult{
+		Allowed:   true,
+		Remaining: int(float64(limit) - estimated - 1),
+		Reset:     window - elapsed,
+	}, nil
+}
+
+// sweepLocked 每分钟清理一次已过期的限流状态，调用方需持有锁
+func (s *MemoryRateLimitStore) sweepLocked(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	for key, bucket := range s.buckets {
+		if now.After(bucket.expiresAt) {
+			delete(s.buckets, key)
+		}
+	}
+	for key, state := range s.windows {
+		if now.After(state.expiresAt) {
+			delete(s.windows, key)
+		}
+	}
+	s.lastSweep = now
+}
@@ -0,0 +1,107 @@
+// repository/memory_rate_limit_store_test.go
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryRateLimitStoreTokenBucket(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	ctx := context.Background()
+
+	for i, want := range []int{1, 0} {
+		result, err := store.TokenBucket(ctx, "ip:1", 2, time.Hour)
+		if err != nil {
+			t.Fatalf("令牌桶限流失败: %v", err)
+		}
+		if !result.Allowed || result.Remaining != want {
+			t.Fatalf("第 %d 次请求 allowed = %v, remaining = %d, want true, %d", i+1, result.Allowed, result.Remaining, want)
+		}
+	}
+
+	result, _ := store.TokenBucket(ctx, "ip:1", 2, time.Hour)
+	if result.Allowed {
+		t.Fatal("令牌耗尽后应拒绝")
+	}
+	// 每 30 分钟补充一个令牌
+	if result.RetryAfter <= 29*time.Minute || result.RetryAfter > 30*time.Minute {
+		t.Fatalf("RetryAfter = %v, want 约 30m", result.RetryAfter)
+	}
+	if result.Reset <= result.RetryAfter || result.Reset > time.Hour {
+		t.Fatalf("Reset = %v, want (RetryAfter, 1h]", result.Reset)
+	}
+
+	if other, _ := store.TokenBucket(ctx, "ip:2", 2, time.Hour); !other.Allowed {
+		t.Fatal("不同键应分别计数")
+	}
+}
+
+func TestMemoryRateLimitStoreTokenBucketRefills(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	ctx := context.Background()
+
+	if result, _ := store.TokenBucket(ctx, "ip:1", 1, 20*time.Millisecond); !result.Allowed {
+		t.Fatal("首次请求应允许")
+	}
+	if result, _ := store.TokenBucket(ctx, "ip:1", 1, 20*time.Millisecond); result.Allowed {
+		t.Fatal("令牌耗尽后应拒绝")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if result, _ := store.TokenBucket(ctx, "ip:1", 1, 20*time.Millisecond); !result.Allowed {
+		t.Fatal("补充令牌后应允许")
+	}
+}
+
+func TestMemoryRateLimitStoreSlidingWindow(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	ctx := context.Background()
+
+	for i, want := range []int{2, 1, 0} {
+		result, err := store.SlidingWindow(ctx, "key:1", 3, time.Hour)
+		if err != nil {
+			t.Fatalf("滑动窗口限流失败: %v", err)
+		}
+		if !result.Allowed || result.Remaining != want {
+			t.Fatalf("第 %d 次请求 allowed = %v, remaining = %d, want true, %d", i+1, result.Allowed, result.Remaining, want)
+		}
+	}
+
+	result, _ := store.SlidingWindow(ctx, "key:1", 3, time.Hour)
+	if result.Allowed {
+		t.Fatal("超出上限后应拒绝")
+	}
+	// 当前窗口已满，需要等到下一窗口且上一窗口权重降到 2/3 以下
+	if result.RetryAfter <= result.Reset || result.RetryAfter > result.Reset+time.Hour/3+time.Millisecond {
+		t.Fatalf("RetryAfter = %v, Reset = %v", result.RetryAfter, result.Reset)
+	}
+
+	if other, _ := store.SlidingWindow(ctx, "key:2", 3, time.Hour); !other.Allowed {
+		t.Fatal("不同键应分别计数")
+	}
+}
+
+func TestSlidingWindowRetryAfter(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    int
+		elapsed  time.Duration
+		previous int
+		current  int
+		want     time.Duration
+	}{
+		// 估算值 10*0.5+5=10，上一窗口权重降到 0.4 后为 9
+		{name: "previous window decays", limit: 10, elapsed: 30 * time.Second, previous: 10, current: 5, want: 6 * time.Second},
+		// 当前窗口已满，等到下一窗口后再等待 10 的权重降到 0.9
+		{name: "current window full", limit: 10, elapsed: 30 * time.Second, previous: 0, current: 10, want: 36 * time.Second},
+		{name: "limit one", limit: 1, elapsed: 15 * time.Second, previous: 0, current: 1, want: 105 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := slidingWindowRetryAfter(tt.limit, time.Minute, tt.elapsed, tt.previous, tt.current); got != tt.want {
+				t.Fatalf("RetryAfter = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
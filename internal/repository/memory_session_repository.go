@@ -0,0 +1,162 @@
+// repository/memory_session_repository.go
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"gin_saas_auth/internal/models"
+)
+
+// MemorySessionRepository 基于内存的会话存储，适用于本地开发和测试
+type MemorySessionRepository struct {
+	mu        sync.RWMutex
+	sessions  map[string]*models.Session
+	tokens    map[string]*models.RefreshToken // key: token hash
+	tokenByID map[string]string               // key: token id, value: token hash
+}
+
+// NewMemorySessionRepository 创建内存会话存储
+func NewMemorySessionRepository() *MemorySessionRepository {
+	return &MemorySessionRepository{
+		sessions:  make(map[string]*models.Session),
+		tokens:    make(map[string]*models.RefreshToken),
+		tokenByID: make(map[string]string),
+	}
+}
+
+// CreateSession 创建会话
+func (r *MemorySessionRepository) CreateSession(ctx context.Context, session *models.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.sessions[session.ID]; exists {
+		return ErrDuplicate
+	}
+	copied := *session
+	r.sessions[session.ID] = &copied
+	return nil
+}
+
+// GetSession 根据 ID 查询会话
+func (r *MemorySessionRepository) GetSession(ctx context.Context, id string) (*models.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, exists := r.sessions[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	copied := *session
+	return &copied, nil
+}
+
+// ListSessionsByUser 查询用户所有未撤销的会话
+func (r *MemorySessionRepository) ListSessionsByUser(ctx context.Context, userID string) ([]*models.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*models.Session
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			copied := *session
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+// TouchSession 更新会话最近使用时间
+func (r *MemorySessionRepository) TouchSession(ctx context.Context, id string, usedAt time.Time, userAgent, clientIP string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, exists := r.sessions[id]
+	if !exists {
+		return ErrNotFound
+	}
+	session.LastUsedAt = usedAt
+	session.UserAgent = userAgent
+	session.ClientIP = clientIP
+	return nil
+}
+
+// RevokeSession 撤销会话
+func (r *MemorySessionRepository) RevokeSession(ctx context.Context, id string, revokedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, exists := r.sessions[id]
+	if !exists || session.RevokedAt != nil {
+		return ErrNotFound
+	}
+	at := revokedAt
+	session.RevokedAt = &at
+	return nil
+}
+
+// RevokeUserSessions 撤销用户的所有会话
+func (r *MemorySessionRepository) RevokeUserSessions(ctx context.Context, userID string, revokedAt time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := 0
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			at := revokedAt
+			session.RevokedAt = &at
+			count++
+		}
+	}
+	return count, nil
+}
+
+// SaveRefreshToken 保存刷新令牌
+func (r *MemorySessionRepository) SaveRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tokens[token.TokenHash]; exists {
+		return ErrDuplicate
+	}
+	copied := *token
+	r.tokens[token.TokenHash] = &copied
+	r.tokenByID[token.ID] = token.TokenHash
+	return nil
+}
+
+// GetRefreshToken 根据摘要查询刷新令牌
+func (r *MemorySessionRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	token, exists := r.tokens[tokenHash]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	copied := *token
+	return &copied, nil
+}
+
+// MarkRefreshTokenUsed 将未使用的令牌标记为已使用
+func (r *MemorySessionRepository) MarkRefreshTokenUsed(ctx context.Context, id string, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	hash, exists := r.tokenByID[id]
+	if !exists {
+		return ErrNotFound
+	}
+	token := r.tokens[hash]
+	if token.UsedAt != nil {
+		return ErrNotFound
+	}
+	at := usedAt
+	token.UsedAt = &at
+	return nil
+}
@@ -0,0 +1,117 @@
+// repository/memory_tenant_repository.go
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"gin_saas_auth/internal/models"
+)
+
+// MemoryTenantRepository 基于内存的租户存储，适用于本地开发和测试
+type MemoryTenantRepository struct {
+	mu       sync.RWMutex
+	tenants  map[string]*models.Tenant
+	policies map[string]*models.PasswordPolicy
+}
+
+// NewMemoryTenantRepository 创建内存租户存储
+func NewMemoryTenantRepository() *MemoryTenantRepository {
+	return &MemoryTenantRepository{
+		tenants:  make(map[string]*models.Tenant),
+		policies: make(map[string]*models.PasswordPolicy),
+	}
+}
+
+// CreateTenant 创建租户
+func (r *MemoryTenantRepository) CreateTenant(ctx context.Context, tenant *models.Tenant) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tenants[tenant.ID]; exists {
+		return ErrDuplicate
+	}
+	copied := *tenant
+	r.tenants[tenant.ID] = &copied
+	return nil
+}
+
+// GetTenant 根据 ID 查询租户
+func (r *MemoryTenantRepository) GetTenant(ctx context.Context, id string) (*models.Tenant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tenant, exists := r.tenants[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	copied := *tenant
+	return &copied, nil
+}
+
+// ListTenants 查询全部租户
+func (r *MemoryTenantRepository) ListTenants(ctx context.Context) ([]*models.Tenant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*models.Tenant, 0, len(r.tenants))
+	for _, tenant := range r.tenants {
+		copied := *tenant
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+// UpdateTenantStatus 更新租户状态
+func (r *MemoryTenantRepository) UpdateTenantStatus(ctx context.Context, id string, status models.TenantStatus, updatedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tenant, exists := r.tenants[id]
+	if !exists {
+		return ErrNotFound
+	}
+	tenant.Status = status
+	tenant.UpdatedAt = updatedAt
+	return nil
+}
+
+// GetPasswordPolicy 查询租户密码策略
+func (r *MemoryTenantRepository) GetPasswordPolicy(ctx context.Context, tenantID string) (*models.PasswordPolicy, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	policy, exists := r.policies[tenantID]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	copied := *policy
+	return &copied, nil
+}
+
+// SavePasswordPolicy 保存租户密码策略
+func (r *MemoryTenantRepository) SavePasswordPolicy(ctx context.Context, policy *models.PasswordPolicy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copied := *policy
+	r.policies[policy.TenantID] = &copied
+	return nil
+}
+
+// DeletePasswordPolicy 删除租户密码策略
+func (r *MemoryTenantRepository) DeletePasswordPolicy(ctx context.Context, tenantID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.policies[tenantID]; !exists {
+		return ErrNotFound
+	}
+	delete(r.policies, tenantID)
+	return nil
+}
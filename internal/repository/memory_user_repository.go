@@ -0,0 +1,200 @@
+// repository/memory_user_repository.go
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gin_saas_auth/internal/models"
+)
+
+// MemoryUserRepository 基于内存的用户存储，适用于本地开发和测试
+type MemoryUserRepository struct {
+	mu          sync.RWMutex
+	users       map[string]*models.User
+	emailIndex  map[string]string
+	phoneIndex  map[string]string
+	resetTokens map[string]*models.PasswordResetToken
+	history     map[string][]string // key: user id, value: 历史密码哈希，最近的在前
+}
+
+// NewMemoryUserRepository 创建内存用户存储
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{
+		users:       make(map[string]*models.User),
+		emailIndex:  make(map[string]string),
+		phoneIndex:  make(map[string]string),
+		resetTokens: make(map[string]*models.PasswordResetToken),
+		history:     make(map[string][]string),
+	}
+}
+
+// Create 创建用户
+func (r *MemoryUserRepository) Create(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := emailKey(user.TenantID, user.Email)
+	if _, exists := r.emailIndex[key]; exists {
+		return ErrDuplicate
+	}
+	if _, exists := r.users[user.ID]; exists {
+		return ErrDuplicate
+	}
+
+	copied := *user
+	r.users[user.ID] = &copied
+	r.emailIndex[key] = user.ID
+	return nil
+}
+
+// GetByID 根据 ID 查询用户
+func (r *MemoryUserRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, exists := r.users[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+// GetByEmail 根据邮箱查询用户
+func (r *MemoryUserRepository) GetByEmail(ctx context.Context, tenantID, email string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, exists := r.emailIndex[emailKey(tenantID, email)]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	copied := *r.users[id]
+	return &copied, nil
+}
+
+// GetByPhone 根据手机号查询用户
+func (r *MemoryUserRepository) GetByPhone(ctx context.Context, tenantID, phone string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, exists := r.phoneIndex[emailKey(tenantID, phone)]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	copied := *r.users[id]
+	return &copied, nil
+}
+
+// UpdatePassword 更新用户密码哈希
+func (r *MemoryUserRepository) UpdatePassword(ctx context.Context, id, passwordHash string, changedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, exists := r.users[id]
+	if !exists {
+		return ErrNotFound
+	}
+	user.PasswordHash = passwordHash
+	user.PasswordChangedAt = changedAt
+	user.UpdatedAt = changedAt
+	return nil
+}
+
+// MarkEmailVerified 标记邮箱已验证
+func (r *MemoryUserRepository) MarkEmailVerified(ctx context.Context, id string, verifiedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, exists := r.users[id]
+	if !exists {
+		return ErrNotFound
+	}
+	if user.EmailVerifiedAt == nil {
+		user.EmailVerifiedAt = &verifiedAt
+		user.UpdatedAt = verifiedAt
+	}
+	return nil
+}
+
+// UpdatePhone 保存验证通过的手机号
+func (r *MemoryUserRepository) UpdatePhone(ctx context.Context, id, phone string, verifiedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, exists := r.users[id]
+	if !exists {
+		return ErrNotFound
+	}
+	key := emailKey(user.TenantID, phone)
+	if owner, exists := r.phoneIndex[key]; exists && owner != id {
+		return ErrDuplicate
+	}
+
+	if user.Phone != "" {
+		delete(r.phoneIndex, emailKey(user.TenantID, user.Phone))
+	}
+	r.phoneIndex[key] = id
+	user.Phone = phone
+	user.PhoneVerifiedAt = &verifiedAt
+	user.UpdatedAt = verifiedAt
+	return nil
+}
+
+// SaveResetToken 保存重置令牌
+func (r *MemoryUserRepository) SaveResetToken(ctx context.Context, token *models.PasswordResetToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copied := *token
+	r.resetTokens[token.TokenHash] = &copied
+	return nil
+}
+
+// ConsumeResetToken 标记令牌已使用并返回令牌
+func (r *MemoryUserRepository) ConsumeResetToken(ctx context.Context, tokenHash string, now time.Time) (*models.PasswordResetToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, exists := r.resetTokens[tokenHash]
+	if !exists || token.UsedAt != nil || !now.Before(token.ExpiresAt) {
+		return nil, ErrNotFound
+	}
+	usedAt := now
+	token.UsedAt = &usedAt
+
+	copied := *token
+	return &copied, nil
+}
+
+// emailKey 邮箱和手机号索引键，邮箱和手机号均在租户内唯一
+func emailKey(tenantID, email string) string {
+	return tenantID + "\x00" + email
+}
+
+// AddPasswordHistory 记录被替换的密码哈希
+func (r *MemoryUserRepository) AddPasswordHistory(ctx context.Context, userID, passwordHash string, replacedAt time.Time, keep int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	history := append([]string{passwordHash}, r.history[userID]...)
+	if len(history) > keep {
+		history = history[:keep]
+	}
+	r.history[userID] = history
+	return nil
+}
+
+// ListPasswordHistory 查询最近的历史密码哈希
+func (r *MemoryUserRepository) ListPasswordHistory(ctx context.Context, userID string, limit int) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	history := r.history[userID]
+	if len(history) > limit {
+		history = history[:limit]
+	}
+	return append([]string(nil), history...), nil
+}
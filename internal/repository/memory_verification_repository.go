@@ -0,0 +1,85 @@
+// repository/memory_verification_repository.go
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gin_saas_auth/internal/models"
+)
+
+// MemoryVerificationRepository 基于内存的验证码存储，每个目标只保留最近一次发送的验证码
+type MemoryVerificationRepository struct {
+	mu     sync.Mutex
+	latest map[string]*models.VerificationCode
+	byID   map[string]*models.VerificationCode
+}
+
+// NewMemoryVerificationRepository 创建内存验证码存储
+func NewMemoryVerificationRepository() *MemoryVerificationRepository {
+	return &MemoryVerificationRepository{
+		latest: make(map[string]*models.VerificationCode),
+		byID:   make(map[string]*models.VerificationCode),
+	}
+}
+
+// Save 保存新发送的验证码，替换同一目标之前的验证码
+func (r *MemoryVerificationRepository) Save(ctx context.Context, code *models.VerificationCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := verificationKey(code.TenantID, code.Channel, code.Destination, code.Purpose)
+	if previous, exists := r.latest[key]; exists {
+		delete(r.byID, previous.ID)
+	}
+
+	copied := *code
+	r.latest[key] = &copied
+	r.byID[code.ID] = &copied
+	return nil
+}
+
+// GetLatest 查询最近一次发送的验证码
+func (r *MemoryVerificationRepository) GetLatest(ctx context.Context, tenantID, channel, destination, purpose string) (*models.VerificationCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	code, exists := r.latest[verificationKey(tenantID, channel, destination, purpose)]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	copied := *code
+	return &copied, nil
+}
+
+// IncrementAttempts 校验次数加一
+func (r *MemoryVerificationRepository) IncrementAttempts(ctx context.Context, id string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	code, exists := r.byID[id]
+	if !exists {
+		return 0, ErrNotFound
+	}
+	code.Attempts++
+	return code.Attempts, nil
+}
+
+// Consume 标记验证码已使用
+func (r *MemoryVerificationRepository) Consume(ctx context.Context, id string, consumedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	code, exists := r.byID[id]
+	if !exists || code.ConsumedAt != nil {
+		return ErrNotFound
+	}
+	code.ConsumedAt = &consumedAt
+	return nil
+}
+
+// verificationKey 验证码索引键
+func verificationKey(tenantID, channel, destination, purpose string) string {
+	return tenantID + "\x00" + channel + "\x00" + destination + "\x00" + purpose
+}
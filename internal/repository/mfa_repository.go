@@ -0,0 +1,30 @@
+// repository/mfa_repository.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gin_saas_auth/internal/models"
+)
+
+// MFARepository 多因素认证数据存储接口
+type MFARepository interface {
+	// GetTOTP 查询用户的 TOTP 绑定，未绑定时返回 ErrNotFound
+	GetTOTP(ctx context.Context, userID string) (*models.TOTPEnrollment, error)
+	// SaveTOTP 保存或覆盖用户的 TOTP 绑定
+	SaveTOTP(ctx context.Context, enrollment *models.TOTPEnrollment) error
+	// ConfirmTOTP 将 TOTP 绑定标记为已确认
+	ConfirmTOTP(ctx context.Context, userID string, step int64, confirmedAt time.Time) error
+	// AdvanceTOTPStep 原子地更新最近使用的时间步，仅当 step 大于已记录值时成功，否则返回 ErrNotFound
+	AdvanceTOTPStep(ctx context.Context, userID string, step int64) error
+	// DeleteTOTP 删除用户的 TOTP 绑定及全部恢复码
+	DeleteTOTP(ctx context.Context, userID string) error
+
+	// ReplaceRecoveryCodes 用新的恢复码替换用户现有的全部恢复码
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codes []*models.RecoveryCode) error
+	// ConsumeRecoveryCode 原子地使用一个恢复码，不存在或已使用时返回 ErrNotFound
+	ConsumeRecoveryCode(ctx context.Context, userID, codeHash string, usedAt time.Time) error
+	// CountRecoveryCodes 统计用户未使用的恢复码数量
+	CountRecoveryCodes(ctx context.Context, userID string) (int, error)
+}
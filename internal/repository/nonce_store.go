@@ -0,0 +1,14 @@
+// repository/nonce_store.go
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// NonceStore 请求签名随机数存储接口
+// 多实例部署时需要使用共享存储（Redis），否则重放到其他实例的请求无法被识别
+type NonceStore interface {
+	// Remember 记录随机数并保留 ttl，有效期内已记录过时返回 false
+	Remember(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
@@ -0,0 +1,42 @@
+// repository/rate_limit_store.go
+package repository
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// RateLimitResult 单次限流判定结果
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int           // 本次请求之后剩余的额度
+	Reset      time.Duration // 额度完全恢复前的时间
+	RetryAfter time.Duration // 被拒绝时建议的重试等待时间
+}
+
+// RateLimitStore 请求限流计数存储接口
+// 多实例部署时需要使用共享存储（Redis），否则各实例分别计数
+type RateLimitStore interface {
+	// TokenBucket 令牌桶：桶容量为 limit，每个 window 匀速补满，允许时取走一个令牌
+	TokenBucket(ctx context.Context, key string, limit int, window time.Duration) (*RateLimitResult, error)
+	// SlidingWindow 滑动窗口：以上一窗口计数按剩余比例加权估算最近 window 内的请求数，允许时计数加一
+	SlidingWindow(ctx context.Context, key string, limit int, window time.Duration) (*RateLimitResult, error)
+}
+
+// slidingWindowRetryAfter 滑动窗口被拒绝时，估算的请求数降到 limit-1 以下所需的时间
+// elapsed 为当前窗口已经过的时间，previous、current 分别为上一窗口和当前窗口的计数
+func slidingWindowRetryAfter(limit int, window, elapsed time.Duration, previous, current int) time.Duration {
+	// 当前窗口内随上一窗口权重下降即可恢复额度
+	if current < limit && previous > 0 {
+		wait := float64(window-elapsed) - float64(limit-1-current)*float64(window)/float64(previous)
+		return time.Duration(math.Max(math.Ceil(wait), float64(time.Millisecond)))
+	}
+
+	// 需要等到下一窗口，当前窗口的计数成为上一窗口后按剩余比例下降
+	wait := float64(window - elapsed)
+	if current > 0 {
+		wait += math.Max(0, float64(window)-float64(limit-1)*float64(window)/float64(current))
+	}
+	return time.Duration(math.Ceil(wait))
+}
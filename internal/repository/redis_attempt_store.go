@@ -0,0 +1,92 @@
+// repository/redis_attempt_store.go
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisAttemptStore 基于 Redis 的失败计数存储，多实例共享计数
+// 失败次数和锁定状态分别保存为两个带过期时间的键
+type RedisAttemptStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisAttemptStore 创建 Redis 失败计数存储
+func NewRedisAttemptStore(client *redis.Client, prefix string) *RedisAttemptStore {
+	return &RedisAttemptStore{client: client, prefix: prefix + "login:"}
+}
+
+// IncrementFailures 失败次数加一
+func (s *RedisAttemptStore) IncrementFailures(ctx context.Context, key string, window time.Duration) (int, error) {
+	pipe := s.client.TxPipeline()
+	incr := pipe.Incr(ctx, s.failuresKey(key))
+	pipe.PExpire(ctx, s.failuresKey(key), window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("记录登录失败次数失败: %w", err)
+	}
+	return int(incr.Val()), nil
+}
+
+// GetFailures 查询当前失败次数
+func (s *RedisAttemptStore) GetFailures(ctx context.Context, key string) (int, error) {
+	count, err := s.client.Get(ctx, s.failuresKey(key)).Int()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("查询登录失败次数失败: %w", err)
+	}
+	return count, nil
+}
+
+// Lock 锁定指定时长，已有更长的锁定时保留原锁定
+func (s *RedisAttemptStore) Lock(ctx context.Context, key string, duration time.Duration) error {
+	remaining, err := s.LockRemaining(ctx, key)
+	if err != nil {
+		return err
+	}
+	if remaining >= duration {
+		return nil
+	}
+	if err := s.client.Set(ctx, s.lockKey(key), 1, duration).Err(); err != nil {
+		return fmt.Errorf("写入锁定状态失败: %w", err)
+	}
+	return nil
+}
+
+// LockRemaining 查询剩余锁定时间
+func (s *RedisAttemptStore) LockRemaining(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := s.client.PTTL(ctx, s.lockKey(key)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("查询锁定状态失败: %w", err)
+	}
+	// 键不存在时 PTTL 返回负值
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// Reset 清除失败次数和锁定状态
+func (s *RedisAttemptStore) Reset(ctx context.Context, key string) error {
+	if err := s.client.Del(ctx, s.failuresKey(key), s.lockKey(key)).Err(); err != nil {
+		return fmt.Errorf("清除登录失败状态失败: %w", err)
+	}
+	return nil
+}
+
+// failuresKey 失败计数键
+func (s *RedisAttemptStore) failuresKey(key string) string {
+	return s.prefix + "failures:" + key
+}
+
+// lockKey 锁定状态键
+func (s *RedisAttemptStore) lockKey(key string) string {
+	return s.prefix + "lock:" + key
+}
@@ -0,0 +1,34 @@
+// repository/redis_nonce_store.go
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisNonceStore 基于 Redis 的随机数存储，多实例共享，重放到任一实例的请求都会被拒绝
+type RedisNonceStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisNonceStore 创建 Redis 随机数存储
+func NewRedisNonceStore(client *redis.Client, prefix string) *RedisNonceStore {
+	return &RedisNonceStore{client: client, prefix: prefix + "nonce:"}
+}
+
+// Remember 使用 SET NX PX 原子地记录随机数，键已存在时说明随机数已被使用
+func (s *RedisNonceStore) Remember(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	err := s.client.Do(ctx, "SET", s.prefix+key, 1, "NX", "PX", ttl.Milliseconds()).Err()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return false, nil
+		}
+		return false, fmt.Errorf("记录请求签名随机数失败: %w", err)
+	}
+	return true, nil
+}
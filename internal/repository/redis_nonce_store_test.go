@@ -0,0 +1,48 @@
+// repository/redis_nonce_store_test.go
+package repository
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestRedis 连接 TEST_REDIS_ADDR 指定的 Redis，未设置时跳过测试
+// 每个测试使用独立的键前缀，互不影响
+func newTestRedis(t *testing.T) (*redis.Client, string) {
+	t.Helper()
+	addr := os.Getenv("TEST_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("未设置 TEST_REDIS_ADDR，跳过 Redis 测试")
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	t.Cleanup(func() { client.Close() })
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		t.Fatalf("连接 Redis 失败: %v", err)
+	}
+	return client, "test:" + t.Name() + ":" + strconv.FormatInt(time.Now().UnixNano(), 36) + ":"
+}
+
+func TestRedisNonceStoreRemember(t *testing.T) {
+	client, prefix := newTestRedis(t)
+	ctx := context.Background()
+	// 两个实例共享同一 Redis
+	first := NewRedisNonceStore(client, prefix)
+	second := NewRedisNonceStore(client, prefix)
+
+	if fresh, err := first.Remember(ctx, "svc:nonce", time.Minute); err != nil || !fresh {
+		t.Fatalf("首次记录 fresh = %v, err = %v", fresh, err)
+	}
+	if fresh, err := second.Remember(ctx, "svc:nonce", time.Minute); err != nil || fresh {
+		t.Fatalf("其他实例重复记录 fresh = %v, err = %v, want false", fresh, err)
+	}
+
+	ttl, err := client.PTTL(ctx, prefix+"nonce:svc:nonce").Result()
+	if err != nil || ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("随机数过期时间 = %v, err = %v", ttl, err)
+	}
+}
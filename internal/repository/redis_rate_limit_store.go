@@ -0,0 +1,138 @@
+// repository/redis_rate_limit_store.go
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript 令牌桶脚本，使用 Redis 服务器时间，多实例之间不受时钟偏差影响
+// 返回 {是否允许, 剩余令牌数}
+var tokenBucketScript = redis.NewScript(`
+if redis.replicate_commands then redis.replicate_commands() end
+local capacity = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * capacity / window)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], window)
+return {allowed, tostring(tokens)}
+`)
+
+// slidingWindowScript 滑动窗口脚本，KEYS[1] 为当前窗口计数键，KEYS[2] 为上一窗口计数键
+// 窗口序号由调用方计算，两个键使用相同的 {hash tag}，Redis Cluster 下落在同一槽位
+// 返回 {是否允许, 上一窗口计数, 当前窗口计数（不含本次）}
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local elapsed = tonumber(ARGV[3])
+
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+
+local estimated = previous * (window - elapsed) / window + current
+if estimated + 1 > limit then
+	return {0, previous, current}
+end
+redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], window * 2)
+return {1, previous, current}
+`)
+
+// RedisRateLimitStore 基于 Redis 的限流计数存储，多实例共享计数
+type RedisRateLimitStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisRateLimitStore 创建 Redis 限流计数存储
+func NewRedisRateLimitStore(client *redis.Client, prefix string) *RedisRateLimitStore {
+	return &RedisRateLimitStore{client: client, prefix: prefix + "ratelimit:"}
+}
+
+// TokenBucket 令牌桶限流
+func (s *RedisRateLimitStore) TokenBucket(ctx context.Context, key string, limit int, window time.Duration) (*RateLimitResult, error) {
+	reply, err := tokenBucketScript.Run(ctx, s.client, []string{s.prefix + "tb:" + key}, limit, window.Milliseconds()).Slice()
+	if err != nil {
+		return nil, fmt.Errorf("执行令牌桶限流失败: %w", err)
+	}
+	if len(reply) != 2 {
+		return nil, fmt.Errorf("令牌桶限流返回值格式错误")
+	}
+
+	allowed, _ := reply[0].(int64)
+	tokensStr, _ := reply[1].(string)
+	tokens, err := strconv.ParseFloat(tokensStr, 64)
+	if err != nil {
+		return nil, fmt.Errorf("解析令牌桶剩余令牌失败: %w", err)
+	}
+
+	perToken := float64(window) / float64(limit)
+	result := &RateLimitResult{
+		Allowed:   allowed == 1,
+		Remaining: int(tokens),
+		Reset:     time.Duration(math.Ceil((float64(limit) - tokens) * perToken)),
+	}
+	if !result.Allowed {
+		result.RetryAfter = time.Duration(math.Ceil((1 - tokens) * perToken))
+	}
+	return result, nil
+}
+
+// SlidingWindow 滑动窗口限流
+// 窗口序号按本实例时钟计算，多实例之间的时钟偏差只影响窗口切换的时刻
+func (s *RedisRateLimitStore) SlidingWindow(ctx context.Context, key string, limit int, window time.Duration) (*RateLimitResult, error) {
+	now := time.Now().UnixMilli()
+	windowMs := max(window.Milliseconds(), 1)
+	index := now / windowMs
+	elapsed := time.Duration(now-index*windowMs) * time.Millisecond
+
+	keys := s.slidingWindowKeys(key, index)
+	reply, err := slidingWindowScript.Run(ctx, s.client, keys, limit, windowMs, elapsed.Milliseconds()).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("执行滑动窗口限流失败: %w", err)
+	}
+	if len(reply) != 3 {
+		return nil, fmt.Errorf("滑动窗口限流返回值格式错误")
+	}
+
+	previous, current := int(reply[1]), int(reply[2])
+	result := &RateLimitResult{Allowed: reply[0] == 1, Reset: window - elapsed}
+	if !result.Allowed {
+		result.RetryAfter = slidingWindowRetryAfter(limit, window, elapsed, previous, current)
+		return result, nil
+	}
+
+	estimated := float64(previous)*float64(window-elapsed)/float64(window) + float64(current)
+	result.Remaining = int(float64(limit) - estimated - 1)
+	return result, nil
+}
+
+// slidingWindowKeys 当前窗口和上一窗口的计数键，限流键放在 {} 中作为 hash tag
+func (s *RedisRateLimitStore) slidingWindowKeys(key string, index int64) []string {
+	base := s.prefix + "sw:{" + key + "}:"
+	return []string{
+		base + strconv.FormatInt(index, 10),
+		base + strconv.FormatInt(index-1, 10),
+	}
+}
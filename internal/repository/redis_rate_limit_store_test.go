@@ -0,0 +1,68 @@
+// repository/redis_rate_limit_store_test.go
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedisSlidingWindowKeysShareHashTag(t *testing.T) {
+	store := NewRedisRateLimitStore(nil, "auth:")
+	keys := store.slidingWindowKeys("api_key:ak_1", 42)
+
+	want := []string{"auth:ratelimit:sw:{api_key:ak_1}:42", "auth:ratelimit:sw:{api_key:ak_1}:41"}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Fatalf("keys = %q, want %q", keys, want)
+		}
+	}
+	for _, key := range keys {
+		if tag := key[strings.Index(key, "{")+1 : strings.Index(key, "}")]; tag != "api_key:ak_1" {
+			t.Fatalf("键 %s 的 hash tag = %q", key, tag)
+		}
+	}
+}
+
+func TestRedisRateLimitStoreTokenBucket(t *testing.T) {
+	client, prefix := newTestRedis(t)
+	store := NewRedisRateLimitStore(client, prefix)
+	ctx := context.Background()
+
+	for i, want := range []int{1, 0} {
+		result, err := store.TokenBucket(ctx, "ip:1", 2, time.Hour)
+		if err != nil {
+			t.Fatalf("令牌桶限流失败: %v", err)
+		}
+		if !result.Allowed || result.Remaining != want {
+			t.Fatalf("第 %d 次请求 allowed = %v, remaining = %d, want true, %d", i+1, result.Allowed, result.Remaining, want)
+		}
+	}
+	result, err := store.TokenBucket(ctx, "ip:1", 2, time.Hour)
+	if err != nil || result.Allowed || result.RetryAfter <= 0 {
+		t.Fatalf("令牌耗尽后 result = %+v, err = %v, want 拒绝", result, err)
+	}
+}
+
+func TestRedisRateLimitStoreSlidingWindow(t *testing.T) {
+	client, prefix := newTestRedis(t)
+	// 两个实例共享同一 Redis 计数
+	first := NewRedisRateLimitStore(client, prefix)
+	second := NewRedisRateLimitStore(client, prefix)
+	ctx := context.Background()
+
+	for i, store := range []*RedisRateLimitStore{first, second, first} {
+		result, err := store.SlidingWindow(ctx, "key:1", 3, time.Hour)
+		if err != nil {
+			t.Fatalf("滑动窗口限流失败: %v", err)
+		}
+		if want := 2 - i; !result.Allowed || result.Remaining != want {
+			t.Fatalf("第 %d 次请求 allowed = %v, remaining = %d, want true, %d", i+1, result.Allowed, result.Remaining, want)
+		}
+	}
+	result, err := second.SlidingWindow(ctx, "key:1", 3, time.Hour)
+	if err != nil || result.Allowed || result.RetryAfter <= 0 {
+		t.Fatalf("超出上限后 result = %+v, err = %v, want 拒绝", result, err)
+	}
+}
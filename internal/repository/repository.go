@@ -0,0 +1,59 @@
+// repository/repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"gin_saas_auth/internal/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrNotFound 记录不存在
+	ErrNotFound = errors.New("记录不存在")
+	// ErrDuplicate 记录已存在（唯一约束冲突）
+	ErrDuplicate = errors.New("记录已存在")
+)
+
+// OpenDB 根据配置打开数据库连接池
+// 数据库驱动需要在 main 包中以匿名导入方式注册
+func OpenDB(cfg *config.Config) (*sql.DB, error) {
+	db, err := sql.Open(cfg.Database.Driver, cfg.Database.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("打开数据库连接失败: %w", err)
+	}
+
+	db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("连接数据库失败: %w", err)
+	}
+
+	return db, nil
+}
+
+// OpenRedis 根据配置创建 Redis 客户端并检查连通性
+func OpenRedis(cfg *config.Config) (*redis.Client, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Addr,
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("连接 Redis 失败: %w", err)
+	}
+
+	return client, nil
+}
@@ -0,0 +1,32 @@
+// repository/session_repository.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gin_saas_auth/internal/models"
+)
+
+// SessionRepository 会话与刷新令牌存储接口
+type SessionRepository interface {
+	// CreateSession 创建会话
+	CreateSession(ctx context.Context, session *models.Session) error
+	// GetSession 根据 ID 查询会话
+	GetSession(ctx context.Context, id string) (*models.Session, error)
+	// ListSessionsByUser 查询用户所有未撤销的会话，按创建时间倒序
+	ListSessionsByUser(ctx context.Context, userID string) ([]*models.Session, error)
+	// TouchSession 更新会话最近使用时间和客户端信息
+	TouchSession(ctx context.Context, id string, usedAt time.Time, userAgent, clientIP string) error
+	// RevokeSession 撤销会话，会话不存在或已撤销时返回 ErrNotFound
+	RevokeSession(ctx context.Context, id string, revokedAt time.Time) error
+	// RevokeUserSessions 撤销用户的所有会话，返回撤销数量
+	RevokeUserSessions(ctx context.Context, userID string, revokedAt time.Time) (int, error)
+
+	// SaveRefreshToken 保存刷新令牌
+	SaveRefreshToken(ctx context.Context, token *models.RefreshToken) error
+	// GetRefreshToken 根据摘要查询刷新令牌（包括已使用的令牌，用于重用检测）
+	GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	// MarkRefreshTokenUsed 原子地将未使用的令牌标记为已使用，令牌已被使用时返回 ErrNotFound
+	MarkRefreshTokenUsed(ctx context.Context, id string, usedAt time.Time) error
+}
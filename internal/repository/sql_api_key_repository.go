@@ -0,0 +1,132 @@
+// repository/sql_api_key_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gin_saas_auth/internal/models"
+)
+
+// SQLAPIKeyRepository 基于 database/sql 的 API 密钥存储（PostgreSQL 语法）
+// 表结构见 migrations/0005_create_api_keys.sql
+type SQLAPIKeyRepository struct {
+	db *sql.DB
+}
+
+// NewSQLAPIKeyRepository 创建 SQL API 密钥存储
+func NewSQLAPIKeyRepository(db *sql.DB) *SQLAPIKeyRepository {
+	return &SQLAPIKeyRepository{db: db}
+}
+
+const apiKeyColumns = `id, tenant_id, user_id, name, prefix, key_hash, scopes, rate_limit, expires_at, last_used_at, revoked_at, created_at`
+
+// CreateAPIKey 创建密钥
+func (r *SQLAPIKeyRepository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO api_keys (`+apiKeyColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+		key.ID, key.TenantID, key.UserID, key.Name, key.Prefix, key.KeyHash, strings.Join(key.Scopes, " "),
+		key.RateLimit, key.ExpiresAt, key.LastUsedAt, key.RevokedAt, key.CreatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicate
+		}
+		return fmt.Errorf("创建 API 密钥失败: %w", err)
+	}
+	return nil
+}
+
+// GetAPIKey 根据 ID 查询密钥
+func (r *SQLAPIKeyRepository) GetAPIKey(ctx context.Context, id string) (*models.APIKey, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1`, id)
+	return scanAPIKeyRow(row)
+}
+
+// GetAPIKeyByPrefix 根据前缀查询密钥
+func (r *SQLAPIKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE prefix = $1`, prefix)
+	return scanAPIKeyRow(row)
+}
+
+// ListAPIKeysByUser 查询用户的所有密钥
+func (r *SQLAPIKeyRepository) ListAPIKeysByUser(ctx context.Context, userID string) ([]*models.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+apiKeyColumns+` FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("查询 API 密钥列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*models.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("读取 API 密钥失败: %w", err)
+		}
+		result = append(result, key)
+	}
+	return result, rows.Err()
+}
+
+// TouchAPIKey 更新密钥最近使用时间
+func (r *SQLAPIKeyRepository) TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = $1 WHERE id = $2`, usedAt, id)
+	if err != nil {
+		return fmt.Errorf("更新 API 密钥使用时间失败: %w", err)
+	}
+	return expectAffected(result)
+}
+
+// RevokeAPIKey 撤销密钥
+func (r *SQLAPIKeyRepository) RevokeAPIKey(ctx context.Context, id string, revokedAt time.Time) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`,
+		revokedAt, id,
+	)
+	if err != nil {
+		return fmt.Errorf("撤销 API 密钥失败: %w", err)
+	}
+	return expectAffected(result)
+}
+
+// scanAPIKeyRow 扫描单行查询结果，无记录时返回 ErrNotFound
+func scanAPIKeyRow(row *sql.Row) (*models.APIKey, error) {
+	key, err := scanAPIKey(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("查询 API 密钥失败: %w", err)
+	}
+	return key, nil
+}
+
+// scanAPIKey 将查询结果扫描为 API 密钥
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	var key models.APIKey
+	var scopes string
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	err := row.Scan(&key.ID, &key.TenantID, &key.UserID, &key.Name, &key.Prefix, &key.KeyHash, &scopes,
+		&key.RateLimit, &expiresAt, &lastUsedAt, &revokedAt, &key.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	key.Scopes = strings.Fields(scopes)
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return &key, nil
+}
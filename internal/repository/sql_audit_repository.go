@@ -0,0 +1,129 @@
+// repository/sql_audit_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gin_saas_auth/internal/models"
+)
+
+// auditChainLockID 追加审计事件时使用的事务级咨询锁，保证多实例下哈希链不分叉
+const auditChainLockID = 0x61756469 // "audi"
+
+// SQLAuditRepository 基于 database/sql 的审计事件存储（PostgreSQL 语法）
+// 表结构见 migrations/0006_create_audit_events.sql
+type SQLAuditRepository struct {
+	db *sql.DB
+}
+
+// NewSQLAuditRepository 创建 SQL 审计事件存储
+func NewSQLAuditRepository(db *sql.DB) *SQLAuditRepository {
+	return &SQLAuditRepository{db: db}
+}
+
+const auditColumns = `seq, id, type, tenant_id, actor_id, subject, client_ip, user_agent, metadata, occurred_at, prev_hash, hash`
+
+// AppendAuditEvent 追加审计事件
+func (r *SQLAuditRepository) AppendAuditEvent(ctx context.Context, event *models.AuditEvent, seal func(prev *models.AuditEvent)) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开启审计事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, auditChainLockID); err != nil {
+		return fmt.Errorf("获取审计链锁失败: %w", err)
+	}
+
+	prev, err := scanAuditEvent(tx.QueryRowContext(ctx,
+		`SELECT `+auditColumns+` FROM audit_events ORDER BY seq DESC LIMIT 1`,
+	))
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("查询审计链尾失败: %w", err)
+		}
+		prev = nil
+	}
+	seal(prev)
+
+	metadata, err := json.Marshal(event.Metadata)
+	if err != nil {
+		return fmt.Errorf("序列化审计元数据失败: %w", err)
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO audit_events (`+auditColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+		event.Seq, event.ID, event.Type, event.TenantID, event.ActorID, event.Subject, event.ClientIP,
+		event.UserAgent, string(metadata), event.OccurredAt, event.PrevHash, event.Hash,
+	)
+	if err != nil {
+		return fmt.Errorf("写入审计事件失败: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ListAuditEvents 按序号升序查询审计事件
+func (r *SQLAuditRepository) ListAuditEvents(ctx context.Context, filter AuditFilter) ([]*models.AuditEvent, error) {
+	conditions := []string{"seq > $1"}
+	args := []interface{}{filter.AfterSeq}
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, condition+" $"+strconv.Itoa(len(args)))
+	}
+	if filter.TenantID != "" {
+		add("tenant_id =", filter.TenantID)
+	}
+	if filter.ActorID != "" {
+		add("actor_id =", filter.ActorID)
+	}
+	if filter.Type != "" {
+		add("type =", filter.Type)
+	}
+	if !filter.From.IsZero() {
+		add("occurred_at >=", filter.From)
+	}
+	if !filter.To.IsZero() {
+		add("occurred_at <", filter.To)
+	}
+
+	query := `SELECT ` + auditColumns + ` FROM audit_events WHERE ` + strings.Join(conditions, " AND ") + ` ORDER BY seq`
+	if filter.Limit > 0 {
+		query += ` LIMIT ` + strconv.Itoa(filter.Limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询审计事件失败: %w", err)
+	}
+	defer rows.Close()
+
+	result := []*models.AuditEvent{}
+	for rows.Next() {
+		event, err := scanAuditEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("读取审计事件失败: %w", err)
+		}
+		result = append(result, event)
+	}
+	return result, rows.Err()
+}
+
+// scanAuditEvent 将查询结果扫描为审计事件
+func scanAuditEvent(row rowScanner) (*models.AuditEvent, error) {
+	var event models.AuditEvent
+	var metadata string
+	err := row.Scan(&event.Seq, &event.ID, &event.Type, &event.TenantID, &event.ActorID, &event.Subject,
+		&event.ClientIP, &event.UserAgent, &metadata, &event.OccurredAt, &event.PrevHash, &event.Hash)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(metadata), &event.Metadata); err != nil {
+		return nil, fmt.Errorf("解析审计元数据失败: %w", err)
+	}
+	return &event, nil
+}
@@ -0,0 +1,209 @@
+// repository/sql_identity_provider_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gin_saas_auth/internal/models"
+)
+
+// SQLIdentityProviderRepository 基于 database/sql 的身份提供方存储（PostgreSQL 语法）
+// 表结构见 migrations/0008_create_identity_providers.sql
+type SQLIdentityProviderRepository struct {
+	db *sql.DB
+}
+
+// NewSQLIdentityProviderRepository 创建 SQL 身份提供方存储
+func NewSQLIdentityProviderRepository(db *sql.DB) *SQLIdentityProviderRepository {
+	return &SQLIdentityProviderRepository{db: db}
+}
+
+const identityProviderColumns = `id, tenant_id, name, display_name, issuer, client_id, client_secret_encrypted, scopes,
+	email_claim, email_verified_claim, trust_email, auto_link, allow_signup, enabled, created_at, updated_at`
+
+const federatedIdentityColumns = `id, tenant_id, provider_id, subject, user_id, email, created_at, last_login_at`
+
+// CreateProvider 创建提供方
+func (r *SQLIdentityProviderRepository) CreateProvider(ctx context.Context, provider *models.IdentityProvider) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO identity_providers (`+identityProviderColumns+`)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`,
+		provider.ID, provider.TenantID, provider.Name, provider.DisplayName, provider.Issuer, provider.ClientID,
+		provider.ClientSecretEncrypted, strings.Join(provider.Scopes, " "), provider.EmailClaim, provider.EmailVerifiedClaim,
+		provider.TrustEmail, provider.AutoLink, provider.AllowSignup, provider.Enabled, provider.CreatedAt, provider.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicate
+		}
+		return fmt.Errorf("创建身份提供方失败: %w", err)
+	}
+	return nil
+}
+
+// GetProvider 根据租户和名称查询提供方
+func (r *SQLIdentityProviderRepository) GetProvider(ctx context.Context, tenantID, name string) (*models.IdentityProvider, error) {
+	provider, err := scanIdentityProvider(r.db.QueryRowContext(ctx,
+		`SELECT `+identityProviderColumns+` FROM identity_providers WHERE tenant_id = $1 AND name = $2`,
+		tenantID, name,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("查询身份提供方失败: %w", err)
+	}
+	return provider, nil
+}
+
+// ListProviders 查询租户的所有提供方
+func (r *SQLIdentityProviderRepository) ListProviders(ctx context.Context, tenantID string) ([]*models.IdentityProvider, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+identityProviderColumns+` FROM identity_providers WHERE tenant_id = $1 ORDER BY name`,
+		tenantID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("查询身份提供方列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*models.IdentityProvider
+	for rows.Next() {
+		provider, err := scanIdentityProvider(rows)
+		if err != nil {
+			return nil, fmt.Errorf("读取身份提供方失败: %w", err)
+		}
+		result = append(result, provider)
+	}
+	return result, rows.Err()
+}
+
+// UpdateProvider 更新提供方配置，名称不可修改
+func (r *SQLIdentityProviderRepository) UpdateProvider(ctx context.Context, provider *models.IdentityProvider) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE identity_providers SET display_name = $1, issuer = $2, client_id = $3, client_secret_encrypted = $4,
+		 scopes = $5, email_claim = $6, email_verified_claim = $7, trust_email = $8, auto_link = $9, allow_signup = $10,
+		 enabled = $11, updated_at = $12 WHERE id = $13`,
+		provider.DisplayName, provider.Issuer, provider.ClientID, provider.ClientSecretEncrypted,
+		strings.Join(provider.Scopes, " "), provider.EmailClaim, provider.EmailVerifiedClaim, provider.TrustEmail,
+		provider.AutoLink, provider.AllowSignup, provider.Enabled, provider.UpdatedAt, provider.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("更新身份提供方失败: %w", err)
+	}
+	return expectAffected(result)
+}
+
+// DeleteProvider 删除提供方，身份关联由外键级联删除
+func (r *SQLIdentityProviderRepository) DeleteProvider(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM identity_providers WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("删除身份提供方失败: %w", err)
+	}
+	return expectAffected(result)
+}
+
+// CreateIdentity 创建身份关联
+func (r *SQLIdentityProviderRepository) CreateIdentity(ctx context.Context, identity *models.FederatedIdentity) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO federated_identities (`+federatedIdentityColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		identity.ID, identity.TenantID, identity.ProviderID, identity.Subject, identity.UserID, identity.Email,
+		identity.CreatedAt, identity.LastLoginAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicate
+		}
+		return fmt.Errorf("创建身份关联失败: %w", err)
+	}
+	return nil
+}
+
+// GetIdentity 根据提供方和外部 subject 查询身份关联
+func (r *SQLIdentityProviderRepository) GetIdentity(ctx context.Context, providerID, subject string) (*models.FederatedIdentity, error) {
+	identity, err := scanFederatedIdentity(r.db.QueryRowContext(ctx,
+		`SELECT `+federatedIdentityColumns+` FROM federated_identities WHERE provider_id = $1 AND subject = $2`,
+		providerID, subject,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("查询身份关联失败: %w", err)
+	}
+	return identity, nil
+}
+
+// ListIdentitiesByUser 查询用户的所有身份关联
+func (r *SQLIdentityProviderRepository) ListIdentitiesByUser(ctx context.Context, userID string) ([]*models.FederatedIdentity, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+federatedIdentityColumns+` FROM federated_identities WHERE user_id = $1 ORDER BY created_at`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("查询身份关联列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*models.FederatedIdentity
+	for rows.Next() {
+		identity, err := scanFederatedIdentity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("读取身份关联失败: %w", err)
+		}
+		result = append(result, identity)
+	}
+	return result, rows.Err()
+}
+
+// TouchIdentity 更新最近登录时间
+func (r *SQLIdentityProviderRepository) TouchIdentity(ctx context.Context, id string, loginAt time.Time) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE federated_identities SET last_login_at = $1 WHERE id = $2`, loginAt, id)
+	if err != nil {
+		return fmt.Errorf("更新身份关联登录时间失败: %w", err)
+	}
+	return expectAffected(result)
+}
+
+// DeleteIdentity 删除用户的身份关联
+func (r *SQLIdentityProviderRepository) DeleteIdentity(ctx context.Context, userID, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM federated_identities WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("删除身份关联失败: %w", err)
+	}
+	return expectAffected(result)
+}
+
+// scanIdentityProvider 将查询结果扫描为身份提供方
+func scanIdentityProvider(row rowScanner) (*models.IdentityProvider, error) {
+	var provider models.IdentityProvider
+	var scopes string
+	err := row.Scan(&provider.ID, &provider.TenantID, &provider.Name, &provider.DisplayName, &provider.Issuer,
+		&provider.ClientID, &provider.ClientSecretEncrypted, &scopes, &provider.EmailClaim, &provider.EmailVerifiedClaim,
+		&provider.TrustEmail, &provider.AutoLink, &provider.AllowSignup, &provider.Enabled, &provider.CreatedAt, &provider.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	provider.Scopes = strings.Fields(scopes)
+	return &provider, nil
+}
+
+// scanFederatedIdentity 将查询结果扫描为身份关联
+func scanFederatedIdentity(row rowScanner) (*models.FederatedIdentity, error) {
+	var identity models.FederatedIdentity
+	var lastLoginAt sql.NullTime
+	err := row.Scan(&identity.ID, &identity.TenantID, &identity.ProviderID, &identity.Subject, &identity.UserID,
+		&identity.Email, &identity.CreatedAt, &lastLoginAt)
+	if err != nil {
+		return nil, err
+	}
+	if lastLoginAt.Valid {
+		identity.LastLoginAt = &lastLoginAt.Time
+	}
+	return &identity, nil
+}
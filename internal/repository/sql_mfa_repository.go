@@ -0,0 +1,156 @@
+// repository/sql_mfa_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"gin_saas_auth/internal/models"
+)
+
+// SQLMFARepository 基于 database/sql 的多因素认证存储（PostgreSQL 语法）
+// 表结构见 migrations/0003_create_mfa.sql
+type SQLMFARepository struct {
+	db *sql.DB
+}
+
+// NewSQLMFARepository 创建 SQL 多因素认证存储
+func NewSQLMFARepository(db *sql.DB) *SQLMFARepository {
+	return &SQLMFARepository{db: db}
+}
+
+// GetTOTP 查询用户的 TOTP 绑定
+func (r *SQLMFARepository) GetTOTP(ctx context.Context, userID string) (*models.TOTPEnrollment, error) {
+	enrollment := &models.TOTPEnrollment{UserID: userID}
+	var confirmedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx,
+		`SELECT secret_encrypted, confirmed, confirmed_at, last_used_step, created_at FROM totp_enrollments WHERE user_id = $1`,
+		userID,
+	).Scan(&enrollment.SecretEncrypted, &enrollment.Confirmed, &confirmedAt, &enrollment.LastUsedStep, &enrollment.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("查询 TOTP 绑定失败: %w", err)
+	}
+	if confirmedAt.Valid {
+		enrollment.ConfirmedAt = &confirmedAt.Time
+	}
+	return enrollment, nil
+}
+
+// SaveTOTP 保存或覆盖用户的 TOTP 绑定
+func (r *SQLMFARepository) SaveTOTP(ctx context.Context, enrollment *models.TOTPEnrollment) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO totp_enrollments (user_id, secret_encrypted, confirmed, confirmed_at, last_used_step, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 ON CONFLICT (user_id) DO UPDATE SET
+		     secret_encrypted = EXCLUDED.secret_encrypted,
+		     confirmed = EXCLUDED.confirmed,
+		     confirmed_at = EXCLUDED.confirmed_at,
+		     last_used_step = EXCLUDED.last_used_step,
+		     created_at = EXCLUDED.created_at`,
+		enrollment.UserID, enrollment.SecretEncrypted, enrollment.Confirmed, enrollment.ConfirmedAt,
+		enrollment.LastUsedStep, enrollment.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("保存 TOTP 绑定失败: %w", err)
+	}
+	return nil
+}
+
+// ConfirmTOTP 将 TOTP 绑定标记为已确认
+func (r *SQLMFARepository) ConfirmTOTP(ctx context.Context, userID string, step int64, confirmedAt time.Time) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE totp_enrollments SET confirmed = TRUE, confirmed_at = $1, last_used_step = $2 WHERE user_id = $3`,
+		confirmedAt, step, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("确认 TOTP 绑定失败: %w", err)
+	}
+	return expectAffected(result)
+}
+
+// AdvanceTOTPStep 更新最近使用的时间步
+func (r *SQLMFARepository) AdvanceTOTPStep(ctx context.Context, userID string, step int64) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE totp_enrollments SET last_used_step = $1 WHERE user_id = $2 AND last_used_step < $1`,
+		step, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("更新 TOTP 时间步失败: %w", err)
+	}
+	return expectAffected(result)
+}
+
+// DeleteTOTP 删除用户的 TOTP 绑定及全部恢复码
+func (r *SQLMFARepository) DeleteTOTP(ctx context.Context, userID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("删除恢复码失败: %w", err)
+	}
+	result, err := tx.ExecContext(ctx, `DELETE FROM totp_enrollments WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("删除 TOTP 绑定失败: %w", err)
+	}
+	if err := expectAffected(result); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReplaceRecoveryCodes 替换用户的全部恢复码
+func (r *SQLMFARepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codes []*models.RecoveryCode) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("删除恢复码失败: %w", err)
+	}
+	for _, code := range codes {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO recovery_codes (user_id, code_hash, created_at) VALUES ($1, $2, $3)`,
+			userID, code.CodeHash, code.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("保存恢复码失败: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// ConsumeRecoveryCode 使用一个恢复码
+func (r *SQLMFARepository) ConsumeRecoveryCode(ctx context.Context, userID, codeHash string, usedAt time.Time) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE recovery_codes SET used_at = $1 WHERE user_id = $2 AND code_hash = $3 AND used_at IS NULL`,
+		usedAt, userID, codeHash,
+	)
+	if err != nil {
+		return fmt.Errorf("使用恢复码失败: %w", err)
+	}
+	return expectAffected(result)
+}
+
+// CountRecoveryCodes 统计用户未使用的恢复码数量
+func (r *SQLMFARepository) CountRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM recovery_codes WHERE user_id = $1 AND used_at IS NULL`,
+		userID,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("统计恢复码失败: %w", err)
+	}
+	return count, nil
+}
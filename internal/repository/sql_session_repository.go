@@ -0,0 +1,189 @@
+// repository/sql_session_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gin_saas_auth/internal/models"
+)
+
+// SQLSessionRepository 基于 database/sql 的会话存储（PostgreSQL 语法）
+// 表结构见 migrations/0002_create_sessions.sql
+type SQLSessionRepository struct {
+	db *sql.DB
+}
+
+// NewSQLSessionRepository 创建 SQL 会话存储
+func NewSQLSessionRepository(db *sql.DB) *SQLSessionRepository {
+	return &SQLSessionRepository{db: db}
+}
+
+const sessionColumns = `id, user_id, user_agent, client_ip, amr, created_at, last_used_at, expires_at, revoked_at`
+
+// CreateSession 创建会话
+func (r *SQLSessionRepository) CreateSession(ctx context.Context, session *models.Session) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO sessions (`+sessionColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		session.ID, session.UserID, session.UserAgent, session.ClientIP, strings.Join(session.AMR, ","),
+		session.CreatedAt, session.LastUsedAt, session.ExpiresAt, session.RevokedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicate
+		}
+		return fmt.Errorf("创建会话失败: %w", err)
+	}
+	return nil
+}
+
+// GetSession 根据 ID 查询会话
+func (r *SQLSessionRepository) GetSession(ctx context.Context, id string) (*models.Session, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+sessionColumns+` FROM sessions WHERE id = $1`, id)
+	session, err := scanSession(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("查询会话失败: %w", err)
+	}
+	return session, nil
+}
+
+// ListSessionsByUser 查询用户所有未撤销的会话
+func (r *SQLSessionRepository) ListSessionsByUser(ctx context.Context, userID string) ([]*models.Session, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+sessionColumns+` FROM sessions WHERE user_id = $1 AND revoked_at IS NULL ORDER BY created_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("查询会话列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*models.Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("读取会话失败: %w", err)
+		}
+		result = append(result, session)
+	}
+	return result, rows.Err()
+}
+
+// TouchSession 更新会话最近使用时间
+func (r *SQLSessionRepository) TouchSession(ctx context.Context, id string, usedAt time.Time, userAgent, clientIP string) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE sessions SET last_used_at = $1, user_agent = $2, client_ip = $3 WHERE id = $4`,
+		usedAt, userAgent, clientIP, id,
+	)
+	if err != nil {
+		return fmt.Errorf("更新会话失败: %w", err)
+	}
+	return expectAffected(result)
+}
+
+// RevokeSession 撤销会话
+func (r *SQLSessionRepository) RevokeSession(ctx context.Context, id string, revokedAt time.Time) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE sessions SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`,
+		revokedAt, id,
+	)
+	if err != nil {
+		return fmt.Errorf("撤销会话失败: %w", err)
+	}
+	return expectAffected(result)
+}
+
+// RevokeUserSessions 撤销用户的所有会话
+func (r *SQLSessionRepository) RevokeUserSessions(ctx context.Context, userID string, revokedAt time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE sessions SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL`,
+		revokedAt, userID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("撤销用户会话失败: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("获取影响行数失败: %w", err)
+	}
+	return int(affected), nil
+}
+
+// SaveRefreshToken 保存刷新令牌
+func (r *SQLSessionRepository) SaveRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO refresh_tokens (id, session_id, user_id, token_hash, expires_at, used_at, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		token.ID, token.SessionID, token.UserID, token.TokenHash, token.ExpiresAt, token.UsedAt, token.CreatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicate
+		}
+		return fmt.Errorf("保存刷新令牌失败: %w", err)
+	}
+	return nil
+}
+
+// GetRefreshToken 根据摘要查询刷新令牌
+func (r *SQLSessionRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	token := &models.RefreshToken{TokenHash: tokenHash}
+	var usedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx,
+		`SELECT id, session_id, user_id, expires_at, used_at, created_at FROM refresh_tokens WHERE token_hash = $1`,
+		tokenHash,
+	).Scan(&token.ID, &token.SessionID, &token.UserID, &token.ExpiresAt, &usedAt, &token.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("查询刷新令牌失败: %w", err)
+	}
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+	return token, nil
+}
+
+// MarkRefreshTokenUsed 将未使用的令牌标记为已使用
+func (r *SQLSessionRepository) MarkRefreshTokenUsed(ctx context.Context, id string, usedAt time.Time) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE refresh_tokens SET used_at = $1 WHERE id = $2 AND used_at IS NULL`,
+		usedAt, id,
+	)
+	if err != nil {
+		return fmt.Errorf("标记刷新令牌失败: %w", err)
+	}
+	return expectAffected(result)
+}
+
+// rowScanner 兼容 *sql.Row 与 *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanSession 将查询结果扫描为会话
+func scanSession(row rowScanner) (*models.Session, error) {
+	var session models.Session
+	var amr string
+	var revokedAt sql.NullTime
+	err := row.Scan(&session.ID, &session.UserID, &session.UserAgent, &session.ClientIP, &amr,
+		&session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+	if amr != "" {
+		session.AMR = strings.Split(amr, ",")
+	}
+	if revokedAt.Valid {
+		session.RevokedAt = &revokedAt.Time
+	}
+	return &session, nil
+}
@@ -0,0 +1,142 @@
+// repository/sql_tenant_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"gin_saas_auth/internal/models"
+)
+
+// SQLTenantRepository 基于 database/sql 的租户存储（PostgreSQL 语法）
+// 表结构见 migrations/0004_create_tenants.sql 和 migrations/0009_create_password_policies.sql
+type SQLTenantRepository struct {
+	db *sql.DB
+}
+
+// NewSQLTenantRepository 创建 SQL 租户存储
+func NewSQLTenantRepository(db *sql.DB) *SQLTenantRepository {
+	return &SQLTenantRepository{db: db}
+}
+
+const tenantColumns = `id, name, status, created_at, updated_at`
+
+// CreateTenant 创建租户
+func (r *SQLTenantRepository) CreateTenant(ctx context.Context, tenant *models.Tenant) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO tenants (`+tenantColumns+`) VALUES ($1, $2, $3, $4, $5)`,
+		tenant.ID, tenant.Name, string(tenant.Status), tenant.CreatedAt, tenant.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicate
+		}
+		return fmt.Errorf("创建租户失败: %w", err)
+	}
+	return nil
+}
+
+// GetTenant 根据 ID 查询租户
+func (r *SQLTenantRepository) GetTenant(ctx context.Context, id string) (*models.Tenant, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+tenantColumns+` FROM tenants WHERE id = $1`, id)
+	tenant, err := scanTenant(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("查询租户失败: %w", err)
+	}
+	return tenant, nil
+}
+
+// ListTenants 查询全部租户
+func (r *SQLTenantRepository) ListTenants(ctx context.Context) ([]*models.Tenant, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+tenantColumns+` FROM tenants ORDER BY created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("查询租户列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*models.Tenant
+	for rows.Next() {
+		tenant, err := scanTenant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("读取租户失败: %w", err)
+		}
+		result = append(result, tenant)
+	}
+	return result, rows.Err()
+}
+
+// UpdateTenantStatus 更新租户状态
+func (r *SQLTenantRepository) UpdateTenantStatus(ctx context.Context, id string, status models.TenantStatus, updatedAt time.Time) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE tenants SET status = $1, updated_at = $2 WHERE id = $3`,
+		string(status), updatedAt, id,
+	)
+	if err != nil {
+		return fmt.Errorf("更新租户状态失败: %w", err)
+	}
+	return expectAffected(result)
+}
+
+// GetPasswordPolicy 查询租户密码策略
+func (r *SQLTenantRepository) GetPasswordPolicy(ctx context.Context, tenantID string) (*models.PasswordPolicy, error) {
+	policy := &models.PasswordPolicy{TenantID: tenantID, Custom: true}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT min_length, max_length, require_upper, require_lower, require_digit, require_symbol,
+		 check_breached, history_size, max_age_days, updated_at FROM password_policies WHERE tenant_id = $1`,
+		tenantID,
+	).Scan(&policy.MinLength, &policy.MaxLength, &policy.RequireUpper, &policy.RequireLower, &policy.RequireDigit,
+		&policy.RequireSymbol, &policy.CheckBreached, &policy.HistorySize, &policy.MaxAgeDays, &policy.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("查询密码策略失败: %w", err)
+	}
+	return policy, nil
+}
+
+// SavePasswordPolicy 保存租户密码策略
+func (r *SQLTenantRepository) SavePasswordPolicy(ctx context.Context, policy *models.PasswordPolicy) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO password_policies (tenant_id, min_length, max_length, require_upper, require_lower, require_digit,
+		 require_symbol, check_breached, history_size, max_age_days, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		 ON CONFLICT (tenant_id) DO UPDATE SET min_length = EXCLUDED.min_length, max_length = EXCLUDED.max_length,
+		 require_upper = EXCLUDED.require_upper, require_lower = EXCLUDED.require_lower,
+		 require_digit = EXCLUDED.require_digit, require_symbol = EXCLUDED.require_symbol,
+		 check_breached = EXCLUDED.check_breached, history_size = EXCLUDED.history_size,
+		 max_age_days = EXCLUDED.max_age_days, updated_at = EXCLUDED.updated_at`,
+		policy.TenantID, policy.MinLength, policy.MaxLength, policy.RequireUpper, policy.RequireLower, policy.RequireDigit,
+		policy.RequireSymbol, policy.CheckBreached, policy.HistorySize, policy.MaxAgeDays, policy.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("保存密码策略失败: %w", err)
+	}
+	return nil
+}
+
+// DeletePasswordPolicy 删除租户密码策略
+func (r *SQLTenantRepository) DeletePasswordPolicy(ctx context.Context, tenantID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM password_policies WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		return fmt.Errorf("删除密码策略失败: %w", err)
+	}
+	return expectAffected(result)
+}
+
+// scanTenant 将查询结果扫描为租户
+func scanTenant(row rowScanner) (*models.Tenant, error) {
+	var tenant models.Tenant
+	var status string
+	if err := row.Scan(&tenant.ID, &tenant.Name, &status, &tenant.CreatedAt, &tenant.UpdatedAt); err != nil {
+		return nil, err
+	}
+	tenant.Status = models.TenantStatus(status)
+	return &tenant, nil
+}
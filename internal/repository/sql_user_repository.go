@@ -0,0 +1,221 @@
+// repository/sql_user_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"gin_saas_auth/internal/models"
+)
+
+// SQLUserRepository 基于 database/sql 的用户存储（PostgreSQL 语法）
+// 表结构见 migrations/0001_create_users.sql 及后续迁移
+type SQLUserRepository struct {
+	db *sql.DB
+}
+
+// NewSQLUserRepository 创建 SQL 用户存储
+func NewSQLUserRepository(db *sql.DB) *SQLUserRepository {
+	return &SQLUserRepository{db: db}
+}
+
+const userColumns = `id, tenant_id, email, email_verified_at, phone, phone_verified_at, password_hash, status, password_changed_at, created_at, updated_at`
+
+// Create 创建用户
+func (r *SQLUserRepository) Create(ctx context.Context, user *models.User) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO users (`+userColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		user.ID, user.TenantID, user.Email, user.EmailVerifiedAt, user.Phone, user.PhoneVerifiedAt,
+		user.PasswordHash, string(user.Status),
+		user.PasswordChangedAt, user.CreatedAt, user.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicate
+		}
+		return fmt.Errorf("创建用户失败: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据 ID 查询用户
+func (r *SQLUserRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`, id)
+	return scanUser(row)
+}
+
+// GetByEmail 根据邮箱查询用户
+func (r *SQLUserRepository) GetByEmail(ctx context.Context, tenantID, email string) (*models.User, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE tenant_id = $1 AND email = $2`, tenantID, email)
+	return scanUser(row)
+}
+
+// GetByPhone 根据手机号查询用户
+func (r *SQLUserRepository) GetByPhone(ctx context.Context, tenantID, phone string) (*models.User, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE tenant_id = $1 AND phone = $2`, tenantID, phone)
+	return scanUser(row)
+}
+
+// UpdatePassword 更新用户密码哈希
+func (r *SQLUserRepository) UpdatePassword(ctx context.Context, id, passwordHash string, changedAt time.Time) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE users SET password_hash = $1, password_changed_at = $2, updated_at = $2 WHERE id = $3`,
+		passwordHash, changedAt, id,
+	)
+	if err != nil {
+		return fmt.Errorf("更新用户密码失败: %w", err)
+	}
+	return expectAffected(result)
+}
+
+// MarkEmailVerified 标记邮箱已验证
+func (r *SQLUserRepository) MarkEmailVerified(ctx context.Context, id string, verifiedAt time.Time) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE users SET email_verified_at = COALESCE(email_verified_at, $1), updated_at = $1 WHERE id = $2`,
+		verifiedAt, id,
+	)
+	if err != nil {
+		return fmt.Errorf("更新邮箱验证状态失败: %w", err)
+	}
+	return expectAffected(result)
+}
+
+// UpdatePhone 保存验证通过的手机号
+func (r *SQLUserRepository) UpdatePhone(ctx context.Context, id, phone string, verifiedAt time.Time) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE users SET phone = $1, phone_verified_at = $2, updated_at = $2 WHERE id = $3`,
+		phone, verifiedAt, id,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicate
+		}
+		return fmt.Errorf("更新手机号失败: %w", err)
+	}
+	return expectAffected(result)
+}
+
+// SaveResetToken 保存重置令牌
+func (r *SQLUserRepository) SaveResetToken(ctx context.Context, token *models.PasswordResetToken) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO password_reset_tokens (token_hash, user_id, expires_at, created_at) VALUES ($1, $2, $3, $4)`,
+		token.TokenHash, token.UserID, token.ExpiresAt, token.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("保存重置令牌失败: %w", err)
+	}
+	return nil
+}
+
+// ConsumeResetToken 标记令牌已使用并返回令牌
+func (r *SQLUserRepository) ConsumeResetToken(ctx context.Context, tokenHash string, now time.Time) (*models.PasswordResetToken, error) {
+	token := &models.PasswordResetToken{TokenHash: tokenHash}
+	var usedAt time.Time
+	err := r.db.QueryRowContext(ctx,
+		`UPDATE password_reset_tokens SET used_at = $1
+		 WHERE token_hash = $2 AND used_at IS NULL AND expires_at > $1
+		 RETURNING user_id, expires_at, used_at, created_at`,
+		now, tokenHash,
+	).Scan(&token.UserID, &token.ExpiresAt, &usedAt, &token.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("使用重置令牌失败: %w", err)
+	}
+	token.UsedAt = &usedAt
+	return token, nil
+}
+
+// AddPasswordHistory 记录被替换的密码哈希，并删除超出保留数量的旧记录
+func (r *SQLUserRepository) AddPasswordHistory(ctx context.Context, userID, passwordHash string, replacedAt time.Time, keep int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("记录历史密码失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO password_history (user_id, password_hash, replaced_at) VALUES ($1, $2, $3)`,
+		userID, passwordHash, replacedAt,
+	); err != nil {
+		return fmt.Errorf("记录历史密码失败: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM password_history WHERE user_id = $1 AND id NOT IN (
+		 SELECT id FROM password_history WHERE user_id = $1 ORDER BY replaced_at DESC, id DESC LIMIT $2)`,
+		userID, keep,
+	); err != nil {
+		return fmt.Errorf("清理历史密码失败: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ListPasswordHistory 查询最近的历史密码哈希
+func (r *SQLUserRepository) ListPasswordHistory(ctx context.Context, userID string, limit int) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT password_hash FROM password_history WHERE user_id = $1 ORDER BY replaced_at DESC, id DESC LIMIT $2`,
+		userID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("查询历史密码失败: %w", err)
+	}
+	defer rows.Close()
+
+	var result []string
+	for rows.Next() {
+		var passwordHash string
+		if err := rows.Scan(&passwordHash); err != nil {
+			return nil, fmt.Errorf("读取历史密码失败: %w", err)
+		}
+		result = append(result, passwordHash)
+	}
+	return result, rows.Err()
+}
+
+// scanUser 将查询结果扫描为用户
+func scanUser(row *sql.Row) (*models.User, error) {
+	var user models.User
+	var status string
+	var emailVerifiedAt, phoneVerifiedAt sql.NullTime
+	err := row.Scan(&user.ID, &user.TenantID, &user.Email, &emailVerifiedAt, &user.Phone, &phoneVerifiedAt,
+		&user.PasswordHash, &status, &user.PasswordChangedAt, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("查询用户失败: %w", err)
+	}
+	user.Status = models.UserStatus(status)
+	if emailVerifiedAt.Valid {
+		user.EmailVerifiedAt = &emailVerifiedAt.Time
+	}
+	if phoneVerifiedAt.Valid {
+		user.PhoneVerifiedAt = &phoneVerifiedAt.Time
+	}
+	return &user, nil
+}
+
+// expectAffected 检查更新语句是否命中记录
+func expectAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("获取影响行数失败: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// isUniqueViolation 判断是否为唯一约束冲突（PostgreSQL 错误码 23505）
+func isUniqueViolation(err error) bool {
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) {
+		return stateErr.SQLState() == "23505"
+	}
+	return false
+}
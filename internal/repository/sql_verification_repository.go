@@ -0,0 +1,92 @@
+// repository/sql_verification_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"gin_saas_auth/internal/models"
+)
+
+// SQLVerificationRepository 基于 database/sql 的验证码存储（PostgreSQL 语法）
+// 表结构见 migrations/0007_create_verification_codes.sql
+type SQLVerificationRepository struct {
+	db *sql.DB
+}
+
+// NewSQLVerificationRepository 创建 SQL 验证码存储
+func NewSQLVerificationRepository(db *sql.DB) *SQLVerificationRepository {
+	return &SQLVerificationRepository{db: db}
+}
+
+// Save 保存新发送的验证码
+func (r *SQLVerificationRepository) Save(ctx context.Context, code *models.VerificationCode) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO verification_codes (id, tenant_id, user_id, channel, destination, purpose, code_hash, attempts, expires_at, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		code.ID, code.TenantID, code.UserID, code.Channel, code.Destination, code.Purpose,
+		code.CodeHash, code.Attempts, code.ExpiresAt, code.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("保存验证码失败: %w", err)
+	}
+	return nil
+}
+
+// GetLatest 查询最近一次发送的验证码
+func (r *SQLVerificationRepository) GetLatest(ctx context.Context, tenantID, channel, destination, purpose string) (*models.VerificationCode, error) {
+	code := &models.VerificationCode{
+		TenantID:    tenantID,
+		Channel:     channel,
+		Destination: destination,
+		Purpose:     purpose,
+	}
+	var consumedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx,
+		`SELECT id, user_id, code_hash, attempts, expires_at, consumed_at, created_at FROM verification_codes
+		 WHERE tenant_id = $1 AND channel = $2 AND destination = $3 AND purpose = $4
+		 ORDER BY created_at DESC LIMIT 1`,
+		tenantID, channel, destination, purpose,
+	).Scan(&code.ID, &code.UserID, &code.CodeHash, &code.Attempts, &code.ExpiresAt, &consumedAt, &code.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("查询验证码失败: %w", err)
+	}
+	if consumedAt.Valid {
+		code.ConsumedAt = &consumedAt.Time
+	}
+	return code, nil
+}
+
+// IncrementAttempts 校验次数加一
+func (r *SQLVerificationRepository) IncrementAttempts(ctx context.Context, id string) (int, error) {
+	var attempts int
+	err := r.db.QueryRowContext(ctx,
+		`UPDATE verification_codes SET attempts = attempts + 1 WHERE id = $1 RETURNING attempts`,
+		id,
+	).Scan(&attempts)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, fmt.Errorf("更新验证码校验次数失败: %w", err)
+	}
+	return attempts, nil
+}
+
+// Consume 标记验证码已使用
+func (r *SQLVerificationRepository) Consume(ctx context.Context, id string, consumedAt time.Time) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE verification_codes SET consumed_at = $1 WHERE id = $2 AND consumed_at IS NULL`,
+		consumedAt, id,
+	)
+	if err != nil {
+		return fmt.Errorf("使用验证码失败: %w", err)
+	}
+	return expectAffected(result)
+}
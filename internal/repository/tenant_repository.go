@@ -0,0 +1,31 @@
+// repository/tenant_repository.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gin_saas_auth/internal/models"
+)
+
+// TenantRepository 租户存储接口
+type TenantRepository interface {
+	// CreateTenant 创建租户，ID 重复时返回 ErrDuplicate
+	CreateTenant(ctx context.Context, tenant *models.Tenant) error
+	// GetTenant 根据 ID 查询租户
+	GetTenant(ctx context.Context, id string) (*models.Tenant, error)
+	// ListTenants 查询全部租户，按创建时间排序
+	ListTenants(ctx context.Context) ([]*models.Tenant, error)
+	// UpdateTenantStatus 更新租户状态
+	UpdateTenantStatus(ctx context.Context, id string, status models.TenantStatus, updatedAt time.Time) error
+}
+
+// PasswordPolicyRepository 租户密码策略存储接口
+type PasswordPolicyRepository interface {
+	// GetPasswordPolicy 查询租户自定义的密码策略，未配置时返回 ErrNotFound
+	GetPasswordPolicy(ctx context.Context, tenantID string) (*models.PasswordPolicy, error)
+	// SavePasswordPolicy 保存租户密码策略，已存在时覆盖
+	SavePasswordPolicy(ctx context.Context, policy *models.PasswordPolicy) error
+	// DeletePasswordPolicy 删除租户密码策略，恢复使用默认策略，未配置时返回 ErrNotFound
+	DeletePasswordPolicy(ctx context.Context, tenantID string) error
+}
@@ -0,0 +1,43 @@
+// repository/user_repository.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gin_saas_auth/internal/models"
+)
+
+// UserRepository 用户存储接口
+type UserRepository interface {
+	// Create 创建用户，同一租户下邮箱重复时返回 ErrDuplicate
+	Create(ctx context.Context, user *models.User) error
+	// GetByID 根据 ID 查询用户
+	GetByID(ctx context.Context, id string) (*models.User, error)
+	// GetByEmail 根据租户和邮箱查询用户（邮箱已规范化为小写）
+	GetByEmail(ctx context.Context, tenantID, email string) (*models.User, error)
+	// GetByPhone 根据租户和已验证的手机号查询用户
+	GetByPhone(ctx context.Context, tenantID, phone string) (*models.User, error)
+	// UpdatePassword 更新用户密码哈希
+	UpdatePassword(ctx context.Context, id, passwordHash string, changedAt time.Time) error
+	// MarkEmailVerified 标记邮箱已验证，已验证时保留原验证时间
+	MarkEmailVerified(ctx context.Context, id string, verifiedAt time.Time) error
+	// UpdatePhone 保存验证通过的手机号，同一租户下手机号已被其他用户使用时返回 ErrDuplicate
+	UpdatePhone(ctx context.Context, id, phone string, verifiedAt time.Time) error
+}
+
+// PasswordResetRepository 密码重置令牌存储接口
+type PasswordResetRepository interface {
+	// SaveResetToken 保存重置令牌
+	SaveResetToken(ctx context.Context, token *models.PasswordResetToken) error
+	// ConsumeResetToken 原子地标记令牌已使用并返回令牌，令牌不存在、已使用或已过期时返回 ErrNotFound
+	ConsumeResetToken(ctx context.Context, tokenHash string, now time.Time) (*models.PasswordResetToken, error)
+}
+
+// PasswordHistoryRepository 历史密码存储接口，只保存密码哈希
+type PasswordHistoryRepository interface {
+	// AddPasswordHistory 记录被替换的密码哈希，只保留最近 keep 条
+	AddPasswordHistory(ctx context.Context, userID, passwordHash string, replacedAt time.Time, keep int) error
+	// ListPasswordHistory 查询最近 limit 条历史密码哈希，按替换时间倒序
+	ListPasswordHistory(ctx context.Context, userID string, limit int) ([]string, error)
+}
@@ -0,0 +1,21 @@
+// repository/verification_repository.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gin_saas_auth/internal/models"
+)
+
+// VerificationRepository 验证码存储接口
+type VerificationRepository interface {
+	// Save 保存新发送的验证码
+	Save(ctx context.Context, code *models.VerificationCode) error
+	// GetLatest 查询租户下指定渠道、目标和用途最近一次发送的验证码（包括已使用的），不存在时返回 ErrNotFound
+	GetLatest(ctx context.Context, tenantID, channel, destination, purpose string) (*models.VerificationCode, error)
+	// IncrementAttempts 校验次数加一并返回累计次数
+	IncrementAttempts(ctx context.Context, id string) (int, error)
+	// Consume 原子地标记验证码已使用，已使用时返回 ErrNotFound
+	Consume(ctx context.Context, id string, consumedAt time.Time) error
+}
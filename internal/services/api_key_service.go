@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/i18n"
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/repository"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// API 密钥相关错误
+var (
+	// ErrInvalidAPIKey API 密钥无效、已撤销或已过期
+	ErrInvalidAPIKey = utils.NewAppError("INVALID_API_KEY", http.StatusUnauthorized, "API 密钥无效或已过期")
+	// ErrAPIKeyNotFound API 密钥不存在
+	ErrAPIKeyNotFound = utils.NewAppError("API_KEY_NOT_FOUND", http.StatusNotFound, "API 密钥不存在")
+	// ErrAPIKeyRateLimited API 密钥请求过于频繁
+	ErrAPIKeyRateLimited = utils.NewAppError("API_KEY_RATE_LIMITED", http.StatusTooManyRequests, "API 密钥请求过于频繁，请稍后再试")
+	// ErrAPIKeyLimitExceeded 用户持有的有效密钥数量已达上限
+	ErrAPIKeyLimitExceeded = utils.NewAppError("API_KEY_LIMIT_EXCEEDED", http.StatusConflict, "API 密钥数量已达上限")
+	// ErrInvalidAPIKeyRequest 创建参数不合法
+	ErrInvalidAPIKeyRequest = utils.NewAppError("INVALID_API_KEY_REQUEST", http.StatusBadRequest, "API 密钥参数无效")
+)
+
+const (
+	// apiKeyScheme 密钥前缀标识，便于在日志和代码仓库中识别泄露的密钥
+	apiKeyScheme = "sk"
+	// apiKeyPrefixBytes 查找前缀的随机字节数
+	apiKeyPrefixBytes = 6
+	// apiKeySecretBytes 密钥主体的随机字节数
+	apiKeySecretBytes = 32
+	// apiKeyTouchInterval 最近使用时间的最小更新间隔，避免每个请求都写库
+	apiKeyTouchInterval = time.Minute
+	// apiKeyRateWindow 限流窗口
+	apiKeyRateWindow = time.Minute
+)
+
+// CreateAPIKeyInput 创建 API 密钥的参数
+type CreateAPIKeyInput struct {
+	Name      string
+	Scopes    []string
+	RateLimit *int // 为空时使用默认值
+	ExpiresAt *time.Time
+}
+
+// CreatedAPIKey 新创建的密钥，明文密钥只在创建时返回一次
+type CreatedAPIKey struct {
+	*models.APIKey
+	Key string `json:"key"`
+}
+
+// APIKeyService API 密钥服务
+type APIKeyService struct {
+	keys             repository.APIKeyRepository
+	users            repository.UserRepository
+	rbac             *RBACService
+	defaultRateLimit int
+	maxPerUser       int
+}
+
+// NewAPIKeyService 创建 API 密钥服务
+func NewAPIKeyService(cfg *config.Config, keys repository.APIKeyRepository, users repository.UserRepository, rbac *RBACService) *APIKeyService {
+	return &APIKeyService{
+		keys:             keys,
+		users:            users,
+		rbac:             rbac,
+		defaultRateLimit: cfg.Auth.APIKeyDefaultRateLimit,
+		maxPerUser:       cfg.Auth.APIKeyMaxPerUser,
+	}
+}
+
+// Create 为用户创建 API 密钥，密钥归属用户所在租户，权限范围不能超出用户在该租户拥有的权限
+func (s *APIKeyService) Create(ctx context.Context, user *models.User, input CreateAPIKeyInput) (*CreatedAPIKey, error) {
+	now := time.Now()
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len(name) > 100 {
+		return nil, ErrInvalidAPIKeyRequest.WithDetails(i18n.NewMessage("api_key.invalid_name", i18n.Params{"max": 100}))
+	}
+	if len(input.Scopes) == 0 {
+		return nil, ErrInvalidAPIKeyRequest.WithDetails(i18n.NewMessage("api_key.scopes_required"))
+	}
+	for _, scope := range input.Scopes {
+		if validatePermission(scope) != nil {
+			return nil, ErrInvalidAPIKeyRequest.WithDetails(i18n.NewMessage("api_key.invalid_scope", i18n.Params{"scope": scope}))
+		}
+		if !s.rbac.HasPermission(user.ID, user.TenantID, scope) {
+			return nil, ErrInvalidAPIKeyRequest.WithDetails(i18n.NewMessage("api_key.scope_not_granted", i18n.Params{"scope": scope}))
+		}
+	}
+	rateLimit := s.defaultRateLimit
+	if input.RateLimit != nil {
+		if *input.RateLimit < 0 {
+			return nil, ErrInvalidAPIKeyRequest.WithDetails(i18n.NewMessage("api_key.negative_rate_limit"))
+		}
+		rateLimit = *input.RateLimit
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(now) {
+		return nil, ErrInvalidAPIKeyRequest.WithDetails(i18n.NewMessage("api_key.expires_in_past"))
+	}
+
+	if s.maxPerUser > 0 {
+		existing, err := s.keys.ListAPIKeysByUser(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		active := 0
+		for _, key := range existing {
+			if key.IsActive(now) {
+				active++
+			}
+		}
+		if active >= s.maxPerUser {
+			return nil, ErrAPIKeyLimitExceeded
+		}
+	}
+
+	prefix, err := utils.GenerateRandomHex(apiKeyPrefixBytes)
+	if err != nil {
+		return nil, fmt.Errorf("生成密钥前缀失败: %w", err)
+	}
+	secret, err := utils.GenerateRandomToken(apiKeySecretBytes)
+	if err != nil {
+		return nil, fmt.Errorf("生成密钥失败: %w", err)
+	}
+	rawKey := apiKeyScheme + "_" + prefix + "_" + secret
+
+	key := &models.APIKey{
+		ID:        utils.NewID(),
+		TenantID:  user.TenantID,
+		UserID:    user.ID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   utils.HashToken(rawKey),
+		Scopes:    input.Scopes,
+		RateLimit: rateLimit,
+		ExpiresAt: input.ExpiresAt,
+		CreatedAt: now,
+	}
+	if err := s.keys.CreateAPIKey(ctx, key); err != nil {
+		return nil, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":   user.ID,
+		"tenant_id": user.TenantID,
+		"key_id":    key.ID,
+		"prefix":    prefix,
+	}).Info("API 密钥创建成功")
+	return &CreatedAPIKey{APIKey: key, Key: rawKey}, nil
+}
+
+// List 查询用户的所有密钥
+func (s *APIKeyService) List(ctx context.Context, userID string) ([]*models.APIKey, error) {
+	return s.keys.ListAPIKeysByUser(ctx, userID)
+}
+
+// Get 查询用户的指定密钥，不属于该用户时视为不存在
+func (s *APIKeyService) Get(ctx context.Context, userID, id string) (*models.APIKey, error) {
+	key, err := s.keys.GetAPIKey(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	if key.UserID != userID {
+		return nil, ErrAPIKeyNotFound
+	}
+	return key, nil
+}
+
+// Revoke 撤销用户的指定密钥
+func (s *APIKeyService) Revoke(ctx context.Context, userID, id string) error {
+	if _, err := s.Get(ctx, userID, id); err != nil {
+		return err
+	}
+	if err := s.keys.RevokeAPIKey(ctx, id, time.Now()); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrAPIKeyNotFound
+		}
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id": userID,
+		"key_id":  id,
+	}).Info("API 密钥已撤销")
+	return nil
+}
+
+// Authenticate 校验明文密钥：按前缀查找后以常量时间比较摘要，并校验密钥和所属用户的状态
+func (s *APIKeyService) Authenticate(ctx context.Context, rawKey string) (*models.APIKey, error) {
+	now := time.Now()
+
+	scheme, rest, ok := strings.Cut(rawKey, "_")
+	if !ok || scheme != apiKeyScheme {
+		return nil, ErrInvalidAPIKey
+	}
+	prefix, _, ok := strings.Cut(rest, "_")
+	if !ok || prefix == "" {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.keys.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(utils.HashToken(rawKey))) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+	if !key.IsActive(now) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	user, err := s.users.GetByID(ctx, key.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	if !user.IsActive() {
+		return nil, ErrUserDisabled
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.keys.TouchAPIKey(ctx, key.ID, now); err != nil {
+			logrus.WithError(err).WithField("key_id", key.ID).Warn("更新 API 密钥使用时间失败")
+		}
+	}
+	return key, nil
+}
+
+// RateLimitRule 密钥自身的限流规则，按密钥 ID 在限流服务中计数，Limit 为 0 表示不限制
+func (s *APIKeyService) RateLimitRule(key *models.APIKey) RateLimitRule {
+	return RateLimitRule{
+		Name:      "api_key",
+		Algorithm: RateLimitSlidingWindow,
+		Limit:     key.RateLimit,
+		Window:    apiKeyRateWindow,
+	}
+}
+
+// Claims 将密钥换算为请求上下文中的声明，主体为密钥所属用户
+func (s *APIKeyService) Claims(key *models.APIKey) *AccessClaims {
+	claims := &AccessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:       key.ID,
+			Subject:  key.UserID,
+			IssuedAt: jwt.NewNumericDate(key.CreatedAt),
+		},
+		TokenUse: tokenUseAPIKey,
+		TenantID: key.TenantID,
+		Scopes:   key.Scopes,
+	}
+	if key.ExpiresAt != nil {
+		claims.ExpiresAt = jwt.NewNumericDate(*key.ExpiresAt)
+	}
+	return claims
+}
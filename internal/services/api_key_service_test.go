@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/repository"
+)
+
+// apiKeyPolicy 用户在 acme 租户只有 users:read
+const apiKeyPolicy = `{
+  "roles": {"viewer": {"permissions": ["users:read"]}},
+  "bindings": [{"subject": "*", "role": "viewer", "tenant": "acme"}]
+}`
+
+// apiKeyFormat 明文密钥格式：sk_<12 位十六进制前缀>_<随机串>
+var apiKeyFormat = regexp.MustCompile(`^sk_[0-9a-f]{12}_[A-Za-z0-9_-]+$`)
+
+// apiKeyServiceEnv API 密钥服务测试环境
+type apiKeyServiceEnv struct {
+	service *APIKeyService
+	users   *repository.MemoryUserRepository
+	active  *models.User
+}
+
+func newAPIKeyServiceEnv(t *testing.T) *apiKeyServiceEnv {
+	t.Helper()
+	users := repository.NewMemoryUserRepository()
+	active := &models.User{ID: "user-1", TenantID: "acme", Email: "key@example.com", Status: models.UserStatusActive, CreatedAt: time.Now()}
+	if err := users.Create(context.Background(), active); err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	rbac, err := NewRBACService(writePolicy(t, apiKeyPolicy), nil)
+	if err != nil {
+		t.Fatalf("加载策略失败: %v", err)
+	}
+	cfg := &config.Config{Auth: config.AuthConfig{APIKeyDefaultRateLimit: 60, APIKeyMaxPerUser: 2}}
+	return &apiKeyServiceEnv{
+		service: NewAPIKeyService(cfg, repository.NewMemoryAPIKeyRepository(), users, rbac),
+		users:   users,
+		active:  active,
+	}
+}
+
+func (env *apiKeyServiceEnv) create(t *testing.T, user *models.User, input CreateAPIKeyInput) *CreatedAPIKey {
+	t.Helper()
+	created, err := env.service.Create(context.Background(), user, input)
+	if err != nil {
+		t.Fatalf("创建密钥失败: %v", err)
+	}
+	return created
+}
+
+func TestAPIKeyCreateValidatesInput(t *testing.T) {
+	env := newAPIKeyServiceEnv(t)
+	negative := -1
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name  string
+		input CreateAPIKeyInput
+	}{
+		{name: "empty name", input: CreateAPIKeyInput{Name: " ", Scopes: []string{"users:read"}}},
+		{name: "no scopes", input: CreateAPIKeyInput{Name: "ci"}},
+		{name: "invalid scope", input: CreateAPIKeyInput{Name: "ci", Scopes: []string{"users"}}},
+		{name: "scope not granted", input: CreateAPIKeyInput{Name: "ci", Scopes: []string{"users:write"}}},
+		{name: "negative rate limit", input: CreateAPIKeyInput{Name: "ci", Scopes: []string{"users:read"}, RateLimit: &negative}},
+		{name: "expires in past", input: CreateAPIKeyInput{Name: "ci", Scopes: []string{"users:read"}, ExpiresAt: &past}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := env.service.Create(context.Background(), env.active, tt.input); !errors.Is(err, ErrInvalidAPIKeyRequest) {
+				t.Fatalf("err = %v, want ErrInvalidAPIKeyRequest", err)
+			}
+		})
+	}
+}
+
+func TestAPIKeyCreateLimitPerUser(t *testing.T) {
+	env := newAPIKeyServiceEnv(t)
+	ctx := context.Background()
+	input := CreateAPIKeyInput{Name: "ci", Scopes: []string{"users:read"}}
+
+	first := env.create(t, env.active, input)
+	env.create(t, env.active, input)
+	if _, err := env.service.Create(ctx, env.active, input); !errors.Is(err, ErrAPIKeyLimitExceeded) {
+		t.Fatalf("超出数量上限 err = %v, want ErrAPIKeyLimitExceeded", err)
+	}
+
+	// 撤销的密钥不计入上限
+	if err := env.service.Revoke(ctx, env.active.ID, first.ID); err != nil {
+		t.Fatalf("撤销密钥失败: %v", err)
+	}
+	env.create(t, env.active, input)
+}
+
+func TestAPIKeyAuthenticate(t *testing.T) {
+	env := newAPIKeyServiceEnv(t)
+	ctx := context.Background()
+	created := env.create(t, env.active, CreateAPIKeyInput{Name: "ci", Scopes: []string{"users:read"}})
+	if !apiKeyFormat.MatchString(created.Key) {
+		t.Fatalf("密钥格式 = %s", created.Key)
+	}
+	if created.RateLimit != 60 {
+		t.Fatalf("默认限流 = %d, want 60", created.RateLimit)
+	}
+
+	key, err := env.service.Authenticate(ctx, created.Key)
+	if err != nil {
+		t.Fatalf("校验密钥失败: %v", err)
+	}
+	claims := env.service.Claims(key)
+	if claims.Subject != "user-1" || claims.TenantID != "acme" || len(claims.Scopes) != 1 {
+		t.Fatalf("claims = %+v", claims)
+	}
+
+	for _, raw := range []string{
+		"",
+		"sk",
+		"sk__secret",
+		"pk" + created.Key[2:],
+		created.Key + "x",
+		created.Key[:len("sk_")+12] + "_other",
+	} {
+		if _, err := env.service.Authenticate(ctx, raw); !errors.Is(err, ErrInvalidAPIKey) {
+			t.Errorf("Authenticate(%q) err = %v, want ErrInvalidAPIKey", raw, err)
+		}
+	}
+}
+
+func TestAPIKeyAuthenticateRejectsRevokedAndDisabled(t *testing.T) {
+	env := newAPIKeyServiceEnv(t)
+	ctx := context.Background()
+
+	revoked := env.create(t, env.active, CreateAPIKeyInput{Name: "ci", Scopes: []string{"users:read"}})
+	if err := env.service.Revoke(ctx, env.active.ID, revoked.ID); err != nil {
+		t.Fatalf("撤销密钥失败: %v", err)
+	}
+	if _, err := env.service.Authenticate(ctx, revoked.Key); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("已撤销密钥 err = %v, want ErrInvalidAPIKey", err)
+	}
+
+	disabled := &models.User{ID: "user-2", TenantID: "acme", Email: "disabled@example.com", Status: models.UserStatusDisabled, CreatedAt: time.Now()}
+	if err := env.users.Create(ctx, disabled); err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	created := env.create(t, disabled, CreateAPIKeyInput{Name: "ci", Scopes: []string{"users:read"}})
+	if _, err := env.service.Authenticate(ctx, created.Key); !errors.Is(err, ErrUserDisabled) {
+		t.Fatalf("用户已禁用 err = %v, want ErrUserDisabled", err)
+	}
+}
+
+func TestAPIKeyRevokeOtherUsersKey(t *testing.T) {
+	env := newAPIKeyServiceEnv(t)
+	created := env.create(t, env.active, CreateAPIKeyInput{Name: "ci", Scopes: []string{"users:read"}})
+	if err := env.service.Revoke(context.Background(), "user-2", created.ID); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Fatalf("err = %v, want ErrAPIKeyNotFound", err)
+	}
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/repository"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AuditRecorder 审计事件记录接口
+type AuditRecorder interface {
+	Record(ctx context.Context, event *models.AuditEvent)
+}
+
+// auditGenesisHash 哈希链第一个事件的 PrevHash
+const auditGenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// auditVerifyBatch 校验哈希链时每批读取的事件数
+const auditVerifyBatch = 1000
+
+// 审计字段长度上限，与表结构保持一致
+const (
+	auditMaxSubject   = 255
+	auditMaxUserAgent = 512
+)
+
+// AuditVerifyResult 哈希链校验结果
+type AuditVerifyResult struct {
+	Valid      bool   `json:"valid"`
+	Checked    int    `json:"checked"`
+	LastSeq    int64  `json:"last_seq"`
+	BrokenSeq  int64  `json:"broken_seq,omitempty"` // 第一个校验失败的事件序号
+	BrokenHint string `json:"broken_hint,omitempty"`
+}
+
+// AuditService 审计日志服务
+// 事件写入存储形成哈希链，同时输出到独立的审计日志文件；写入失败只记录错误，不影响业务请求
+type AuditService struct {
+	events repository.AuditRepository
+	logger *logrus.Logger
+
+	// 单实例内串行化追加，SQL 存储另有数据库锁保证多实例一致
+	mu sync.Mutex
+}
+
+// NewAuditService 创建审计日志服务，logger 为空时使用全局日志
+func NewAuditService(events repository.AuditRepository, logger *logrus.Logger) *AuditService {
+	if logger == nil {
+		logger = logrus.StandardLogger()
+	}
+	return &AuditService{events: events, logger: logger}
+}
+
+// Record 记录审计事件
+func (s *AuditService) Record(ctx context.Context, event *models.AuditEvent) {
+	if event.ID == "" {
+		event.ID = utils.NewID()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	// 统一为 UTC 微秒精度，保证经过数据库往返后哈希不变
+	event.OccurredAt = event.OccurredAt.UTC().Truncate(time.Microsecond)
+	event.Subject = truncate(event.Subject, auditMaxSubject)
+	event.UserAgent = truncate(event.UserAgent, auditMaxUserAgent)
+	if len(event.Metadata) == 0 {
+		event.Metadata = nil
+	}
+
+	s.mu.Lock()
+	err := s.events.AppendAuditEvent(ctx, event, func(prev *models.AuditEvent) {
+		event.Seq = 1
+		event.PrevHash = auditGenesisHash
+		if prev != nil {
+			event.Seq = prev.Seq + 1
+			event.PrevHash = prev.Hash
+		}
+		event.Hash = auditHash(event)
+	})
+	s.mu.Unlock()
+	if err != nil {
+		logrus.WithError(err).WithField("event", event.Type).Error("写入审计事件失败")
+	}
+
+	fields := logrus.Fields{
+		"seq":        event.Seq,
+		"event_id":   event.ID,
+		"event":      event.Type,
+		"tenant_id":  event.TenantID,
+		"actor_id":   event.ActorID,
+		"subject":    event.Subject,
+		"client_ip":  event.ClientIP,
+		"user_agent": event.UserAgent,
+		"hash":       event.Hash,
+		"prev_hash":  event.PrevHash,
+	}
+	if event.Metadata != nil {
+		fields["metadata"] = event.Metadata
+	}
+	s.logger.WithFields(fields).Info("审计事件")
+}
+
+// Query 查询审计事件
+func (s *AuditService) Query(ctx context.Context, filter repository.AuditFilter) ([]*models.AuditEvent, error) {
+	events, err := s.events.ListAuditEvents(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("查询审计事件失败: %w", err)
+	}
+	return events, nil
+}
+
+// Verify 从头校验整条哈希链，发现被篡改、删除或插入的事件时返回其序号
+func (s *AuditService) Verify(ctx context.Context) (*AuditVerifyResult, error) {
+	result := &AuditVerifyResult{Valid: true}
+	prevHash := auditGenesisHash
+
+	for {
+		batch, err := s.events.ListAuditEvents(ctx, repository.AuditFilter{
+			AfterSeq: result.LastSeq,
+			Limit:    auditVerifyBatch,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("读取审计事件失败: %w", err)
+		}
+
+		for _, event := range batch {
+			switch {
+			case event.Seq != result.LastSeq+1:
+				result.BrokenHint = "事件序号不连续，可能存在删除"
+			case event.PrevHash != prevHash:
+				result.BrokenHint = "上一事件哈希不匹配"
+			case event.Hash != auditHash(event):
+				result.BrokenHint = "事件内容与哈希不匹配"
+			}
+			if result.BrokenHint != "" {
+				result.Valid = false
+				result.BrokenSeq = event.Seq
+				return result, nil
+			}
+			prevHash = event.Hash
+			result.LastSeq = event.Seq
+			result.Checked++
+		}
+
+		if len(batch) < auditVerifyBatch {
+			return result, nil
+		}
+	}
+}
+
+// auditHash 计算事件哈希，覆盖除 Hash 外的全部字段
+// 使用固定字段顺序的 JSON 序列化，元数据按键排序
+func auditHash(event *models.AuditEvent) string {
+	payload, _ := json.Marshal(struct {
+		Seq        int64             `json:"seq"`
+		ID         string            `json:"id"`
+		Type       string            `json:"type"`
+		TenantID   string            `json:"tenant_id"`
+		ActorID    string            `json:"actor_id"`
+		Subject    string            `json:"subject"`
+		ClientIP   string            `json:"client_ip"`
+		UserAgent  string            `json:"user_agent"`
+		Metadata   map[string]string `json:"metadata"`
+		OccurredAt string            `json:"occurred_at"`
+		PrevHash   string            `json:"prev_hash"`
+	}{
+		Seq:        event.Seq,
+		ID:         event.ID,
+		Type:       event.Type,
+		TenantID:   event.TenantID,
+		ActorID:    event.ActorID,
+		Subject:    event.Subject,
+		ClientIP:   event.ClientIP,
+		UserAgent:  event.UserAgent,
+		Metadata:   event.Metadata,
+		OccurredAt: event.OccurredAt.UTC().Format(time.RFC3339Nano),
+		PrevHash:   event.PrevHash,
+	})
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// truncate 按字符截断字符串
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
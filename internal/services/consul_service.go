@@ -3,8 +3,9 @@ package services
 import (
 	"context"
 	"fmt"
+	"strconv"
 
-	"gin_middleware_oss/internal/config"
+	"gin_saas_auth/internal/config"
 
 	"github.com/hashicorp/consul/api"
 	"github.com/sirupsen/logrus"
@@ -67,6 +68,7 @@ func (r *ConsulRegistry) Register(ctx context.Context) error {
 			"metrics_path":      r.config.Consul.Meta.MetricsPath,
 			"metrics_namespace": r.config.Consul.Meta.MetricsNamespace,
 			"info_path":         r.config.Consul.Meta.InfoPath,
+			"metrics_port":      r.metricsPort(),
 
 			// === 负载均衡 ===
 			"weight":     r.config.Consul.Meta.Weight,
@@ -84,11 +86,17 @@ func (r *ConsulRegistry) Register(ctx context.Context) error {
 			"environment": r.config.App.Env,
 			"scheme":      r.config.Service.Scheme,
 		},
+		// 健康检查返回 429（过载降级）时 Consul 将实例标记为 warning，按降级权重参与负载均衡
+		Weights: &api.AgentWeights{
+			Passing: r.weight(),
+			Warning: 1,
+		},
 		Check: &api.AgentServiceCheck{
 			HTTP:                           r.config.GetConsulHealthCheckURL(),
 			Interval:                       "10s",
 			Timeout:                        "5s",
 			DeregisterCriticalServiceAfter: "60s",
+			TLSSkipVerify:                  r.config.Service.Scheme == "https" && r.config.TLS.CheckSkipVerify,
 		},
 	}
 
@@ -105,7 +113,7 @@ func (r *ConsulRegistry) Register(ctx context.Context) error {
 		"service_port":    r.config.Service.Port,
 		"consul_address":  r.config.Consul.Address,
 		"health_check":    r.config.GetHealthCheckURL(),
-		"metrics_path":    "/metrics",
+		"metrics_url":     r.config.GetMetricsURL(),
 	}).Info("OSS文件服务已成功注册到 Consul")
 
 	return nil
@@ -126,6 +134,23 @@ func (r *ConsulRegistry) Deregister(ctx context.Context) error {
 	return nil
 }
 
+// weight 服务正常时的负载均衡权重，取自 Consul Meta 中的 weight
+func (r *ConsulRegistry) weight() int {
+	weight, err := strconv.Atoi(r.config.Consul.Meta.Weight)
+	if err != nil || weight <= 0 {
+		return 100
+	}
+	return weight
+}
+
+// metricsPort 监控指标所在端口，启用运维管理端口时为管理端口
+func (r *ConsulRegistry) metricsPort() string {
+	if r.config.IsAdminEnabled() {
+		return strconv.Itoa(r.config.Admin.Port)
+	}
+	return strconv.Itoa(r.config.Service.Port)
+}
+
 // GetServiceID 获取服务 ID
 func (r *ConsulRegistry) GetServiceID() string {
 	return r.serviceID
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"container/list"
+	"context"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/utils"
+)
+
+// ErrOverloaded 实例过载，请求被拒绝
+var ErrOverloaded = utils.NewAppError("OVERLOADED", http.StatusServiceUnavailable, "服务繁忙，请稍后重试")
+
+// 请求被拒绝的原因，用于监控指标
+const (
+	ShedQueueFull    = "queue_full"    // 排队人数已满
+	ShedQueueTimeout = "queue_timeout" // 排队等待超时
+	ShedRouteLimit   = "route_limit"   // 单个路由并发已满
+)
+
+// 并发上限调整参数
+const (
+	latencySmoothing = 0.1 // 近期耗时的指数加权系数
+	limitSmoothing   = 0.2 // 并发上限每次调整的平滑系数
+	minGradient      = 0.5 // 单次调整最多将上限降低一半
+)
+
+// LoadStats 当前负载状态
+type LoadStats struct {
+	Limit    int     `json:"limit"`
+	InFlight int     `json:"in_flight"`
+	Queued   int     `json:"queued"`
+	Latency  float64 `json:"latency_ms"`
+	Degraded bool    `json:"degraded"`
+}
+
+// LoadShedder 自适应并发限制
+// 并发上限按梯度算法调整：近期平均耗时低于目标时逐步放宽，高于目标时按比例收紧；
+// 超过上限的请求按先来先到短暂排队，排队已满或等待超时的请求直接拒绝
+type LoadShedder struct {
+	cfg config.LoadShedConfig
+
+	mu       sync.Mutex
+	limit    float64
+	inFlight int
+	waiters  *list.List // 排队中的请求，元素为 chan struct{}，获得名额时关闭
+	latency  float64    // 近期平均处理耗时（秒）
+	lastShed time.Time
+
+	routeMu sync.Mutex
+	routes  map[string]chan struct{}
+}
+
+// NewLoadShedder 创建自适应并发限制
+func NewLoadShedder(cfg config.LoadShedConfig) *LoadShedder {
+	cfg.MinLimit = max(cfg.MinLimit, 1)
+	cfg.MaxLimit = max(cfg.MaxLimit, cfg.MinLimit)
+	return &LoadShedder{
+		cfg:     cfg,
+		limit:   float64(min(max(cfg.InitialLimit, cfg.MinLimit), cfg.MaxLimit)),
+		waiters: list.New(),
+		routes:  make(map[string]chan struct{}),
+	}
+}
+
+// Acquire 为请求申请全局和路由并发名额，名额不足时短暂排队
+// 成功时返回的 release 必须在请求处理完成后调用一次；被拒绝时返回 ErrOverloaded 和拒绝原因
+func (s *LoadShedder) Acquire(ctx context.Context, route string) (release func(), reason string, err error) {
+	if reason := s.acquireGlobal(ctx); reason != "" {
+		return nil, reason, ErrOverloaded
+	}
+
+	routeSlot := s.routeSlot(route)
+	if routeSlot != nil && !s.wait(ctx, routeSlot) {
+		s.releaseGlobal(0)
+		s.markShed()
+		return nil, ShedRouteLimit, ErrOverloaded
+	}
+
+	start := time.Now()
+	return func() {
+		if routeSlot != nil {
+			<-routeSlot
+		}
+		s.releaseGlobal(time.Since(start))
+	}, "", nil
+}
+
+// Degraded 最近是否拒绝过请求，健康检查据此报告降级状态
+func (s *LoadShedder) Degraded() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.lastShed.IsZero() && time.Since(s.lastShed) < s.cfg.DegradedHold
+}
+
+// Stats 查询当前负载状态
+func (s *LoadShedder) Stats() LoadStats {
+	degraded := s.Degraded()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return LoadStats{
+		Limit:    int(s.limit),
+		InFlight: s.inFlight,
+		Queued:   s.waiters.Len(),
+		Latency:  s.latency * 1000,
+		Degraded: degraded,
+	}
+}
+
+// RetryAfter 拒绝请求时建议客户端等待的时间
+func (s *LoadShedder) RetryAfter() time.Duration {
+	return s.cfg.RetryAfter
+}
+
+// acquireGlobal 申请全局并发名额，被拒绝时返回原因
+func (s *LoadShedder) acquireGlobal(ctx context.Context) string {
+	s.mu.Lock()
+	if s.inFlight < int(s.limit) && s.waiters.Len() == 0 {
+		s.inFlight++
+		s.mu.Unlock()
+		return ""
+	}
+	if s.waiters.Len() >= s.cfg.MaxQueue {
+		s.lastShed = time.Now()
+		s.mu.Unlock()
+		return ShedQueueFull
+	}
+	granted := make(chan struct{})
+	element := s.waiters.PushBack(granted)
+	s.mu.Unlock()
+
+	timer := time.NewTimer(s.cfg.QueueTimeout)
+	defer timer.Stop()
+	select {
+	case <-granted:
+		return ""
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-granted:
+		// 超时的同时恰好获得名额
+		return ""
+	default:
+	}
+	s.waiters.Remove(element)
+	s.lastShed = time.Now()
+	return ShedQueueTimeout
+}
+
+// releaseGlobal 归还全局名额并按本次耗时调整上限，然后按顺序唤醒排队的请求
+// latency 为 0 表示请求未被处理，不参与上限调整
+func (s *LoadShedder) releaseGlobal(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.inFlight--
+	if latency > 0 {
+		s.adjustLimitLocked(latency)
+	}
+	for s.waiters.Len() > 0 && s.inFlight < int(s.limit) {
+		granted := s.waiters.Remove(s.waiters.Front()).(chan struct{})
+		s.inFlight++
+		close(granted)
+	}
+}
+
+// adjustLimitLocked 梯度算法调整并发上限，调用方需持有锁
+// 新上限 = 当前上限 × min(1, 目标耗时 / 近期耗时) + √当前上限，再与当前上限做平滑
+func (s *LoadShedder) adjustLimitLocked(latency time.Duration) {
+	sample := latency.Seconds()
+	if s.latency == 0 {
+		s.latency = sample
+	} else {
+		s.latency = s.latency*(1-latencySmoothing) + sample*latencySmoothing
+	}
+
+	gradient := math.Max(minGradient, math.Min(1, s.cfg.TargetLatency.Seconds()/s.latency))
+	// 并发远低于上限时耗时不能说明上限是否合适，不再放宽
+	if gradient == 1 && float64(s.inFlight+1) < s.limit/2 {
+		return
+	}
+
+	target := s.limit*gradient + math.Sqrt(s.limit)
+	limit := s.limit*(1-limitSmoothing) + target*limitSmoothing
+	s.limit = math.Max(float64(s.cfg.MinLimit), math.Min(float64(s.cfg.MaxLimit), limit))
+}
+
+// routeSlot 获取路由的并发名额通道，未限制单路由并发时返回 nil
+func (s *LoadShedder) routeSlot(route string) chan struct{} {
+	if s.cfg.RouteMaxInFlight <= 0 {
+		return nil
+	}
+
+	s.routeMu.Lock()
+	defer s.routeMu.Unlock()
+	slot, exists := s.routes[route]
+	if !exists {
+		slot = make(chan struct{}, s.cfg.RouteMaxInFlight)
+		s.routes[route] = slot
+	}
+	return slot
+}
+
+// wait 在排队时间内占用路由名额
+func (s *LoadShedder) wait(ctx context.Context, slot chan struct{}) bool {
+	select {
+	case slot <- struct{}{}:
+		return true
+	default:
+	}
+
+	timer := time.NewTimer(s.cfg.QueueTimeout)
+	defer timer.Stop()
+	select {
+	case slot <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// markShed 记录拒绝请求的时间
+func (s *LoadShedder) markShed() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastShed = time.Now()
+}
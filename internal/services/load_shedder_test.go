@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gin_saas_auth/internal/config"
+)
+
+// testLoadShedConfig 目标耗时 10ms，并发上限在 [2, 100] 之间调整
+var testLoadShedConfig = config.LoadShedConfig{
+	InitialLimit:  20,
+	MinLimit:      2,
+	MaxLimit:      100,
+	TargetLatency: 10 * time.Millisecond,
+	MaxQueue:      1,
+	QueueTimeout:  time.Second,
+	RetryAfter:    time.Second,
+	DegradedHold:  time.Minute,
+}
+
+func TestLoadShedderGradientLowersLimitWhenSlow(t *testing.T) {
+	shedder := NewLoadShedder(testLoadShedConfig)
+
+	previous := shedder.limit
+	for range 5 {
+		shedder.adjustLimitLocked(100 * time.Millisecond)
+		if shedder.limit >= previous {
+			t.Fatalf("耗时超过目标后上限 = %.2f, want < %.2f", shedder.limit, previous)
+		}
+		previous = shedder.limit
+	}
+	for range 100 {
+		shedder.adjustLimitLocked(100 * time.Millisecond)
+	}
+	if shedder.limit < float64(testLoadShedConfig.MinLimit) || shedder.limit > 10 {
+		t.Fatalf("持续过载后上限 = %.2f, want 收敛到较低水平", shedder.limit)
+	}
+}
+
+func TestLoadShedderGradientRaisesLimitWhenBusy(t *testing.T) {
+	shedder := NewLoadShedder(testLoadShedConfig)
+	shedder.inFlight = 19
+
+	shedder.adjustLimitLocked(time.Millisecond)
+	if shedder.limit <= 20 {
+		t.Fatalf("接近上限且耗时低于目标时上限 = %.2f, want > 20", shedder.limit)
+	}
+	for range 200 {
+		shedder.inFlight = int(shedder.limit)
+		shedder.adjustLimitLocked(time.Millisecond)
+	}
+	if shedder.limit != float64(testLoadShedConfig.MaxLimit) {
+		t.Fatalf("上限 = %.2f, want 不超过 %d", shedder.limit, testLoadShedConfig.MaxLimit)
+	}
+}
+
+func TestLoadShedderGradientHoldsLimitWhenIdle(t *testing.T) {
+	shedder := NewLoadShedder(testLoadShedConfig)
+	shedder.adjustLimitLocked(time.Millisecond)
+	if shedder.limit != 20 {
+		t.Fatalf("并发远低于上限时上限 = %.2f, want 20", shedder.limit)
+	}
+}
+
+func TestLoadShedderQueue(t *testing.T) {
+	cfg := testLoadShedConfig
+	cfg.InitialLimit, cfg.MinLimit, cfg.MaxLimit = 1, 1, 1
+	shedder := NewLoadShedder(cfg)
+	ctx := context.Background()
+
+	release, _, err := shedder.Acquire(ctx, "GET /a")
+	if err != nil {
+		t.Fatalf("申请名额失败: %v", err)
+	}
+
+	queued := make(chan error, 1)
+	go func() {
+		release, _, err := shedder.Acquire(ctx, "GET /a")
+		if err == nil {
+			release()
+		}
+		queued <- err
+	}()
+	waitFor(t, func() bool { return shedder.Stats().Queued == 1 })
+
+	// 排队人数已满
+	if _, reason, err := shedder.Acquire(ctx, "GET /a"); !errors.Is(err, ErrOverloaded) || reason != ShedQueueFull {
+		t.Fatalf("reason = %q, err = %v, want %s", reason, err, ShedQueueFull)
+	}
+	if !shedder.Degraded() {
+		t.Fatal("拒绝请求后应报告降级")
+	}
+
+	release()
+	if err := <-queued; err != nil {
+		t.Fatalf("排队的请求应在名额释放后获得名额: %v", err)
+	}
+	if stats := shedder.Stats(); stats.InFlight != 0 || stats.Queued != 0 {
+		t.Fatalf("stats = %+v, want 全部释放", stats)
+	}
+}
+
+func TestLoadShedderQueueTimeout(t *testing.T) {
+	cfg := testLoadShedConfig
+	cfg.InitialLimit, cfg.MinLimit, cfg.MaxLimit = 1, 1, 1
+	cfg.QueueTimeout = 20 * time.Millisecond
+	shedder := NewLoadShedder(cfg)
+	ctx := context.Background()
+
+	release, _, err := shedder.Acquire(ctx, "GET /a")
+	if err != nil {
+		t.Fatalf("申请名额失败: %v", err)
+	}
+	defer release()
+
+	if _, reason, err := shedder.Acquire(ctx, "GET /a"); !errors.Is(err, ErrOverloaded) || reason != ShedQueueTimeout {
+		t.Fatalf("reason = %q, err = %v, want %s", reason, err, ShedQueueTimeout)
+	}
+	if stats := shedder.Stats(); stats.Queued != 0 {
+		t.Fatalf("超时的请求应移出队列, queued = %d", stats.Queued)
+	}
+}
+
+func TestLoadShedderRouteLimit(t *testing.T) {
+	cfg := testLoadShedConfig
+	cfg.RouteMaxInFlight = 1
+	cfg.QueueTimeout = 20 * time.Millisecond
+	shedder := NewLoadShedder(cfg)
+	ctx := context.Background()
+
+	release, _, err := shedder.Acquire(ctx, "POST /login")
+	if err != nil {
+		t.Fatalf("申请名额失败: %v", err)
+	}
+	defer release()
+
+	if _, reason, err := shedder.Acquire(ctx, "POST /login"); !errors.Is(err, ErrOverloaded) || reason != ShedRouteLimit {
+		t.Fatalf("reason = %q, err = %v, want %s", reason, err, ShedRouteLimit)
+	}
+	// 被路由限制拒绝的请求归还全局名额
+	if stats := shedder.Stats(); stats.InFlight != 1 {
+		t.Fatalf("in_flight = %d, want 1", stats.InFlight)
+	}
+
+	other, _, err := shedder.Acquire(ctx, "GET /users")
+	if err != nil {
+		t.Fatalf("其他路由不受影响: %v", err)
+	}
+	other()
+}
+
+// waitFor 等待条件成立，最多 1 秒
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("等待条件成立超时")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LoginAttempt 一次登录尝试的来源信息
+type LoginAttempt struct {
+	TenantID  string
+	Account   string // 登录邮箱，或 MFA 校验时的 "mfa:<用户ID>"；为空时只按 IP 统计
+	ClientIP  string
+	UserAgent string
+}
+
+// LockoutDecision 防暴力破解判定结果
+type LockoutDecision struct {
+	Locked          bool
+	RetryAfter      time.Duration
+	CaptchaRequired bool
+}
+
+// RetryAfterSeconds 向上取整的重试等待秒数
+func (d *LockoutDecision) RetryAfterSeconds() int {
+	if d.RetryAfter <= 0 {
+		return 0
+	}
+	return int((d.RetryAfter + time.Second - 1) / time.Second)
+}
+
+// LockoutService 登录防暴力破解服务
+// 失败次数按账户和客户端 IP 分别统计：账户超过免惩罚次数后按指数退避锁定，达到阈值后长时间锁定；
+// IP 达到阈值后锁定，用于阻止撞库式的多账户尝试。存储异常时放行请求，避免依赖故障导致无法登录
+type LockoutService struct {
+	store repository.AttemptStore
+	audit AuditRecorder
+	cfg   config.LockoutConfig
+}
+
+// NewLockoutService 创建防暴力破解服务
+func NewLockoutService(cfg *config.Config, store repository.AttemptStore, audit AuditRecorder) *LockoutService {
+	return &LockoutService{
+		store: store,
+		audit: audit,
+		cfg:   cfg.Lockout,
+	}
+}
+
+// Check 登录前检查账户或 IP 是否处于锁定状态，以及是否需要验证码
+func (s *LockoutService) Check(ctx context.Context, attempt LoginAttempt) *LockoutDecision {
+	decision := &LockoutDecision{}
+
+	accountKey, ipKey := s.keys(attempt)
+	for _, key := range []string{accountKey, ipKey} {
+		if key == "" {
+			continue
+		}
+		remaining, err := s.store.LockRemaining(ctx, key)
+		if err != nil {
+			logrus.WithError(err).WithField("key", key).Error("查询登录锁定状态失败")
+			continue
+		}
+		if remaining > decision.RetryAfter {
+			decision.RetryAfter = remaining
+		}
+	}
+	decision.Locked = decision.RetryAfter > 0
+	decision.CaptchaRequired = s.captchaRequired(ctx, accountKey, ipKey)
+
+	if decision.Locked {
+		s.record(ctx, models.AuditLoginBlocked, attempt, map[string]string{
+			"retry_after": strconv.Itoa(decision.RetryAfterSeconds()),
+		})
+	}
+	return decision
+}
+
+// RecordFailure 记录一次失败的登录，必要时锁定账户或 IP
+func (s *LockoutService) RecordFailure(ctx context.Context, attempt LoginAttempt) *LockoutDecision {
+	decision := &LockoutDecision{}
+	accountKey, ipKey := s.keys(attempt)
+
+	var accountFailures, ipFailures int
+	if accountKey != "" {
+		accountFailures = s.increment(ctx, accountKey)
+		if lockFor := s.accountLockDuration(accountFailures); lockFor > 0 {
+			s.lock(ctx, accountKey, lockFor)
+			decision.RetryAfter = lockFor
+			if accountFailures >= s.cfg.Threshold {
+				s.record(ctx, models.AuditAccountLocked, attempt, map[string]string{
+					"failures": strconv.Itoa(accountFailures),
+					"duration": lockFor.String(),
+				})
+			}
+		}
+	}
+	if ipKey != "" {
+		ipFailures = s.increment(ctx, ipKey)
+		if s.cfg.IPThreshold > 0 && ipFailures >= s.cfg.IPThreshold {
+			s.lock(ctx, ipKey, s.cfg.IPLockoutPeriod)
+			if s.cfg.IPLockoutPeriod > decision.RetryAfter {
+				decision.RetryAfter = s.cfg.IPLockoutPeriod
+			}
+			if ipFailures == s.cfg.IPThreshold {
+				s.record(ctx, models.AuditIPLocked, attempt, map[string]string{
+					"failures": strconv.Itoa(ipFailures),
+					"duration": s.cfg.IPLockoutPeriod.String(),
+				})
+			}
+		}
+	}
+
+	decision.Locked = decision.RetryAfter > 0
+	decision.CaptchaRequired = (s.cfg.CaptchaAfter > 0 && accountFailures >= s.cfg.CaptchaAfter) ||
+		(s.cfg.IPCaptchaAfter > 0 && ipFailures >= s.cfg.IPCaptchaAfter)
+
+	s.record(ctx, models.AuditLoginFailed, attempt, map[string]string{
+		"account_failures": strconv.Itoa(accountFailures),
+		"ip_failures":      strconv.Itoa(ipFailures),
+	})
+	return decision
+}
+
+// RecordSuccess 登录成功后清除账户的失败计数
+// IP 计数不清除，避免攻击者用自己的账户登录来重置撞库计数
+func (s *LockoutService) RecordSuccess(ctx context.Context, attempt LoginAttempt, userID string) {
+	accountKey, _ := s.keys(attempt)
+	if accountKey != "" {
+		if err := s.store.Reset(ctx, accountKey); err != nil {
+			logrus.WithError(err).WithField("key", accountKey).Error("清除登录失败计数失败")
+		}
+	}
+
+	event := s.event(models.AuditLoginSucceeded, attempt, nil)
+	event.ActorID = userID
+	s.audit.Record(ctx, event)
+}
+
+// accountLockDuration 根据账户失败次数计算锁定时长
+func (s *LockoutService) accountLockDuration(failures int) time.Duration {
+	if s.cfg.Threshold > 0 && failures >= s.cfg.Threshold {
+		return s.cfg.Duration
+	}
+	if failures <= s.cfg.FreeAttempts || s.cfg.BackoffBase <= 0 {
+		return 0
+	}
+
+	// 超出免惩罚次数后每次失败等待时间翻倍：1s, 2s, 4s ... 不超过上限
+	backoff := s.cfg.BackoffBase
+	for i := s.cfg.FreeAttempts + 1; i < failures && backoff < s.cfg.BackoffMax; i++ {
+		backoff *= 2
+	}
+	if backoff > s.cfg.BackoffMax {
+		return s.cfg.BackoffMax
+	}
+	return backoff
+}
+
+// captchaRequired 判断当前失败次数是否需要验证码
+func (s *LockoutService) captchaRequired(ctx context.Context, accountKey, ipKey string) bool {
+	if s.cfg.CaptchaAfter > 0 && accountKey != "" && s.failures(ctx, accountKey) >= s.cfg.CaptchaAfter {
+		return true
+	}
+	return s.cfg.IPCaptchaAfter > 0 && ipKey != "" && s.failures(ctx, ipKey) >= s.cfg.IPCaptchaAfter
+}
+
+// keys 生成账户和 IP 维度的统计键，账户按租户隔离
+func (s *LockoutService) keys(attempt LoginAttempt) (string, string) {
+	var accountKey, ipKey string
+	if attempt.Account != "" {
+		accountKey = "acct:" + attempt.TenantID + ":" + normalizeEmail(attempt.Account)
+	}
+	if attempt.ClientIP != "" {
+		ipKey = "ip:" + attempt.ClientIP
+	}
+	return accountKey, ipKey
+}
+
+// increment 失败次数加一，存储异常时返回 0
+func (s *LockoutService) increment(ctx context.Context, key string) int {
+	count, err := s.store.IncrementFailures(ctx, key, s.cfg.Window)
+	if err != nil {
+		logrus.WithError(err).WithField("key", key).Error("记录登录失败次数失败")
+		return 0
+	}
+	return count
+}
+
+// failures 查询失败次数，存储异常时返回 0
+func (s *LockoutService) failures(ctx context.Context, key string) int {
+	count, err := s.store.GetFailures(ctx, key)
+	if err != nil {
+		logrus.WithError(err).WithField("key", key).Error("查询登录失败次数失败")
+		return 0
+	}
+	return count
+}
+
+// lock 锁定统计键
+func (s *LockoutService) lock(ctx context.Context, key string, duration time.Duration) {
+	if err := s.store.Lock(ctx, key, duration); err != nil {
+		logrus.WithError(err).WithField("key", key).Error("写入登录锁定状态失败")
+	}
+}
+
+// record 记录审计事件
+func (s *LockoutService) record(ctx context.Context, eventType string, attempt LoginAttempt, metadata map[string]string) {
+	s.audit.Record(ctx, s.event(eventType, attempt, metadata))
+}
+
+// event 根据登录尝试构造审计事件
+func (s *LockoutService) event(eventType string, attempt LoginAttempt, metadata map[string]string) *models.AuditEvent {
+	return &models.AuditEvent{
+		Type:       eventType,
+		TenantID:   attempt.TenantID,
+		Subject:    attempt.Account,
+		ClientIP:   attempt.ClientIP,
+		UserAgent:  attempt.UserAgent,
+		Metadata:   metadata,
+		OccurredAt: time.Now(),
+	}
+}
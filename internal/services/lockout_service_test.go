@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/repository"
+)
+
+// testLockoutConfig 免惩罚 3 次，之后 1s 起翻倍退避，上限 8s，失败 10 次锁定 15 分钟
+var testLockoutConfig = config.LockoutConfig{
+	Window:          15 * time.Minute,
+	FreeAttempts:    3,
+	BackoffBase:     time.Second,
+	BackoffMax:      8 * time.Second,
+	Threshold:       10,
+	Duration:        15 * time.Minute,
+	CaptchaAfter:    5,
+	IPThreshold:     20,
+	IPCaptchaAfter:  10,
+	IPLockoutPeriod: time.Hour,
+}
+
+func newTestLockoutService(audit AuditRecorder) *LockoutService {
+	return NewLockoutService(&config.Config{Lockout: testLockoutConfig}, repository.NewMemoryAttemptStore(), audit)
+}
+
+func TestLockoutAccountLockDuration(t *testing.T) {
+	service := newTestLockoutService(&recordingAudit{})
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 1, want: 0},
+		{failures: 3, want: 0},
+		{failures: 4, want: time.Second},
+		{failures: 5, want: 2 * time.Second},
+		{failures: 6, want: 4 * time.Second},
+		{failures: 7, want: 8 * time.Second},
+		{failures: 9, want: 8 * time.Second},
+		{failures: 10, want: 15 * time.Minute},
+		{failures: 50, want: 15 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := service.accountLockDuration(tt.failures); got != tt.want {
+			t.Errorf("accountLockDuration(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestLockoutAccountLockDurationWithoutBackoff(t *testing.T) {
+	cfg := testLockoutConfig
+	cfg.BackoffBase = 0
+	service := NewLockoutService(&config.Config{Lockout: cfg}, repository.NewMemoryAttemptStore(), &recordingAudit{})
+	if got := service.accountLockDuration(5); got != 0 {
+		t.Fatalf("未启用退避 accountLockDuration(5) = %v, want 0", got)
+	}
+	if got := service.accountLockDuration(10); got != 15*time.Minute {
+		t.Fatalf("达到阈值 accountLockDuration(10) = %v, want 15m", got)
+	}
+}
+
+func TestLockoutRecordFailure(t *testing.T) {
+	audit := &recordingAudit{}
+	service := newTestLockoutService(audit)
+	ctx := context.Background()
+	attempt := LoginAttempt{TenantID: "acme", Account: "User@Example.com", ClientIP: "203.0.113.1"}
+
+	for i := 1; i <= 3; i++ {
+		if decision := service.RecordFailure(ctx, attempt); decision.Locked {
+			t.Fatalf("第 %d 次失败不应锁定", i)
+		}
+	}
+	decision := service.RecordFailure(ctx, attempt)
+	if !decision.Locked || decision.RetryAfter != time.Second || decision.RetryAfterSeconds() != 1 {
+		t.Fatalf("第 4 次失败 decision = %+v, want 锁定 1s", decision)
+	}
+
+	// 账户按租户隔离，邮箱大小写不敏感
+	check := service.Check(ctx, LoginAttempt{TenantID: "acme", Account: "user@example.com"})
+	if !check.Locked {
+		t.Fatal("同一账户应处于锁定状态")
+	}
+	if other := service.Check(ctx, LoginAttempt{TenantID: "globex", Account: "user@example.com"}); other.Locked {
+		t.Fatal("其他租户的同名账户不应锁定")
+	}
+	if !audit.hasEvent(models.AuditLoginBlocked) {
+		t.Fatal("锁定期间登录应记录审计事件")
+	}
+
+	if decision := service.RecordFailure(ctx, attempt); !decision.CaptchaRequired {
+		t.Fatal("失败 5 次后应要求验证码")
+	}
+}
+
+func TestLockoutAccountThreshold(t *testing.T) {
+	audit := &recordingAudit{}
+	service := newTestLockoutService(audit)
+	ctx := context.Background()
+	attempt := LoginAttempt{TenantID: "acme", Account: "user@example.com"}
+
+	var decision *LockoutDecision
+	for range testLockoutConfig.Threshold {
+		decision = service.RecordFailure(ctx, attempt)
+	}
+	if decision.RetryAfter != testLockoutConfig.Duration {
+		t.Fatalf("达到阈值 RetryAfter = %v, want %v", decision.RetryAfter, testLockoutConfig.Duration)
+	}
+	if !audit.hasEvent(models.AuditAccountLocked) {
+		t.Fatal("达到阈值应记录账户锁定审计事件")
+	}
+}
+
+func TestLockoutRecordSuccessKeepsIPFailures(t *testing.T) {
+	service := newTestLockoutService(&recordingAudit{})
+	ctx := context.Background()
+	attempt := LoginAttempt{TenantID: "acme", Account: "user@example.com", ClientIP: "203.0.113.1"}
+
+	for range 4 {
+		service.RecordFailure(ctx, attempt)
+	}
+	service.RecordSuccess(ctx, attempt, "user-1")
+
+	if decision := service.RecordFailure(ctx, attempt); decision.Locked {
+		t.Fatalf("登录成功后账户失败计数应清零, decision = %+v", decision)
+	}
+	// IP 计数不清除：已失败 5 次，再失败 5 次达到 IP 验证码阈值
+	var decision *LockoutDecision
+	for i := range 5 {
+		decision = service.RecordFailure(ctx, LoginAttempt{TenantID: "acme", Account: "other" + strconv.Itoa(i) + "@example.com", ClientIP: "203.0.113.1"})
+	}
+	if !decision.CaptchaRequired {
+		t.Fatal("IP 失败次数达到阈值后应要求验证码")
+	}
+}
+
+func TestLockoutIPThreshold(t *testing.T) {
+	audit := &recordingAudit{}
+	service := newTestLockoutService(audit)
+	ctx := context.Background()
+
+	// 撞库：每个账户只失败一次
+	var decision *LockoutDecision
+	for i := range testLockoutConfig.IPThreshold {
+		decision = service.RecordFailure(ctx, LoginAttempt{TenantID: "acme", Account: "user" + strconv.Itoa(i) + "@example.com", ClientIP: "198.51.100.7"})
+	}
+	if !decision.Locked || decision.RetryAfter != time.Hour {
+		t.Fatalf("IP 达到阈值 decision = %+v, want 锁定 1h", decision)
+	}
+	if !audit.hasEvent(models.AuditIPLocked) {
+		t.Fatal("应记录 IP 锁定审计事件")
+	}
+	if check := service.Check(ctx, LoginAttempt{TenantID: "acme", Account: "fresh@example.com", ClientIP: "198.51.100.7"}); !check.Locked {
+		t.Fatal("同一 IP 登录其他账户应被拒绝")
+	}
+}
@@ -0,0 +1,386 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/repository"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrMFAAlreadyEnabled 已启用 TOTP
+	ErrMFAAlreadyEnabled = utils.NewAppError("MFA_ALREADY_ENABLED", http.StatusConflict, "已启用动态码认证")
+	// ErrMFANotEnrolled 未绑定 TOTP
+	ErrMFANotEnrolled = utils.NewAppError("MFA_NOT_ENROLLED", http.StatusBadRequest, "未启用动态码认证")
+	// ErrInvalidMFACode 动态码或恢复码错误
+	ErrInvalidMFACode = utils.NewAppError("INVALID_MFA_CODE", http.StatusUnauthorized, "动态码或恢复码错误")
+	// ErrInvalidMFAChallenge MFA 挑战令牌无效或已过期
+	ErrInvalidMFAChallenge = utils.NewAppError("INVALID_MFA_CHALLENGE", http.StatusUnauthorized, "登录验证已过期，请重新登录")
+)
+
+const (
+	// recoveryCodeCount 每次生成的恢复码数量
+	recoveryCodeCount = 10
+	// totpSkew 允许的时钟偏差（时间步）
+	totpSkew = 1
+)
+
+// TOTPSetup TOTP 绑定信息，仅在绑定时返回一次
+type TOTPSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// MFAStatus 用户多因素认证状态
+type MFAStatus struct {
+	TOTPEnabled            bool       `json:"totp_enabled"`
+	ConfirmedAt            *time.Time `json:"confirmed_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+}
+
+// MFAChallenge 密码校验通过后需要完成第二因素时返回的挑战
+type MFAChallenge struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// MFAService TOTP 多因素认证服务
+type MFAService struct {
+	mfa    repository.MFARepository
+	users  repository.UserRepository
+	tokens *TokenService
+	box    *utils.SecretBox
+	issuer string
+}
+
+// NewMFAService 创建多因素认证服务
+func NewMFAService(cfg *config.Config, mfa repository.MFARepository, users repository.UserRepository, tokens *TokenService) (*MFAService, error) {
+	keyMaterial := cfg.Auth.MFAEncryptionKey
+	if keyMaterial == "" {
+		keyMaterial = "mfa:" + cfg.Auth.JWTSecret
+	}
+	box, err := utils.NewSecretBox(keyMaterial)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MFAService{
+		mfa:    mfa,
+		users:  users,
+		tokens: tokens,
+		box:    box,
+		issuer: cfg.Auth.MFAIssuer,
+	}, nil
+}
+
+// Status 查询用户的多因素认证状态
+func (s *MFAService) Status(ctx context.Context, userID string) (*MFAStatus, error) {
+	enrollment, err := s.mfa.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return &MFAStatus{}, nil
+		}
+		return nil, err
+	}
+	if !enrollment.Confirmed {
+		return &MFAStatus{}, nil
+	}
+
+	remaining, err := s.mfa.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &MFAStatus{
+		TOTPEnabled:            true,
+		ConfirmedAt:            enrollment.ConfirmedAt,
+		RecoveryCodesRemaining: remaining,
+	}, nil
+}
+
+// IsEnabled 判断用户是否已启用 TOTP
+func (s *MFAService) IsEnabled(ctx context.Context, userID string) (bool, error) {
+	enrollment, err := s.mfa.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return enrollment.Confirmed, nil
+}
+
+// Enroll 生成新的 TOTP 密钥，需调用 Confirm 校验一次动态码后才会生效
+func (s *MFAService) Enroll(ctx context.Context, user *models.User) (*TOTPSetup, error) {
+	enabled, err := s.IsEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := s.box.Seal(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.mfa.SaveTOTP(ctx, &models.TOTPEnrollment{
+		UserID:          user.ID,
+		SecretEncrypted: encrypted,
+		CreatedAt:       time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TOTPSetup{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm 校验动态码完成绑定，返回一次性展示的恢复码
+func (s *MFAService) Confirm(ctx context.Context, userID, code string) ([]string, error) {
+	enrollment, err := s.mfa.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrMFANotEnrolled
+		}
+		return nil, err
+	}
+	if enrollment.Confirmed {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	step, ok, err := s.validateCode(enrollment, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	if err := s.mfa.ConfirmTOTP(ctx, userID, step, time.Now()); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.replaceRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.WithField("user_id", userID).Info("用户已启用动态码认证")
+	return codes, nil
+}
+
+// Disable 校验动态码或恢复码后关闭 TOTP
+func (s *MFAService) Disable(ctx context.Context, userID, code string) error {
+	if err := s.VerifySecondFactor(ctx, userID, code); err != nil {
+		return err
+	}
+	if err := s.mfa.DeleteTOTP(ctx, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrMFANotEnrolled
+		}
+		return err
+	}
+
+	logrus.WithField("user_id", userID).Info("用户已关闭动态码认证")
+	return nil
+}
+
+// RegenerateRecoveryCodes 校验动态码后重新生成恢复码，旧恢复码全部失效
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	if _, err := s.verifyTOTP(ctx, userID, code); err != nil {
+		return nil, err
+	}
+	return s.replaceRecoveryCodes(ctx, userID)
+}
+
+// StartChallenge 为已通过第一因素校验的用户签发 MFA 挑战，amr 为第一因素的认证方式，完成挑战后沿用
+func (s *MFAService) StartChallenge(user *models.User, amr []string) (*MFAChallenge, error) {
+	token, expiresAt, err := s.tokens.IssueMFAChallenge(user, amr)
+	if err != nil {
+		return nil, err
+	}
+	return &MFAChallenge{
+		MFARequired: true,
+		MFAToken:    token,
+		ExpiresIn:   int64(time.Until(expiresAt).Seconds()),
+	}, nil
+}
+
+// CompleteChallenge 校验挑战令牌和第二因素，返回用户及本次登录的认证方式
+// 挑战令牌只能在签发时所属的租户下使用
+func (s *MFAService) CompleteChallenge(ctx context.Context, tenantID, mfaToken, code string) (*models.User, []string, error) {
+	claims, err := s.tokens.ParseMFAChallenge(mfaToken)
+	if err != nil || claims.TenantID != tenantID {
+		return nil, nil, ErrInvalidMFAChallenge
+	}
+
+	user, err := s.users.GetByID(ctx, claims.Subject)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, ErrInvalidMFAChallenge
+		}
+		return nil, nil, err
+	}
+	if !user.IsActive() {
+		return nil, nil, ErrUserDisabled
+	}
+
+	secondFactor, err := s.verify(ctx, user.ID, code)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 认证方式为挑战签发时的第一因素加上本次校验的第二因素
+	amr := append([]string{}, claims.AMR...)
+	for _, method := range append(secondFactor, models.AMRMFA) {
+		if !slices.Contains(amr, method) {
+			amr = append(amr, method)
+		}
+	}
+	return user, amr, nil
+}
+
+// ChallengeUserID 解析挑战令牌对应的用户 ID，令牌无效时返回空字符串
+// 仅用于统计失败次数，不代表校验通过
+func (s *MFAService) ChallengeUserID(mfaToken string) string {
+	claims, err := s.tokens.ParseMFAChallenge(mfaToken)
+	if err != nil {
+		return ""
+	}
+	return claims.Subject
+}
+
+// VerifySecondFactor 校验动态码或恢复码
+func (s *MFAService) VerifySecondFactor(ctx context.Context, userID, code string) error {
+	_, err := s.verify(ctx, userID, code)
+	return err
+}
+
+// verify 校验动态码，失败时尝试作为恢复码校验，返回第二因素对应的认证方式
+func (s *MFAService) verify(ctx context.Context, userID, code string) ([]string, error) {
+	code = strings.TrimSpace(code)
+	if len(code) == utils.TOTPDigits {
+		if _, err := s.verifyTOTP(ctx, userID, code); err != nil {
+			return nil, err
+		}
+		return []string{models.AMROTP}, nil
+	}
+
+	if err := s.consumeRecoveryCode(ctx, userID, code); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// verifyTOTP 校验动态码并记录时间步，同一动态码不能重复使用
+func (s *MFAService) verifyTOTP(ctx context.Context, userID, code string) (int64, error) {
+	enrollment, err := s.mfa.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return 0, ErrMFANotEnrolled
+		}
+		return 0, err
+	}
+	if !enrollment.Confirmed {
+		return 0, ErrMFANotEnrolled
+	}
+
+	step, ok, err := s.validateCode(enrollment, code)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, ErrInvalidMFACode
+	}
+
+	if err := s.mfa.AdvanceTOTPStep(ctx, userID, step); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			logrus.WithField("user_id", userID).Warn("检测到动态码重放")
+			return 0, ErrInvalidMFACode
+		}
+		return 0, err
+	}
+	return step, nil
+}
+
+// validateCode 解密密钥并校验动态码
+func (s *MFAService) validateCode(enrollment *models.TOTPEnrollment, code string) (int64, bool, error) {
+	secret, err := s.box.Open(enrollment.SecretEncrypted)
+	if err != nil {
+		return 0, false, err
+	}
+	step, ok := utils.ValidateTOTP(secret, code, time.Now(), totpSkew)
+	return step, ok, nil
+}
+
+// consumeRecoveryCode 使用一个恢复码
+func (s *MFAService) consumeRecoveryCode(ctx context.Context, userID, code string) error {
+	enabled, err := s.IsEnabled(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return ErrMFANotEnrolled
+	}
+
+	err = s.mfa.ConsumeRecoveryCode(ctx, userID, utils.HashToken(normalizeRecoveryCode(code)), time.Now())
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidMFACode
+		}
+		return err
+	}
+
+	logrus.WithField("user_id", userID).Info("用户使用了恢复码")
+	return nil
+}
+
+// replaceRecoveryCodes 生成新的恢复码，仅保存摘要
+func (s *MFAService) replaceRecoveryCodes(ctx context.Context, userID string) ([]string, error) {
+	now := time.Now()
+	plain := make([]string, 0, recoveryCodeCount)
+	records := make([]*models.RecoveryCode, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw, err := utils.GenerateRandomHex(5)
+		if err != nil {
+			return nil, err
+		}
+		// 格式化为 xxxxx-xxxxx 便于用户抄写
+		code := raw[:5] + "-" + raw[5:]
+		plain = append(plain, code)
+		records = append(records, &models.RecoveryCode{
+			UserID:    userID,
+			CodeHash:  utils.HashToken(normalizeRecoveryCode(code)),
+			CreatedAt: now,
+		})
+	}
+
+	if err := s.mfa.ReplaceRecoveryCodes(ctx, userID, records); err != nil {
+		return nil, err
+	}
+	return plain, nil
+}
+
+// normalizeRecoveryCode 忽略大小写和分隔符
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
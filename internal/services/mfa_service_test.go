@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/repository"
+	"gin_saas_auth/internal/utils"
+)
+
+// newTestConfig 加载测试配置，降低 argon2 参数加快测试
+func newTestConfig(t *testing.T) *config.Config {
+	t.Helper()
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("AUTH_JWT_SECRET", "services-test-secret-0123456789abcdef")
+	t.Setenv("AUTH_ARGON2_MEMORY", "1024")
+	t.Setenv("AUTH_ARGON2_ITERATIONS", "1")
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	return cfg
+}
+
+// mfaEnv 已启用动态码的用户及其恢复码
+type mfaEnv struct {
+	service  *MFAService
+	user     *models.User
+	secret   string
+	step     int64
+	recovery []string
+}
+
+func newMFAEnv(t *testing.T) *mfaEnv {
+	t.Helper()
+	cfg := newTestConfig(t)
+	ctx := context.Background()
+	users := repository.NewMemoryUserRepository()
+	user := &models.User{ID: "user-1", TenantID: "acme", Email: "mfa@example.com", Status: models.UserStatusActive, CreatedAt: time.Now()}
+	if err := users.Create(ctx, user); err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+
+	service, err := NewMFAService(cfg, repository.NewMemoryMFARepository(), users, NewTokenService(cfg))
+	if err != nil {
+		t.Fatalf("创建 MFA 服务失败: %v", err)
+	}
+	setup, err := service.Enroll(ctx, user)
+	if err != nil {
+		t.Fatalf("绑定动态码失败: %v", err)
+	}
+	step := utils.TOTPStep(time.Now())
+	recovery, err := service.Confirm(ctx, user.ID, totpCode(t, setup.Secret, step))
+	if err != nil {
+		t.Fatalf("确认绑定失败: %v", err)
+	}
+	return &mfaEnv{service: service, user: user, secret: setup.Secret, step: step, recovery: recovery}
+}
+
+// nextCode 返回尚未使用过的下一个时间步的动态码
+func (env *mfaEnv) nextCode(t *testing.T) string {
+	t.Helper()
+	env.step++
+	return totpCode(t, env.secret, env.step)
+}
+
+func totpCode(t *testing.T, secret string, step int64) string {
+	t.Helper()
+	code, err := utils.TOTPCode(secret, step)
+	if err != nil {
+		t.Fatalf("生成动态码失败: %v", err)
+	}
+	return code
+}
+
+func TestMFACompleteChallengeKeepsFirstFactor(t *testing.T) {
+	tests := []struct {
+		name        string
+		firstFactor []string
+		recovery    bool
+		want        []string
+	}{
+		{name: "password and totp", firstFactor: []string{models.AMRPassword}, want: []string{models.AMRPassword, models.AMROTP, models.AMRMFA}},
+		{name: "federated and totp", firstFactor: []string{models.AMRFederated}, want: []string{models.AMRFederated, models.AMROTP, models.AMRMFA}},
+		{name: "email otp and totp", firstFactor: []string{models.AMROTP}, want: []string{models.AMROTP, models.AMRMFA}},
+		{name: "password and recovery code", firstFactor: []string{models.AMRPassword}, recovery: true, want: []string{models.AMRPassword, models.AMRMFA}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newMFAEnv(t)
+			challenge, err := env.service.StartChallenge(env.user, tt.firstFactor)
+			if err != nil {
+				t.Fatalf("签发挑战失败: %v", err)
+			}
+
+			code := env.nextCode(t)
+			if tt.recovery {
+				code = env.recovery[0]
+			}
+			_, amr, err := env.service.CompleteChallenge(context.Background(), "acme", challenge.MFAToken, code)
+			if err != nil {
+				t.Fatalf("完成挑战失败: %v", err)
+			}
+			if !reflect.DeepEqual(amr, tt.want) {
+				t.Fatalf("amr = %v, want %v", amr, tt.want)
+			}
+		})
+	}
+}
+
+func TestMFACompleteChallengeRejectsOtherTenant(t *testing.T) {
+	env := newMFAEnv(t)
+	challenge, err := env.service.StartChallenge(env.user, []string{models.AMRPassword})
+	if err != nil {
+		t.Fatalf("签发挑战失败: %v", err)
+	}
+	if _, _, err := env.service.CompleteChallenge(context.Background(), "globex", challenge.MFAToken, env.nextCode(t)); !errors.Is(err, ErrInvalidMFAChallenge) {
+		t.Fatalf("err = %v, want ErrInvalidMFAChallenge", err)
+	}
+}
+
+func TestMFATOTPReplayRejected(t *testing.T) {
+	env := newMFAEnv(t)
+	ctx := context.Background()
+
+	// 绑定时使用过的动态码不能再次使用
+	if err := env.service.VerifySecondFactor(ctx, env.user.ID, totpCode(t, env.secret, env.step)); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("重放绑定动态码 err = %v, want ErrInvalidMFACode", err)
+	}
+
+	code := env.nextCode(t)
+	if err := env.service.VerifySecondFactor(ctx, env.user.ID, code); err != nil {
+		t.Fatalf("校验动态码失败: %v", err)
+	}
+	if err := env.service.VerifySecondFactor(ctx, env.user.ID, code); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("重放动态码 err = %v, want ErrInvalidMFACode", err)
+	}
+}
+
+func TestMFARecoveryCodeSingleUse(t *testing.T) {
+	env := newMFAEnv(t)
+	ctx := context.Background()
+	if len(env.recovery) == 0 {
+		t.Fatal("确认绑定后应返回恢复码")
+	}
+
+	if err := env.service.VerifySecondFactor(ctx, env.user.ID, " "+env.recovery[0]+" "); err != nil {
+		t.Fatalf("校验恢复码失败: %v", err)
+	}
+	if err := env.service.VerifySecondFactor(ctx, env.user.ID, env.recovery[0]); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("重复使用恢复码 err = %v, want ErrInvalidMFACode", err)
+	}
+	if err := env.service.VerifySecondFactor(ctx, env.user.ID, env.recovery[1]); err != nil {
+		t.Fatalf("校验其他恢复码失败: %v", err)
+	}
+}
+
+func TestMFARegenerateRecoveryCodesRevokesOld(t *testing.T) {
+	env := newMFAEnv(t)
+	ctx := context.Background()
+
+	codes, err := env.service.RegenerateRecoveryCodes(ctx, env.user.ID, env.nextCode(t))
+	if err != nil {
+		t.Fatalf("重新生成恢复码失败: %v", err)
+	}
+	if err := env.service.VerifySecondFactor(ctx, env.user.ID, env.recovery[0]); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("旧恢复码 err = %v, want ErrInvalidMFACode", err)
+	}
+	if err := env.service.VerifySecondFactor(ctx, env.user.ID, codes[0]); err != nil {
+		t.Fatalf("校验新恢复码失败: %v", err)
+	}
+}
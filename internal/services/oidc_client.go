@@ -0,0 +1,345 @@
+package services
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwksRefreshInterval 遇到未知 kid 时重新拉取签名公钥的最小间隔，避免伪造令牌触发大量请求
+const jwksRefreshInterval = time.Minute
+
+// maxOIDCResponseBytes 身份提供方响应的最大长度
+const maxOIDCResponseBytes = 1 << 20
+
+// idTokenMethods 接受的 ID 令牌签名算法，只接受非对称算法
+var idTokenMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// oidcMetadata OIDC 发现文档中使用的字段
+type oidcMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// oidcTokenResponse 令牌端点响应
+type oidcTokenResponse struct {
+	IDToken          string `json:"id_token"`
+	AccessToken      string `json:"access_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// oidcProviderCache 单个 issuer 缓存的发现文档和签名公钥
+type oidcProviderCache struct {
+	metadata    *oidcMetadata
+	fetchedAt   time.Time
+	keys        map[string]crypto.PublicKey
+	keysFetched time.Time
+}
+
+// OIDCClient 访问上游 OIDC 身份提供方的客户端
+// 发现文档和签名公钥按 issuer 缓存，遇到未知 kid 时按最小间隔重新拉取以支持密钥轮换
+type OIDCClient struct {
+	http *http.Client
+	ttl  time.Duration
+
+	mu    sync.Mutex
+	cache map[string]*oidcProviderCache
+}
+
+// NewOIDCClient 创建 OIDC 客户端，httpClient 可替换以便对接本地模拟的身份提供方
+func NewOIDCClient(httpClient *http.Client, ttl time.Duration) *OIDCClient {
+	return &OIDCClient{
+		http:  httpClient,
+		ttl:   ttl,
+		cache: make(map[string]*oidcProviderCache),
+	}
+}
+
+// Discover 获取 issuer 的发现文档，文档中的 issuer 必须与配置完全一致
+func (c *OIDCClient) Discover(ctx context.Context, issuer string) (*oidcMetadata, error) {
+	c.mu.Lock()
+	entry, exists := c.cache[issuer]
+	if exists && entry.metadata != nil && time.Since(entry.fetchedAt) < c.ttl {
+		metadata := entry.metadata
+		c.mu.Unlock()
+		return metadata, nil
+	}
+	c.mu.Unlock()
+
+	var metadata oidcMetadata
+	discoveryURL := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	if err := c.getJSON(ctx, discoveryURL, &metadata); err != nil {
+		return nil, fmt.Errorf("获取身份提供方发现文档失败: %w", err)
+	}
+	if metadata.Issuer != issuer {
+		return nil, fmt.Errorf("发现文档中的 issuer 与配置不一致: %s", metadata.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, errors.New("发现文档缺少授权、令牌或公钥端点")
+	}
+
+	c.mu.Lock()
+	if entry == nil || entry.metadata == nil || entry.metadata.JWKSURI != metadata.JWKSURI {
+		entry = &oidcProviderCache{}
+		c.cache[issuer] = entry
+	}
+	entry.metadata = &metadata
+	entry.fetchedAt = time.Now()
+	c.mu.Unlock()
+	return &metadata, nil
+}
+
+// ExchangeCode 使用授权码和 PKCE 校验值换取令牌
+// 配置了客户端密钥时使用 client_secret_basic 认证，否则作为公开客户端只发送 client_id
+func (c *OIDCClient) ExchangeCode(ctx context.Context, metadata *oidcMetadata, clientID, clientSecret, code, verifier, redirectURI string) (*oidcTokenResponse, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {verifier},
+	}
+	if clientSecret == "" {
+		form.Set("client_id", clientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("创建令牌请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if clientSecret != "" {
+		// RFC 6749 2.3.1：用户名和密码先按表单编码
+		req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求令牌端点失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var token oidcTokenResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxOIDCResponseBytes)).Decode(&token); err != nil {
+		return nil, fmt.Errorf("解析令牌响应失败（状态码 %d）: %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || token.Error != "" {
+		return nil, fmt.Errorf("令牌端点拒绝授权码（状态码 %d）: %s %s", resp.StatusCode, token.Error, token.ErrorDescription)
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("令牌响应中缺少 id_token")
+	}
+	return &token, nil
+}
+
+// VerifyIDToken 校验 ID 令牌的签名、issuer、受众、有效期和 nonce，返回全部声明
+func (c *OIDCClient) VerifyIDToken(ctx context.Context, metadata *oidcMetadata, rawToken, clientID, nonce string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return c.signingKey(ctx, metadata, kid)
+	},
+		jwt.WithValidMethods(idTokenMethods),
+		jwt.WithIssuer(metadata.Issuer),
+		jwt.WithAudience(clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ID 令牌校验失败: %w", err)
+	}
+
+	if value, _ := claims["nonce"].(string); value == "" || value != nonce {
+		return nil, errors.New("ID 令牌的 nonce 不匹配")
+	}
+	// 令牌有多个受众时，azp 必须是本客户端
+	if audience, _ := claims.GetAudience(); len(audience) > 1 {
+		if azp, _ := claims["azp"].(string); azp != clientID {
+			return nil, errors.New("ID 令牌的 azp 不是本客户端")
+		}
+	}
+	if subject, _ := claims.GetSubject(); subject == "" {
+		return nil, errors.New("ID 令牌缺少 sub")
+	}
+	return claims, nil
+}
+
+// signingKey 查找签名公钥，kid 未知时重新拉取公钥集
+func (c *OIDCClient) signingKey(ctx context.Context, metadata *oidcMetadata, kid string) (crypto.PublicKey, error) {
+	c.mu.Lock()
+	entry := c.cache[metadata.Issuer]
+	if entry == nil {
+		entry = &oidcProviderCache{metadata: metadata, fetchedAt: time.Now()}
+		c.cache[metadata.Issuer] = entry
+	}
+	if key := lookupKey(entry.keys, kid); key != nil && time.Since(entry.keysFetched) < c.ttl {
+		c.mu.Unlock()
+		return key, nil
+	}
+	if entry.keys != nil && time.Since(entry.keysFetched) < jwksRefreshInterval {
+		key := lookupKey(entry.keys, kid)
+		c.mu.Unlock()
+		if key == nil {
+			return nil, fmt.Errorf("未找到签名公钥: %s", kid)
+		}
+		return key, nil
+	}
+	c.mu.Unlock()
+
+	keys, err := c.fetchKeys(ctx, metadata.JWKSURI)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	entry.keys = keys
+	entry.keysFetched = time.Now()
+	c.mu.Unlock()
+
+	if key := lookupKey(keys, kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("未找到签名公钥: %s", kid)
+}
+
+// lookupKey 按 kid 查找公钥，令牌未指定 kid 且公钥集只有一个公钥时使用该公钥
+func lookupKey(keys map[string]crypto.PublicKey, kid string) crypto.PublicKey {
+	if key, ok := keys[kid]; ok {
+		return key
+	}
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key
+		}
+	}
+	return nil
+}
+
+// jsonWebKey JWK 中使用的字段
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// fetchKeys 拉取并解析公钥集，忽略加密用途和无法识别的公钥
+func (c *OIDCClient) fetchKeys(ctx context.Context, jwksURI string) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := c.getJSON(ctx, jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("获取身份提供方签名公钥失败: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("身份提供方公钥集中没有可用的签名公钥")
+	}
+	return keys, nil
+}
+
+// publicKey 将 JWK 转换为 RSA 或 EC 公钥
+func (k *jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("RSA 公钥指数无效")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("不支持的椭圆曲线: %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC 公钥不在曲线上")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("不支持的公钥类型: %s", k.Kty)
+}
+
+// decodeBigInt 解码 base64url 编码的大整数
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil || len(raw) == 0 {
+		return nil, errors.New("公钥参数编码错误")
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
+
+// getJSON 请求并解析 JSON 文档
+func (c *OIDCClient) getJSON(ctx context.Context, target string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s 返回状态码 %d", target, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxOIDCResponseBytes)).Decode(v)
+}
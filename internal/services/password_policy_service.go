@@ -0,0 +1,231 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/i18n"
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/repository"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrInvalidPasswordPolicy 密码策略配置不合法
+	ErrInvalidPasswordPolicy = utils.NewAppError("INVALID_PASSWORD_POLICY", http.StatusBadRequest, "密码策略配置错误")
+	// ErrPasswordExpired 密码已超过最长使用期限
+	ErrPasswordExpired = utils.NewAppError("PASSWORD_EXPIRED", http.StatusForbidden, "密码已过期，请在登录时通过 new_password 设置新密码")
+)
+
+// 密码策略的取值范围
+const (
+	minPasswordLengthFloor   = 8
+	maxPasswordLengthCeiling = 256
+	maxPasswordHistorySize   = 24
+	maxPasswordAgeDays       = 3650
+)
+
+// PasswordPolicyService 密码策略服务
+// 租户未配置策略时使用全局默认策略；泄露密码库在启动时载入内存，只保存 SHA-1 摘要
+type PasswordPolicyService struct {
+	repo     repository.PasswordPolicyRepository
+	defaults models.PasswordPolicy
+	breached map[[sha1.Size]byte]struct{}
+}
+
+// NewPasswordPolicyService 创建密码策略服务，默认策略不合法或泄露密码库无法读取时返回错误
+func NewPasswordPolicyService(cfg *config.Config, repo repository.PasswordPolicyRepository) (*PasswordPolicyService, error) {
+	s := &PasswordPolicyService{
+		repo: repo,
+		defaults: models.PasswordPolicy{
+			MinLength:     cfg.Password.MinLength,
+			MaxLength:     cfg.Password.MaxLength,
+			RequireUpper:  cfg.Password.RequireUpper,
+			RequireLower:  cfg.Password.RequireLower,
+			RequireDigit:  cfg.Password.RequireDigit,
+			RequireSymbol: cfg.Password.RequireSymbol,
+			CheckBreached: cfg.Password.CheckBreached,
+			HistorySize:   cfg.Password.HistorySize,
+			MaxAgeDays:    cfg.Password.MaxAgeDays,
+		},
+	}
+
+	if cfg.Password.BreachedFile != "" {
+		breached, err := loadBreachedHashes(cfg.Password.BreachedFile)
+		if err != nil {
+			return nil, err
+		}
+		s.breached = breached
+		logrus.WithField("count", len(breached)).Info("泄露密码库已载入")
+	}
+
+	if err := s.validate(&s.defaults); err != nil {
+		return nil, fmt.Errorf("默认密码策略: %w", err)
+	}
+	return s, nil
+}
+
+// Get 查询租户生效的密码策略
+func (s *PasswordPolicyService) Get(ctx context.Context, tenantID string) (*models.PasswordPolicy, error) {
+	policy, err := s.repo.GetPasswordPolicy(ctx, tenantID)
+	if err == nil {
+		policy.Custom = true
+		return policy, nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	defaults := s.defaults
+	defaults.TenantID = tenantID
+	return &defaults, nil
+}
+
+// Update 保存租户自定义密码策略
+func (s *PasswordPolicyService) Update(ctx context.Context, tenantID string, policy *models.PasswordPolicy) (*models.PasswordPolicy, error) {
+	if err := s.validate(policy); err != nil {
+		return nil, err
+	}
+
+	policy.TenantID = tenantID
+	policy.Custom = true
+	policy.UpdatedAt = time.Now()
+	if err := s.repo.SavePasswordPolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	logrus.WithField("tenant_id", tenantID).Info("租户密码策略已更新")
+	return policy, nil
+}
+
+// Reset 删除租户自定义密码策略，返回恢复后的默认策略
+func (s *PasswordPolicyService) Reset(ctx context.Context, tenantID string) (*models.PasswordPolicy, error) {
+	if err := s.repo.DeletePasswordPolicy(ctx, tenantID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	logrus.WithField("tenant_id", tenantID).Info("租户密码策略已恢复默认")
+	return s.Get(ctx, tenantID)
+}
+
+// Check 按策略校验密码的长度、字符类型和是否已泄露，不符合时返回附带全部未满足要求的 ErrInvalidPassword
+// 历史密码需要比对哈希，由 UserService 校验
+func (s *PasswordPolicyService) Check(policy *models.PasswordPolicy, password string) error {
+	var violations []i18n.Message
+
+	length := utf8.RuneCountInString(password)
+	if length < policy.MinLength {
+		violations = append(violations, i18n.NewMessage("password.min_length", i18n.Params{"min": policy.MinLength}))
+	}
+	if length > policy.MaxLength {
+		violations = append(violations, i18n.NewMessage("password.max_length", i18n.Params{"max": policy.MaxLength}))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case !unicode.IsLetter(r):
+			hasSymbol = true
+		}
+	}
+	if policy.RequireUpper && !hasUpper {
+		violations = append(violations, i18n.NewMessage("password.require_upper"))
+	}
+	if policy.RequireLower && !hasLower {
+		violations = append(violations, i18n.NewMessage("password.require_lower"))
+	}
+	if policy.RequireDigit && !hasDigit {
+		violations = append(violations, i18n.NewMessage("password.require_digit"))
+	}
+	if policy.RequireSymbol && !hasSymbol {
+		violations = append(violations, i18n.NewMessage("password.require_symbol"))
+	}
+
+	if policy.CheckBreached && s.isBreached(password) {
+		violations = append(violations, i18n.NewMessage("password.breached"))
+	}
+
+	if len(violations) > 0 {
+		return ErrInvalidPassword.WithDetails(violations...)
+	}
+	return nil
+}
+
+// isBreached 判断密码是否在泄露密码库中
+func (s *PasswordPolicyService) isBreached(password string) bool {
+	if s.breached == nil {
+		return false
+	}
+	_, found := s.breached[sha1.Sum([]byte(password))]
+	return found
+}
+
+// validate 校验策略的取值范围
+func (s *PasswordPolicyService) validate(policy *models.PasswordPolicy) error {
+	switch {
+	case policy.MinLength < minPasswordLengthFloor || policy.MinLength > policy.MaxLength:
+		return ErrInvalidPasswordPolicy.WithDetails(i18n.NewMessage("password_policy.min_length", i18n.Params{"min": minPasswordLengthFloor}))
+	case policy.MaxLength > maxPasswordLengthCeiling:
+		return ErrInvalidPasswordPolicy.WithDetails(i18n.NewMessage("password_policy.max_length", i18n.Params{"max": maxPasswordLengthCeiling}))
+	case policy.HistorySize < 0 || policy.HistorySize > maxPasswordHistorySize:
+		return ErrInvalidPasswordPolicy.WithDetails(i18n.NewMessage("password_policy.history_size", i18n.Params{"max": maxPasswordHistorySize}))
+	case policy.MaxAgeDays < 0 || policy.MaxAgeDays > maxPasswordAgeDays:
+		return ErrInvalidPasswordPolicy.WithDetails(i18n.NewMessage("password_policy.max_age_days", i18n.Params{"max": maxPasswordAgeDays}))
+	case policy.CheckBreached && s.breached == nil:
+		return ErrInvalidPasswordPolicy.WithDetails(i18n.NewMessage("password_policy.breached_file_missing"))
+	}
+	return nil
+}
+
+// loadBreachedHashes 载入泄露密码库，每行一个十六进制 SHA-1 摘要，忽略空行、# 注释和 :次数 后缀
+func loadBreachedHashes(path string) (map[[sha1.Size]byte]struct{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开泄露密码库失败: %w", err)
+	}
+	defer file.Close()
+
+	hashes := make(map[[sha1.Size]byte]struct{})
+	scanner := bufio.NewScanner(file)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			line = line[:i]
+		}
+
+		var sum [sha1.Size]byte
+		if len(line) != hex.EncodedLen(sha1.Size) {
+			return nil, fmt.Errorf("泄露密码库第 %d 行不是 SHA-1 摘要", lineNo)
+		}
+		if _, err := hex.Decode(sum[:], []byte(line)); err != nil {
+			return nil, fmt.Errorf("泄露密码库第 %d 行不是 SHA-1 摘要", lineNo)
+		}
+		hashes[sum] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取泄露密码库失败: %w", err)
+	}
+	return hashes, nil
+}
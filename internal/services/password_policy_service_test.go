@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/repository"
+	"gin_saas_auth/internal/utils"
+)
+
+// writeBreachedFile 写入包含指定密码 SHA-1 摘要的泄露密码库
+func writeBreachedFile(t *testing.T, passwords ...string) string {
+	t.Helper()
+	lines := []string{"# 测试泄露密码库", ""}
+	for _, password := range passwords {
+		sum := sha1.Sum([]byte(password))
+		lines = append(lines, strings.ToUpper(hex.EncodeToString(sum[:]))+":42")
+	}
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+		t.Fatalf("写入泄露密码库失败: %v", err)
+	}
+	return path
+}
+
+// violationKeys 返回密码校验错误中未满足要求的消息键
+func violationKeys(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var appErr *utils.AppError
+	if !errors.As(err, &appErr) || appErr.Code != ErrInvalidPassword.Code {
+		t.Fatalf("err = %v, want ErrInvalidPassword", err)
+	}
+	keys := make([]string, 0, len(appErr.Details))
+	for _, detail := range appErr.Details {
+		keys = append(keys, detail.Key)
+	}
+	return keys
+}
+
+func TestPasswordPolicyCheck(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.Password.BreachedFile = writeBreachedFile(t, "Password123!")
+	service, err := NewPasswordPolicyService(cfg, repository.NewMemoryTenantRepository())
+	if err != nil {
+		t.Fatalf("初始化密码策略服务失败: %v", err)
+	}
+	strict := &models.PasswordPolicy{MinLength: 10, MaxLength: 20, RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true, CheckBreached: true}
+
+	tests := []struct {
+		name     string
+		password string
+		want     []string
+	}{
+		{name: "valid", password: "Orchid-Lantern-41"},
+		{name: "breached", password: "Password123!", want: []string{"password.breached"}},
+		{name: "too short lowercase", password: "short", want: []string{"password.min_length", "password.require_upper", "password.require_digit", "password.require_symbol"}},
+		{name: "too long", password: "Orchid-Lantern-41-Orchid", want: []string{"password.max_length"}},
+		{name: "multibyte counted as characters", password: "密码密码密码密码密码Aa1!", want: nil},
+		{name: "no symbol", password: "OrchidLantern41", want: []string{"password.require_symbol"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := violationKeys(t, service.Check(strict, tt.password)); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// 未启用泄露检查的策略不拒绝泄露密码
+	relaxed := *strict
+	relaxed.CheckBreached = false
+	if err := service.Check(&relaxed, "Password123!"); err != nil {
+		t.Fatalf("未启用泄露检查 err = %v", err)
+	}
+}
+
+func TestPasswordPolicyRequiresBreachedFile(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.Password.CheckBreached = true
+	if _, err := NewPasswordPolicyService(cfg, repository.NewMemoryTenantRepository()); !errors.Is(err, ErrInvalidPasswordPolicy) {
+		t.Fatalf("启用泄露检查但未配置密码库 err = %v, want ErrInvalidPasswordPolicy", err)
+	}
+
+	cfg.Password.BreachedFile = filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(cfg.Password.BreachedFile, []byte("not-a-sha1\n"), 0o600); err != nil {
+		t.Fatalf("写入泄露密码库失败: %v", err)
+	}
+	if _, err := NewPasswordPolicyService(cfg, repository.NewMemoryTenantRepository()); err == nil || !strings.Contains(err.Error(), "第 1 行") {
+		t.Fatalf("密码库格式错误 err = %v", err)
+	}
+}
+
+func TestPasswordPolicyTenantOverride(t *testing.T) {
+	cfg := newTestConfig(t)
+	service, err := NewPasswordPolicyService(cfg, repository.NewMemoryTenantRepository())
+	if err != nil {
+		t.Fatalf("初始化密码策略服务失败: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := service.Update(ctx, "acme", &models.PasswordPolicy{MinLength: 12, MaxLength: 64, HistorySize: 25}); !errors.Is(err, ErrInvalidPasswordPolicy) {
+		t.Fatalf("历史数量超出范围 err = %v, want ErrInvalidPasswordPolicy", err)
+	}
+	if _, err := service.Update(ctx, "acme", &models.PasswordPolicy{MinLength: 12, MaxLength: 64, CheckBreached: true}); !errors.Is(err, ErrInvalidPasswordPolicy) {
+		t.Fatalf("未配置密码库时启用泄露检查 err = %v, want ErrInvalidPasswordPolicy", err)
+	}
+	if _, err := service.Update(ctx, "acme", &models.PasswordPolicy{MinLength: 12, MaxLength: 64, HistorySize: 3}); err != nil {
+		t.Fatalf("保存租户策略失败: %v", err)
+	}
+
+	policy, err := service.Get(ctx, "acme")
+	if err != nil || !policy.Custom || policy.MinLength != 12 || policy.HistorySize != 3 {
+		t.Fatalf("租户策略 = %+v, err = %v", policy, err)
+	}
+	if other, _ := service.Get(ctx, "globex"); other.Custom || other.MinLength != cfg.Password.MinLength {
+		t.Fatalf("其他租户策略 = %+v, want 默认策略", other)
+	}
+
+	reset, err := service.Reset(ctx, "acme")
+	if err != nil || reset.Custom {
+		t.Fatalf("恢复默认策略 = %+v, err = %v", reset, err)
+	}
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"gin_saas_auth/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 限流算法
+const (
+	RateLimitTokenBucket   = "token_bucket"   // 令牌桶，允许短时突发
+	RateLimitSlidingWindow = "sliding_window" // 滑动窗口，严格限制窗口内的请求数
+)
+
+// RateLimitRule 限流规则
+type RateLimitRule struct {
+	Name      string // 规则名称，用于计数键和监控指标
+	Algorithm string
+	Limit     int // 每个窗口内允许的请求数，0 表示不限制
+	Window    time.Duration
+}
+
+// RateLimiter 请求限流服务
+type RateLimiter struct {
+	store repository.RateLimitStore
+}
+
+// NewRateLimiter 创建请求限流服务
+func NewRateLimiter(store repository.RateLimitStore) *RateLimiter {
+	return &RateLimiter{store: store}
+}
+
+// Allow 按规则判定 key 对应的请求是否放行
+// 计数存储不可用时放行并记录日志，避免限流故障导致服务整体不可用
+func (l *RateLimiter) Allow(ctx context.Context, rule RateLimitRule, key string) *repository.RateLimitResult {
+	storeKey := rule.Name + ":" + key
+
+	var result *repository.RateLimitResult
+	var err error
+	if rule.Algorithm == RateLimitSlidingWindow {
+		result, err = l.store.SlidingWindow(ctx, storeKey, rule.Limit, rule.Window)
+	} else {
+		result, err = l.store.TokenBucket(ctx, storeKey, rule.Limit, rule.Window)
+	}
+	if err != nil {
+		logrus.WithError(err).WithField("rule", rule.Name).Warn("限流计数失败，本次请求放行")
+		return &repository.RateLimitResult{Allowed: true, Remaining: rule.Limit}
+	}
+	return result
+}
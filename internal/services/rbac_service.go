@@ -0,0 +1,340 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrRBACPolicyNotConfigured 未配置策略文件，无法重新加载
+	ErrRBACPolicyNotConfigured = utils.NewAppError("RBAC_POLICY_NOT_CONFIGURED", http.StatusConflict, "未配置 RBAC 策略文件")
+	// ErrInvalidRBACPolicy 策略文件无法读取或内容不合法，属于服务端配置错误，具体原因只记录在日志和审计事件中
+	ErrInvalidRBACPolicy = utils.NewAppError("INVALID_RBAC_POLICY", http.StatusInternalServerError, "RBAC 策略文件无效，已保留上一版策略")
+)
+
+// wildcard 通配符，可用于权限、绑定主体和租户
+const wildcard = "*"
+
+// compiledPolicy 预先展开角色继承后的策略，加载后只读
+type compiledPolicy struct {
+	// rolePermissions 角色展开继承后的全部权限
+	rolePermissions map[string][]string
+	// bindings 按主体索引的角色绑定
+	bindings map[string][]models.RoleBinding
+}
+
+// RBACService 基于角色的访问控制服务
+// 策略从 JSON 文件加载，文件变更后自动重新加载，加载失败时保留上一版策略
+type RBACService struct {
+	path    string
+	audit   AuditRecorder
+	policy  atomic.Pointer[compiledPolicy]
+	mu      sync.Mutex
+	modTime time.Time
+	digest  string // 当前策略文件内容的 SHA-256，审计时用于标识策略版本
+}
+
+// NewRBACService 创建访问控制服务，path 为空时使用空策略（所有权限检查均拒绝）
+func NewRBACService(path string, audit AuditRecorder) (*RBACService, error) {
+	s := &RBACService{path: path, audit: audit}
+	s.policy.Store(&compiledPolicy{
+		rolePermissions: map[string][]string{},
+		bindings:        map[string][]models.RoleBinding{},
+	})
+
+	if path == "" {
+		logrus.Warn("未配置 RBAC 策略文件，所有需要权限的接口都将拒绝访问")
+		return s, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload 重新加载策略文件并记录审计事件，actorID 为触发重新加载的用户，自动重新加载时为空
+func (s *RBACService) Reload(ctx context.Context, actorID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.path == "" {
+		return ErrRBACPolicyNotConfigured
+	}
+
+	metadata := map[string]string{
+		"path":            s.path,
+		"previous_sha256": s.digest,
+	}
+	policy, err := s.load()
+	eventType := models.AuditPolicyReloaded
+	if err != nil {
+		eventType = models.AuditPolicyReloadFailed
+		metadata["error"] = err.Error()
+	} else {
+		metadata["sha256"] = s.digest
+		metadata["roles"] = strconv.Itoa(len(policy.Roles))
+		metadata["bindings"] = strconv.Itoa(len(policy.Bindings))
+	}
+	s.audit.Record(ctx, &models.AuditEvent{
+		Type:       eventType,
+		ActorID:    actorID,
+		Subject:    "rbac_policy",
+		Metadata:   metadata,
+		OccurredAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRBACPolicy, err)
+	}
+	return nil
+}
+
+// load 读取并编译策略文件，调用方需持有 s.mu
+func (s *RBACService) load() (*models.Policy, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("读取 RBAC 策略文件失败: %w", err)
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("读取 RBAC 策略文件失败: %w", err)
+	}
+
+	var policy models.Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("解析 RBAC 策略文件失败: %w", err)
+	}
+
+	compiled, err := compilePolicy(&policy)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	s.policy.Store(compiled)
+	s.modTime = info.ModTime()
+	s.digest = hex.EncodeToString(sum[:])
+
+	logrus.WithFields(logrus.Fields{
+		"path":     s.path,
+		"roles":    len(policy.Roles),
+		"bindings": len(policy.Bindings),
+	}).Info("RBAC 策略已加载")
+	return &policy, nil
+}
+
+// Watch 定期检查策略文件修改时间，变更时自动重新加载，直到 ctx 取消
+func (s *RBACService) Watch(ctx context.Context, interval time.Duration) {
+	if s.path == "" || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := os.Stat(s.path)
+			if err != nil {
+				logrus.WithError(err).Warn("检查 RBAC 策略文件失败")
+				continue
+			}
+
+			s.mu.Lock()
+			changed := !info.ModTime().Equal(s.modTime)
+			s.mu.Unlock()
+
+			if changed {
+				if err := s.Reload(ctx, ""); err != nil {
+					logrus.WithError(err).Error("重新加载 RBAC 策略失败，继续使用上一版策略")
+				}
+			}
+		}
+	}
+}
+
+// Roles 查询主体在租户下拥有的角色
+func (s *RBACService) Roles(subject, tenantID string) []string {
+	policy := s.policy.Load()
+
+	seen := make(map[string]bool)
+	for _, binding := range policy.matchBindings(subject, tenantID) {
+		seen[binding.Role] = true
+	}
+
+	roles := make([]string, 0, len(seen))
+	for role := range seen {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// Permissions 查询主体在租户下拥有的全部权限
+func (s *RBACService) Permissions(subject, tenantID string) []string {
+	policy := s.policy.Load()
+
+	seen := make(map[string]bool)
+	for _, binding := range policy.matchBindings(subject, tenantID) {
+		for _, perm := range policy.rolePermissions[binding.Role] {
+			seen[perm] = true
+		}
+	}
+
+	perms := make([]string, 0, len(seen))
+	for perm := range seen {
+		perms = append(perms, perm)
+	}
+	sort.Strings(perms)
+	return perms
+}
+
+// HasPermission 判断主体在租户下是否拥有指定权限
+func (s *RBACService) HasPermission(subject, tenantID, permission string) bool {
+	policy := s.policy.Load()
+
+	for _, binding := range policy.matchBindings(subject, tenantID) {
+		for _, granted := range policy.rolePermissions[binding.Role] {
+			if permissionMatches(granted, permission) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasSystemPermission 判断主体是否拥有平台级权限，只有租户为 * 的绑定生效，用于跨租户的管理操作
+func (s *RBACService) HasSystemPermission(subject, permission string) bool {
+	policy := s.policy.Load()
+
+	for _, binding := range policy.matchBindings(subject, wildcard) {
+		for _, granted := range policy.rolePermissions[binding.Role] {
+			if permissionMatches(granted, permission) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchBindings 查找适用于主体和租户的绑定，tenantID 为 * 时只匹配租户为 * 的绑定
+func (p *compiledPolicy) matchBindings(subject, tenantID string) []models.RoleBinding {
+	var result []models.RoleBinding
+	for _, key := range []string{subject, wildcard} {
+		for _, binding := range p.bindings[key] {
+			if binding.Tenant == wildcard || binding.Tenant == tenantID {
+				result = append(result, binding)
+			}
+		}
+	}
+	return result
+}
+
+// compilePolicy 校验策略并展开角色继承
+func compilePolicy(policy *models.Policy) (*compiledPolicy, error) {
+	compiled := &compiledPolicy{
+		rolePermissions: make(map[string][]string, len(policy.Roles)),
+		bindings:        make(map[string][]models.RoleBinding),
+	}
+
+	for name, role := range policy.Roles {
+		for _, perm := range role.Permissions {
+			if err := validatePermission(perm); err != nil {
+				return nil, fmt.Errorf("角色 %s: %w", name, err)
+			}
+		}
+		perms, err := expandRole(policy, name, map[string]bool{})
+		if err != nil {
+			return nil, err
+		}
+		compiled.rolePermissions[name] = perms
+	}
+
+	for i, binding := range policy.Bindings {
+		if binding.Subject == "" {
+			return nil, fmt.Errorf("第 %d 条角色绑定缺少 subject", i+1)
+		}
+		// 租户必须显式填写，避免遗漏 tenant 字段时意外授予全部租户的权限
+		if binding.Tenant == "" {
+			return nil, fmt.Errorf("第 %d 条角色绑定缺少 tenant，所有租户请使用 *", i+1)
+		}
+		if _, exists := policy.Roles[binding.Role]; !exists {
+			return nil, fmt.Errorf("第 %d 条角色绑定引用了不存在的角色: %s", i+1, binding.Role)
+		}
+		compiled.bindings[binding.Subject] = append(compiled.bindings[binding.Subject], binding)
+	}
+
+	return compiled, nil
+}
+
+// expandRole 递归展开角色继承，检测循环继承
+func expandRole(policy *models.Policy, name string, visiting map[string]bool) ([]string, error) {
+	role, exists := policy.Roles[name]
+	if !exists {
+		return nil, fmt.Errorf("角色不存在: %s", name)
+	}
+	if visiting[name] {
+		return nil, fmt.Errorf("角色存在循环继承: %s", name)
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	perms := append([]string{}, role.Permissions...)
+	for _, parent := range role.Inherits {
+		inherited, err := expandRole(policy, parent, visiting)
+		if err != nil {
+			return nil, err
+		}
+		perms = append(perms, inherited...)
+	}
+	return perms, nil
+}
+
+// validatePermission 校验权限格式：* 或 资源:操作
+func validatePermission(perm string) error {
+	if perm == wildcard {
+		return nil
+	}
+	parts := strings.Split(perm, ":")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("权限格式无效: %s", perm)
+	}
+	return nil
+}
+
+// permissionMatches 判断已授予的权限是否覆盖请求的权限
+func permissionMatches(granted, required string) bool {
+	if granted == wildcard || granted == required {
+		return true
+	}
+	grantedResource, grantedAction, ok := strings.Cut(granted, ":")
+	if !ok {
+		return false
+	}
+	requiredResource, requiredAction, ok := strings.Cut(required, ":")
+	if !ok {
+		return false
+	}
+	return (grantedResource == wildcard || grantedResource == requiredResource) &&
+		(grantedAction == wildcard || grantedAction == requiredAction)
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"gin_saas_auth/internal/models"
+)
+
+// rbacTestPolicy 覆盖角色继承、通配权限和租户范围
+const rbacTestPolicy = `{
+  "roles": {
+    "viewer": {"permissions": ["users:read"]},
+    "user_admin": {"permissions": ["users:*"], "inherits": ["viewer"]},
+    "auditor": {"permissions": ["*:read"]},
+    "super_admin": {"permissions": ["*"]}
+  },
+  "bindings": [
+    {"subject": "*", "role": "viewer", "tenant": "*"},
+    {"subject": "alice", "role": "user_admin", "tenant": "acme"},
+    {"subject": "bob", "role": "auditor", "tenant": "globex"},
+    {"subject": "root", "role": "super_admin", "tenant": "*"}
+  ]
+}`
+
+// writePolicy 将策略写入临时文件并返回路径
+func writePolicy(t *testing.T, policy string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(path, []byte(policy), 0o600); err != nil {
+		t.Fatalf("写入策略文件失败: %v", err)
+	}
+	return path
+}
+
+// recordingAudit 记录审计事件，可被多个协程同时调用
+type recordingAudit struct {
+	mu     sync.Mutex
+	events []*models.AuditEvent
+}
+
+func (a *recordingAudit) Record(_ context.Context, event *models.AuditEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.events = append(a.events, event)
+}
+
+// hasEvent 是否记录了指定类型的审计事件
+func (a *recordingAudit) hasEvent(eventType string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, event := range a.events {
+		if event.Type == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRBACHasPermission(t *testing.T) {
+	rbac, err := NewRBACService(writePolicy(t, rbacTestPolicy), nil)
+	if err != nil {
+		t.Fatalf("加载策略失败: %v", err)
+	}
+
+	tests := []struct {
+		subject    string
+		tenantID   string
+		permission string
+		want       bool
+	}{
+		{subject: "anyone", tenantID: "acme", permission: "users:read", want: true},
+		{subject: "anyone", tenantID: "acme", permission: "users:write", want: false},
+		{subject: "alice", tenantID: "acme", permission: "users:delete", want: true},
+		{subject: "alice", tenantID: "globex", permission: "users:delete", want: false},
+		{subject: "bob", tenantID: "globex", permission: "sessions:read", want: true},
+		{subject: "bob", tenantID: "globex", permission: "sessions:write", want: false},
+		{subject: "bob", tenantID: "acme", permission: "sessions:read", want: false},
+		{subject: "root", tenantID: "initech", permission: "tenants:write", want: true},
+	}
+	for _, tt := range tests {
+		if got := rbac.HasPermission(tt.subject, tt.tenantID, tt.permission); got != tt.want {
+			t.Errorf("HasPermission(%s, %s, %s) = %v, want %v", tt.subject, tt.tenantID, tt.permission, got, tt.want)
+		}
+	}
+}
+
+func TestRBACHasSystemPermission(t *testing.T) {
+	rbac, err := NewRBACService(writePolicy(t, rbacTestPolicy), nil)
+	if err != nil {
+		t.Fatalf("加载策略失败: %v", err)
+	}
+
+	tests := []struct {
+		subject    string
+		permission string
+		want       bool
+	}{
+		{subject: "root", permission: "tenants:write", want: true},
+		{subject: "anyone", permission: "users:read", want: true},
+		{subject: "alice", permission: "users:write", want: false},
+		{subject: "bob", permission: "tenants:read", want: false},
+	}
+	for _, tt := range tests {
+		if got := rbac.HasSystemPermission(tt.subject, tt.permission); got != tt.want {
+			t.Errorf("HasSystemPermission(%s, %s) = %v, want %v", tt.subject, tt.permission, got, tt.want)
+		}
+	}
+}
+
+func TestRBACRolesAndPermissions(t *testing.T) {
+	rbac, err := NewRBACService(writePolicy(t, rbacTestPolicy), nil)
+	if err != nil {
+		t.Fatalf("加载策略失败: %v", err)
+	}
+
+	if got, want := rbac.Roles("alice", "acme"), []string{"user_admin", "viewer"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Roles = %v, want %v", got, want)
+	}
+	if got, want := rbac.Permissions("alice", "acme"), []string{"users:*", "users:read"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Permissions = %v, want %v", got, want)
+	}
+	if got, want := rbac.Roles("alice", "globex"), []string{"viewer"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("其他租户 Roles = %v, want %v", got, want)
+	}
+}
+
+func TestRBACRejectsInvalidPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		want   string
+	}{
+		{
+			name:   "empty tenant",
+			policy: `{"roles": {"viewer": {"permissions": ["users:read"]}}, "bindings": [{"subject": "*", "role": "viewer"}]}`,
+			want:   "缺少 tenant",
+		},
+		{
+			name:   "missing subject",
+			policy: `{"roles": {"viewer": {"permissions": ["users:read"]}}, "bindings": [{"role": "viewer", "tenant": "*"}]}`,
+			want:   "缺少 subject",
+		},
+		{
+			name:   "unknown role",
+			policy: `{"roles": {}, "bindings": [{"subject": "*", "role": "viewer", "tenant": "*"}]}`,
+			want:   "不存在的角色",
+		},
+		{
+			name:   "inheritance cycle",
+			policy: `{"roles": {"a": {"permissions": [], "inherits": ["b"]}, "b": {"permissions": [], "inherits": ["a"]}}, "bindings": []}`,
+			want:   "循环继承",
+		},
+		{
+			name:   "invalid permission",
+			policy: `{"roles": {"viewer": {"permissions": ["users"]}}, "bindings": []}`,
+			want:   "权限格式无效",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRBACService(writePolicy(t, tt.policy), nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want 包含 %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRBACReloadKeepsPreviousPolicy(t *testing.T) {
+	path := writePolicy(t, rbacTestPolicy)
+	audit := &recordingAudit{}
+	rbac, err := NewRBACService(path, audit)
+	if err != nil {
+		t.Fatalf("加载策略失败: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"roles": {}, "bindings": [{"subject": "*", "role": "viewer"}]}`), 0o600); err != nil {
+		t.Fatalf("写入策略文件失败: %v", err)
+	}
+	err = rbac.Reload(context.Background(), "admin")
+	if !errors.Is(err, ErrInvalidRBACPolicy) {
+		t.Fatalf("err = %v, want ErrInvalidRBACPolicy", err)
+	}
+	if ErrInvalidRBACPolicy.Status < http.StatusInternalServerError {
+		t.Fatalf("策略文件无效状态码 = %d, want 5xx", ErrInvalidRBACPolicy.Status)
+	}
+	if !rbac.HasPermission("alice", "acme", "users:delete") {
+		t.Fatal("加载失败后应保留上一版策略")
+	}
+	if len(audit.events) != 1 || audit.events[0].Type != models.AuditPolicyReloadFailed {
+		t.Fatalf("审计事件 = %v, want 一条 %s", audit.events, models.AuditPolicyReloadFailed)
+	}
+}
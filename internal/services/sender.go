@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrChannelUnavailable 未启用的发送渠道
+var ErrChannelUnavailable = utils.NewAppError("CHANNEL_UNAVAILABLE", http.StatusBadRequest, "该验证渠道未启用")
+
+// Message 发送给用户的邮件或短信
+type Message struct {
+	Channel string // models.ChannelEmail 或 models.ChannelSMS
+	To      string
+	Subject string // 仅邮件使用
+	Body    string
+}
+
+// Sender 消息发送接口，接入其他邮件或短信服务商时实现该接口
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// ChannelSender 按渠道分发消息，未配置的渠道返回 ErrChannelUnavailable
+type ChannelSender map[string]Sender
+
+// Send 按消息渠道选择发送方式
+func (s ChannelSender) Send(ctx context.Context, msg *Message) error {
+	sender, ok := s[msg.Channel]
+	if !ok {
+		return ErrChannelUnavailable
+	}
+	return sender.Send(ctx, msg)
+}
+
+// Supports 判断渠道是否已启用
+func (s ChannelSender) Supports(channel string) bool {
+	_, ok := s[channel]
+	return ok
+}
+
+// NewSender 根据配置创建邮件和短信的发送方式
+func NewSender(cfg *config.Config) (ChannelSender, error) {
+	senders := ChannelSender{}
+	var logSender *LogSender
+	newLogSender := func() *LogSender {
+		if logSender == nil {
+			logSender = NewLogSender(cfg.Verification.OutboxFile)
+		}
+		return logSender
+	}
+
+	switch cfg.Verification.EmailSender {
+	case config.SenderLog:
+		senders[models.ChannelEmail] = newLogSender()
+	case config.SenderSMTP:
+		smtpSender, err := NewSMTPSender(cfg.SMTP)
+		if err != nil {
+			return nil, err
+		}
+		senders[models.ChannelEmail] = smtpSender
+	case config.SenderNone, "":
+	default:
+		return nil, fmt.Errorf("不支持的邮件发送方式: %s", cfg.Verification.EmailSender)
+	}
+
+	switch cfg.Verification.SMSSender {
+	case config.SenderLog:
+		senders[models.ChannelSMS] = newLogSender()
+	case config.SenderNone, "":
+	default:
+		return nil, fmt.Errorf("不支持的短信发送方式: %s", cfg.Verification.SMSSender)
+	}
+
+	if logSender != nil && cfg.IsProduction() {
+		logrus.Warn("验证码使用 log 方式发送，不会真正送达用户，仅适用于开发和测试环境")
+	}
+	return senders, nil
+}
+
+// LogSender 将消息写入文件（每行一条 JSON）或日志，用于本地开发和测试
+// 测试可读取文件最后一行获取验证码
+type LogSender struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewLogSender 创建日志发送方式，path 为空时输出到日志
+func NewLogSender(path string) *LogSender {
+	return &LogSender{path: path}
+}
+
+// outboxEntry 写入文件的消息记录
+type outboxEntry struct {
+	SentAt  time.Time `json:"sent_at"`
+	Channel string    `json:"channel"`
+	To      string    `json:"to"`
+	Subject string    `json:"subject,omitempty"`
+	Body    string    `json:"body"`
+}
+
+// Send 写入消息
+func (s *LogSender) Send(ctx context.Context, msg *Message) error {
+	if s.path == "" {
+		logrus.WithFields(logrus.Fields{
+			"channel": msg.Channel,
+			"to":      msg.To,
+			"subject": msg.Subject,
+		}).Info(msg.Body)
+		return nil
+	}
+
+	line, err := json.Marshal(outboxEntry{
+		SentAt:  time.Now().UTC(),
+		Channel: msg.Channel,
+		To:      msg.To,
+		Subject: msg.Subject,
+		Body:    msg.Body,
+	})
+	if err != nil {
+		return fmt.Errorf("序列化消息失败: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("打开消息文件失败: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("写入消息文件失败: %w", err)
+	}
+	return nil
+}
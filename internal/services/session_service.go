@@ -0,0 +1,346 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/repository"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrInvalidRefreshToken 刷新令牌无效、已过期或已撤销
+	ErrInvalidRefreshToken = utils.NewAppError("INVALID_REFRESH_TOKEN", http.StatusUnauthorized, "刷新令牌无效或已过期")
+	// ErrRefreshTokenReused 已轮换的刷新令牌被再次使用，整个会话已被撤销
+	ErrRefreshTokenReused = utils.NewAppError("REFRESH_TOKEN_REUSED", http.StatusUnauthorized, "刷新令牌已被使用，会话已撤销，请重新登录")
+	// ErrSessionNotFound 会话不存在
+	ErrSessionNotFound = utils.NewAppError("SESSION_NOT_FOUND", http.StatusNotFound, "会话不存在")
+	// ErrInvalidCSRFToken CSRF 令牌缺失或不属于当前会话
+	ErrInvalidCSRFToken = utils.NewAppError("INVALID_CSRF_TOKEN", http.StatusForbidden, "CSRF 令牌无效或缺失")
+)
+
+// refreshTokenBytes 刷新令牌的随机字节数
+const refreshTokenBytes = 32
+
+// ClientInfo 发起请求的客户端信息
+type ClientInfo struct {
+	UserAgent string
+	ClientIP  string
+	TenantID  string // 请求所属租户，用于审计
+}
+
+// AuthResult 登录或刷新成功后返回的令牌信息
+type AuthResult struct {
+	User         *models.User `json:"user"`
+	AccessToken  string       `json:"access_token"`
+	RefreshToken string       `json:"refresh_token"`
+	TokenType    string       `json:"token_type"`
+	ExpiresIn    int64        `json:"expires_in"`
+	SessionID    string       `json:"session_id"`
+
+	// RefreshExpiresAt 刷新令牌过期时间，用于设置 Cookie 有效期
+	RefreshExpiresAt time.Time `json:"-"`
+}
+
+// SessionView 会话列表展示信息
+type SessionView struct {
+	*models.Session
+	Current bool `json:"current"`
+}
+
+// SessionService 会话与刷新令牌服务
+// 刷新令牌每次使用都会轮换，已轮换的令牌再次出现视为泄露，撤销整个会话
+type SessionService struct {
+	sessions    repository.SessionRepository
+	users       repository.UserRepository
+	tokens      *TokenService
+	audit       AuditRecorder
+	refreshTTL  time.Duration
+	maxLifetime time.Duration
+}
+
+// NewSessionService 创建会话服务
+func NewSessionService(cfg *config.Config, sessions repository.SessionRepository, users repository.UserRepository, tokens *TokenService, audit AuditRecorder) *SessionService {
+	return &SessionService{
+		sessions:    sessions,
+		users:       users,
+		tokens:      tokens,
+		audit:       audit,
+		refreshTTL:  cfg.Auth.RefreshTokenTTL,
+		maxLifetime: cfg.Auth.SessionMaxLifetime,
+	}
+}
+
+// StartSession 为已认证用户创建会话并签发令牌，amr 为本次登录使用的认证方式
+func (s *SessionService) StartSession(ctx context.Context, user *models.User, amr []string, client ClientInfo) (*AuthResult, error) {
+	now := time.Now()
+	session := &models.Session{
+		ID:         utils.NewID(),
+		UserID:     user.ID,
+		UserAgent:  client.UserAgent,
+		ClientIP:   client.ClientIP,
+		AMR:        amr,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(s.maxLifetime),
+	}
+
+	if err := s.sessions.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":    user.ID,
+		"session_id": session.ID,
+		"client_ip":  client.ClientIP,
+	}).Info("用户会话已创建")
+
+	return s.issue(ctx, user, session, client, "login", now)
+}
+
+// Refresh 使用刷新令牌换取新的令牌对
+func (s *SessionService) Refresh(ctx context.Context, rawToken string, client ClientInfo) (*AuthResult, error) {
+	now := time.Now()
+
+	token, err := s.sessions.GetRefreshToken(ctx, utils.HashToken(rawToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	session, err := s.sessions.GetSession(ctx, token.SessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if !session.IsActive(now) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// 已轮换的令牌再次出现，说明令牌可能已泄露
+	if token.UsedAt != nil {
+		s.revokeFamily(ctx, session, client, now)
+		return nil, ErrRefreshTokenReused
+	}
+	if !now.Before(token.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// 并发请求使用同一令牌时只有一个能标记成功，其余同样视为重用
+	if err := s.sessions.MarkRefreshTokenUsed(ctx, token.ID, now); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			s.revokeFamily(ctx, session, client, now)
+			return nil, ErrRefreshTokenReused
+		}
+		return nil, err
+	}
+
+	user, err := s.users.GetByID(ctx, session.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if !user.IsActive() {
+		return nil, ErrUserDisabled
+	}
+
+	if err := s.sessions.TouchSession(ctx, session.ID, now, client.UserAgent, client.ClientIP); err != nil {
+		return nil, err
+	}
+
+	return s.issue(ctx, user, session, client, "refresh_token", now)
+}
+
+// Logout 使用刷新令牌注销所属会话
+func (s *SessionService) Logout(ctx context.Context, rawToken string, client ClientInfo) error {
+	token, err := s.sessions.GetRefreshToken(ctx, utils.HashToken(rawToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		return err
+	}
+
+	if err := s.sessions.RevokeSession(ctx, token.SessionID, time.Now()); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+
+	s.record(ctx, models.AuditTokenRevoked, token.UserID, token.SessionID, client, map[string]string{
+		"reason": "logout",
+	})
+	return nil
+}
+
+// CSRFToken 为会话签发 CSRF 令牌，供 Cookie 会话的浏览器客户端在写请求中回传
+func (s *SessionService) CSRFToken(sessionID string) (string, error) {
+	return s.tokens.IssueCSRFToken(sessionID)
+}
+
+// VerifyRefreshCSRF 校验 CSRF 令牌是否属于刷新令牌所在的会话
+// 通过 Cookie 刷新或注销时调用，须在使用刷新令牌之前校验
+func (s *SessionService) VerifyRefreshCSRF(ctx context.Context, rawToken, csrfToken string) error {
+	token, err := s.sessions.GetRefreshToken(ctx, utils.HashToken(rawToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		return err
+	}
+	if csrfToken == "" || !s.tokens.VerifyCSRFToken(token.SessionID, csrfToken) {
+		return ErrInvalidCSRFToken
+	}
+	return nil
+}
+
+// ListSessions 查询用户的有效会话，currentSessionID 对应的会话会被标记为当前会话
+func (s *SessionService) ListSessions(ctx context.Context, userID, currentSessionID string) ([]SessionView, error) {
+	sessions, err := s.sessions.ListSessionsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	views := make([]SessionView, 0, len(sessions))
+	for _, session := range sessions {
+		if !session.IsActive(now) {
+			continue
+		}
+		views = append(views, SessionView{
+			Session: session,
+			Current: session.ID == currentSessionID,
+		})
+	}
+	return views, nil
+}
+
+// RevokeSession 撤销用户的指定会话
+func (s *SessionService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	session, err := s.sessions.GetSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+	// 不允许撤销其他用户的会话，按不存在处理
+	if session.UserID != userID {
+		return ErrSessionNotFound
+	}
+
+	if err := s.sessions.RevokeSession(ctx, sessionID, time.Now()); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":    userID,
+		"session_id": sessionID,
+	}).Info("用户会话已撤销")
+	return nil
+}
+
+// RevokeAllSessions 撤销用户的所有会话，用于修改密码或重置密码后强制下线
+func (s *SessionService) RevokeAllSessions(ctx context.Context, userID string) (int, error) {
+	count, err := s.sessions.RevokeUserSessions(ctx, userID, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id": userID,
+		"count":   count,
+	}).Info("用户所有会话已撤销")
+	return count, nil
+}
+
+// issue 在会话下签发新的访问令牌和刷新令牌
+func (s *SessionService) issue(ctx context.Context, user *models.User, session *models.Session, client ClientInfo, grant string, now time.Time) (*AuthResult, error) {
+	rawRefresh, err := utils.GenerateRandomToken(refreshTokenBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshExpiresAt := now.Add(s.refreshTTL)
+	if refreshExpiresAt.After(session.ExpiresAt) {
+		refreshExpiresAt = session.ExpiresAt
+	}
+
+	err = s.sessions.SaveRefreshToken(ctx, &models.RefreshToken{
+		ID:        utils.NewID(),
+		SessionID: session.ID,
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(rawRefresh),
+		ExpiresAt: refreshExpiresAt,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, expiresAt, err := s.tokens.IssueAccessToken(user, session)
+	if err != nil {
+		return nil, err
+	}
+
+	client.TenantID = user.TenantID
+	s.record(ctx, models.AuditTokenIssued, user.ID, session.ID, client, map[string]string{
+		"grant": grant,
+		"amr":   strings.Join(session.AMR, ","),
+	})
+
+	return &AuthResult{
+		User:         user,
+		AccessToken:  accessToken,
+		RefreshToken: rawRefresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(expiresAt.Sub(now).Seconds()),
+		SessionID:    session.ID,
+
+		RefreshExpiresAt: refreshExpiresAt,
+	}, nil
+}
+
+// revokeFamily 检测到刷新令牌重用时撤销整个会话
+func (s *SessionService) revokeFamily(ctx context.Context, session *models.Session, client ClientInfo, now time.Time) {
+	logrus.WithFields(logrus.Fields{
+		"user_id":    session.UserID,
+		"session_id": session.ID,
+		"client_ip":  client.ClientIP,
+		"user_agent": client.UserAgent,
+	}).Warn("检测到刷新令牌重用，撤销整个会话")
+
+	if err := s.sessions.RevokeSession(ctx, session.ID, now); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		logrus.WithError(err).WithField("session_id", session.ID).Error("撤销会话失败")
+	}
+
+	s.record(ctx, models.AuditTokenReuseDetected, session.UserID, session.ID, client, nil)
+}
+
+// record 记录会话相关的审计事件，会话由用户本人操作，操作者即会话所属用户
+func (s *SessionService) record(ctx context.Context, eventType, userID, sessionID string, client ClientInfo, metadata map[string]string) {
+	s.audit.Record(ctx, &models.AuditEvent{
+		Type:       eventType,
+		TenantID:   client.TenantID,
+		ActorID:    userID,
+		Subject:    sessionID,
+		ClientIP:   client.ClientIP,
+		UserAgent:  client.UserAgent,
+		Metadata:   metadata,
+		OccurredAt: time.Now(),
+	})
+}
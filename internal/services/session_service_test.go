@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/repository"
+)
+
+// sessionEnv 会话服务测试环境，用户已登录
+type sessionEnv struct {
+	service *SessionService
+	audit   *recordingAudit
+	login   *AuthResult
+}
+
+func newSessionEnv(t *testing.T) *sessionEnv {
+	t.Helper()
+	cfg := newTestConfig(t)
+	ctx := context.Background()
+	users := repository.NewMemoryUserRepository()
+	user := &models.User{ID: "user-1", TenantID: "acme", Email: "session@example.com", Status: models.UserStatusActive, CreatedAt: time.Now()}
+	if err := users.Create(ctx, user); err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+
+	audit := &recordingAudit{}
+	service := NewSessionService(cfg, repository.NewMemorySessionRepository(), users, NewTokenService(cfg), audit)
+	login, err := service.StartSession(ctx, user, []string{models.AMRPassword}, ClientInfo{ClientIP: "203.0.113.1"})
+	if err != nil {
+		t.Fatalf("创建会话失败: %v", err)
+	}
+	return &sessionEnv{service: service, audit: audit, login: login}
+}
+
+func TestSessionRefreshRotatesToken(t *testing.T) {
+	env := newSessionEnv(t)
+	ctx := context.Background()
+
+	rotated, err := env.service.Refresh(ctx, env.login.RefreshToken, ClientInfo{})
+	if err != nil {
+		t.Fatalf("刷新失败: %v", err)
+	}
+	if rotated.RefreshToken == env.login.RefreshToken {
+		t.Fatal("刷新后应签发新的刷新令牌")
+	}
+	if rotated.SessionID != env.login.SessionID {
+		t.Fatalf("会话 ID = %s, want %s", rotated.SessionID, env.login.SessionID)
+	}
+
+	again, err := env.service.Refresh(ctx, rotated.RefreshToken, ClientInfo{})
+	if err != nil {
+		t.Fatalf("使用新刷新令牌失败: %v", err)
+	}
+	if again.RefreshToken == rotated.RefreshToken {
+		t.Fatal("每次刷新都应轮换刷新令牌")
+	}
+}
+
+func TestSessionRefreshReuseRevokesSession(t *testing.T) {
+	env := newSessionEnv(t)
+	ctx := context.Background()
+
+	rotated, err := env.service.Refresh(ctx, env.login.RefreshToken, ClientInfo{})
+	if err != nil {
+		t.Fatalf("刷新失败: %v", err)
+	}
+
+	// 已轮换的令牌再次使用
+	if _, err := env.service.Refresh(ctx, env.login.RefreshToken, ClientInfo{}); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("重用旧令牌 err = %v, want ErrRefreshTokenReused", err)
+	}
+	if !env.audit.hasEvent(models.AuditTokenReuseDetected) {
+		t.Fatal("应记录刷新令牌重用审计事件")
+	}
+	// 整个会话被撤销，最新的令牌同样失效
+	if _, err := env.service.Refresh(ctx, rotated.RefreshToken, ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("会话撤销后 err = %v, want ErrInvalidRefreshToken", err)
+	}
+	sessions, err := env.service.ListSessions(ctx, "user-1", "")
+	if err != nil || len(sessions) != 0 {
+		t.Fatalf("有效会话 = %d, err = %v, want 0", len(sessions), err)
+	}
+}
+
+func TestSessionRefreshConcurrentUse(t *testing.T) {
+	env := newSessionEnv(t)
+	ctx := context.Background()
+
+	const workers = 8
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = env.service.Refresh(ctx, env.login.RefreshToken, ClientInfo{})
+		}()
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, ErrRefreshTokenReused) && !errors.Is(err, ErrInvalidRefreshToken):
+			t.Fatalf("并发刷新 err = %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("成功次数 = %d, want 1", succeeded)
+	}
+}
+
+func TestSessionRefreshRejectsUnknownToken(t *testing.T) {
+	env := newSessionEnv(t)
+	if _, err := env.service.Refresh(context.Background(), "unknown", ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("err = %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestSessionLogoutRevokesRefreshToken(t *testing.T) {
+	env := newSessionEnv(t)
+	ctx := context.Background()
+
+	if err := env.service.Logout(ctx, env.login.RefreshToken, ClientInfo{}); err != nil {
+		t.Fatalf("注销失败: %v", err)
+	}
+	if _, err := env.service.Refresh(ctx, env.login.RefreshToken, ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("注销后 err = %v, want ErrInvalidRefreshToken", err)
+	}
+}
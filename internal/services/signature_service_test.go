@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/repository"
+	"gin_saas_auth/internal/utils"
+)
+
+// signedRequest 构造使用 svc 密钥签名的请求
+func signedRequest(nonce string, signedAt time.Time) (utils.SignedRequest, string) {
+	req := utils.SignedRequest{
+		Method:    "POST",
+		Path:      "/internal/v1/introspect",
+		BodyHash:  utils.HashBody([]byte(`{"token":"abc"}`)),
+		Timestamp: signedAt.Unix(),
+		Nonce:     nonce,
+	}
+	return req, utils.ComputeSignature("svc-secret", req)
+}
+
+func newTestSignatureService(nonces repository.NonceStore) *SignatureService {
+	cfg := &config.Config{Signing: config.SigningConfig{
+		Keys:    map[string]string{"svc": "svc-secret"},
+		MaxSkew: 5 * time.Minute,
+	}}
+	return NewSignatureService(cfg, nonces)
+}
+
+func TestSignatureVerify(t *testing.T) {
+	service := newTestSignatureService(repository.NewMemoryNonceStore())
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		keyID  string
+		mutate func(req *utils.SignedRequest, signature *string)
+		want   error
+	}{
+		{name: "valid", keyID: "svc"},
+		{name: "unknown key", keyID: "other", want: ErrSignatureUnknownKey},
+		{name: "missing nonce", keyID: "svc", mutate: func(req *utils.SignedRequest, _ *string) { req.Nonce = "" }, want: ErrSignatureMissing},
+		{name: "short nonce", keyID: "svc", mutate: func(req *utils.SignedRequest, _ *string) { req.Nonce = "short" }, want: ErrSignatureInvalid},
+		{name: "expired", keyID: "svc", mutate: func(req *utils.SignedRequest, _ *string) { req.Timestamp = now.Add(-10 * time.Minute).Unix() }, want: ErrSignatureExpired},
+		{name: "tampered body", keyID: "svc", mutate: func(req *utils.SignedRequest, _ *string) { req.BodyHash = utils.HashBody([]byte("{}")) }, want: ErrSignatureInvalid},
+		{name: "tampered signature", keyID: "svc", mutate: func(_ *utils.SignedRequest, signature *string) { *signature += "0" }, want: ErrSignatureInvalid},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, signature := signedRequest("nonce-0123456789-"+strconv.Itoa(i), now)
+			if tt.mutate != nil {
+				tt.mutate(&req, &signature)
+			}
+			if err := service.Verify(context.Background(), tt.keyID, signature, req); !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignatureVerifyRejectsReplayAcrossInstances(t *testing.T) {
+	// 两个实例共享随机数存储，模拟多实例部署使用同一 Redis
+	nonces := repository.NewMemoryNonceStore()
+	first := newTestSignatureService(nonces)
+	second := newTestSignatureService(nonces)
+	req, signature := signedRequest("nonce-0123456789abcdef", time.Now())
+
+	if err := first.Verify(context.Background(), "svc", signature, req); err != nil {
+		t.Fatalf("首次请求校验失败: %v", err)
+	}
+	if err := second.Verify(context.Background(), "svc", signature, req); !errors.Is(err, ErrSignatureReplayed) {
+		t.Fatalf("重放到其他实例 err = %v, want ErrSignatureReplayed", err)
+	}
+}
+
+func TestSignatureVerifyDoesNotRememberForgedNonce(t *testing.T) {
+	service := newTestSignatureService(repository.NewMemoryNonceStore())
+	req, signature := signedRequest("nonce-0123456789abcdef", time.Now())
+
+	if err := service.Verify(context.Background(), "svc", "forged", req); !errors.Is(err, ErrSignatureInvalid) {
+		t.Fatalf("伪造签名 err = %v, want ErrSignatureInvalid", err)
+	}
+	if err := service.Verify(context.Background(), "svc", signature, req); err != nil {
+		t.Fatalf("伪造请求不应占用随机数: %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/repository"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 租户相关错误
+var (
+	// ErrTenantNotFound 租户不存在
+	ErrTenantNotFound = utils.NewAppError("TENANT_NOT_FOUND", http.StatusNotFound, "租户不存在")
+	// ErrTenantSuspended 租户已停用
+	ErrTenantSuspended = utils.NewAppError("TENANT_SUSPENDED", http.StatusForbidden, "租户已停用")
+	// ErrTenantExists 租户已存在
+	ErrTenantExists = utils.NewAppError("TENANT_EXISTS", http.StatusConflict, "租户已存在")
+	// ErrInvalidTenantID 租户标识格式错误
+	ErrInvalidTenantID = utils.NewAppError("INVALID_TENANT_ID", http.StatusBadRequest, "租户标识只能包含小写字母、数字和连字符，且不超过 63 个字符")
+)
+
+// tenantIDPattern 租户标识同时用作子域名，遵循 DNS 标签规则
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// cachedTenant 缓存的租户信息
+type cachedTenant struct {
+	tenant    *models.Tenant
+	expiresAt time.Time
+}
+
+// TenantService 租户服务，每个请求都需要解析租户，因此对查询结果做短期缓存
+type TenantService struct {
+	tenants  repository.TenantRepository
+	cacheTTL time.Duration
+
+	mu    sync.RWMutex
+	cache map[string]cachedTenant
+}
+
+// NewTenantService 创建租户服务
+func NewTenantService(tenants repository.TenantRepository, cacheTTL time.Duration) *TenantService {
+	return &TenantService{
+		tenants:  tenants,
+		cacheTTL: cacheTTL,
+		cache:    make(map[string]cachedTenant),
+	}
+}
+
+// ValidTenantID 判断租户标识格式是否合法
+func ValidTenantID(id string) bool {
+	return tenantIDPattern.MatchString(id)
+}
+
+// EnsureDefault 确保默认租户存在，用于内存存储或未执行迁移的数据库
+func (s *TenantService) EnsureDefault(ctx context.Context, id string) error {
+	if id == "" {
+		return nil
+	}
+	_, err := s.Create(ctx, id, "默认租户")
+	if err != nil && !errors.Is(err, ErrTenantExists) {
+		return fmt.Errorf("创建默认租户失败: %w", err)
+	}
+	return nil
+}
+
+// Resolve 解析请求中的租户，租户不存在或已停用时返回错误
+func (s *TenantService) Resolve(ctx context.Context, id string) (*models.Tenant, error) {
+	if !ValidTenantID(id) {
+		return nil, ErrTenantNotFound
+	}
+
+	tenant, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !tenant.IsActive() {
+		return nil, ErrTenantSuspended
+	}
+	return tenant, nil
+}
+
+// Get 查询租户，优先读取缓存
+func (s *TenantService) Get(ctx context.Context, id string) (*models.Tenant, error) {
+	now := time.Now()
+
+	s.mu.RLock()
+	entry, ok := s.cache[id]
+	s.mu.RUnlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.tenant, nil
+	}
+
+	tenant, err := s.tenants.GetTenant(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrTenantNotFound
+		}
+		return nil, fmt.Errorf("查询租户失败: %w", err)
+	}
+
+	s.mu.Lock()
+	s.cache[id] = cachedTenant{tenant: tenant, expiresAt: now.Add(s.cacheTTL)}
+	s.mu.Unlock()
+	return tenant, nil
+}
+
+// List 查询全部租户
+func (s *TenantService) List(ctx context.Context) ([]*models.Tenant, error) {
+	tenants, err := s.tenants.ListTenants(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("查询租户列表失败: %w", err)
+	}
+	return tenants, nil
+}
+
+// Create 创建租户
+func (s *TenantService) Create(ctx context.Context, id, name string) (*models.Tenant, error) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if !ValidTenantID(id) {
+		return nil, ErrInvalidTenantID
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = id
+	}
+
+	now := time.Now()
+	tenant := &models.Tenant{
+		ID:        id,
+		Name:      name,
+		Status:    models.TenantStatusActive,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.tenants.CreateTenant(ctx, tenant); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrTenantExists
+		}
+		return nil, fmt.Errorf("创建租户失败: %w", err)
+	}
+
+	logrus.WithField("tenant_id", id).Info("租户创建成功")
+	return tenant, nil
+}
+
+// UpdateStatus 启用或停用租户，立即清除该租户的缓存
+func (s *TenantService) UpdateStatus(ctx context.Context, id string, status models.TenantStatus) (*models.Tenant, error) {
+	if err := s.tenants.UpdateTenantStatus(ctx, id, status, time.Now()); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrTenantNotFound
+		}
+		return nil, fmt.Errorf("更新租户状态失败: %w", err)
+	}
+
+	s.mu.Lock()
+	delete(s.cache, id)
+	s.mu.Unlock()
+
+	logrus.WithFields(logrus.Fields{
+		"tenant_id": id,
+		"status":    status,
+	}).Info("租户状态已更新")
+	return s.Get(ctx, id)
+}
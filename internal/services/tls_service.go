@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gin_saas_auth/internal/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// tlsMaterial 一组已加载的证书和客户端 CA，加载后只读
+type tlsMaterial struct {
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+}
+
+// CertReloader 服务端证书与客户端 CA 加载器
+// 文件变更后自动重新加载，新连接使用新证书；加载失败时保留上一版证书
+type CertReloader struct {
+	cfg        config.TLSConfig
+	clientAuth tls.ClientAuthType
+	material   atomic.Pointer[tlsMaterial]
+
+	mu       sync.Mutex
+	modTimes map[string]time.Time
+}
+
+// NewCertReloader 创建证书加载器并立即加载一次证书
+func NewCertReloader(cfg *config.Config) (*CertReloader, error) {
+	r := &CertReloader{cfg: cfg.TLS}
+
+	switch cfg.TLS.ClientAuth {
+	case "", config.ClientAuthNone:
+		r.clientAuth = tls.NoClientCert
+	case config.ClientAuthOptional:
+		r.clientAuth = tls.VerifyClientCertIfGiven
+	case config.ClientAuthRequire:
+		r.clientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, fmt.Errorf("不支持的客户端证书校验方式: %s", cfg.TLS.ClientAuth)
+	}
+	if r.clientAuth != tls.NoClientCert && cfg.TLS.ClientCAFile == "" {
+		return nil, fmt.Errorf("校验客户端证书需要配置 TLS_CLIENT_CA_FILE")
+	}
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// TLSConfig 生成 HTTP 服务器使用的 TLS 配置
+// 每次握手时读取当前证书和客户端 CA，证书重新加载后无需重建服务器
+func (r *CertReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			material := r.material.Load()
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*material.cert},
+				ClientAuth:   r.clientAuth,
+				ClientCAs:    material.clientCAs,
+				NextProtos:   []string{"h2", "http/1.1"},
+			}, nil
+		},
+	}
+}
+
+// Reload 重新加载证书、私钥和客户端 CA
+func (r *CertReloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("加载服务端证书失败: %w", err)
+	}
+	material := &tlsMaterial{cert: &cert}
+
+	if r.cfg.ClientCAFile != "" {
+		data, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("读取客户端 CA 证书失败: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("客户端 CA 证书文件中没有有效的证书: %s", r.cfg.ClientCAFile)
+		}
+		material.clientCAs = pool
+	}
+
+	r.material.Store(material)
+	r.modTimes = r.statFiles()
+
+	fields := logrus.Fields{"cert_file": r.cfg.CertFile}
+	if leaf, err := x509.ParseCertificate(cert.Certificate[0]); err == nil {
+		fields["subject"] = leaf.Subject.String()
+		fields["not_after"] = leaf.NotAfter
+	}
+	logrus.WithFields(fields).Info("TLS 证书已加载")
+	return nil
+}
+
+// Watch 定期检查证书文件修改时间，变更时自动重新加载，直到 ctx 取消
+func (r *CertReloader) Watch(ctx context.Context) {
+	if r.cfg.ReloadInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.cfg.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current := r.statFiles()
+
+			r.mu.Lock()
+			changed := false
+			for path, modTime := range current {
+				if !modTime.Equal(r.modTimes[path]) {
+					changed = true
+				}
+			}
+			r.mu.Unlock()
+
+			if changed {
+				if err := r.Reload(); err != nil {
+					logrus.WithError(err).Error("重新加载 TLS 证书失败，继续使用上一版证书")
+				}
+			}
+		}
+	}
+}
+
+// statFiles 读取证书相关文件的修改时间，文件不存在时忽略
+func (r *CertReloader) statFiles() map[string]time.Time {
+	result := make(map[string]time.Time, 3)
+	for _, path := range []string{r.cfg.CertFile, r.cfg.KeyFile, r.cfg.ClientCAFile} {
+		if path == "" {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil {
+			result[path] = info.ModTime()
+		}
+	}
+	return result
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/utils"
+)
+
+// RFC 8693 定义的授权类型和令牌类型标识
+const (
+	GrantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+	TokenTypeAccessToken   = "urn:ietf:params:oauth:token-type:access_token"
+	TokenTypeJWT           = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+// 令牌交换相关错误
+var (
+	// ErrUnsupportedTokenType 不支持的令牌类型
+	ErrUnsupportedTokenType = utils.NewAppError("UNSUPPORTED_TOKEN_TYPE", http.StatusBadRequest, "不支持的令牌类型")
+	// ErrInvalidSubjectToken 待交换的令牌无效或已过期
+	ErrInvalidSubjectToken = utils.NewAppError("INVALID_SUBJECT_TOKEN", http.StatusBadRequest, "待交换的令牌无效或已过期")
+	// ErrExchangeNotAllowed 调用方未被允许交换令牌
+	ErrExchangeNotAllowed = utils.NewAppError("EXCHANGE_NOT_ALLOWED", http.StatusForbidden, "调用方未被允许交换令牌")
+	// ErrInvalidAudience 调用方无权申请该目标受众
+	ErrInvalidAudience = utils.NewAppError("INVALID_AUDIENCE", http.StatusBadRequest, "目标受众无效或调用方无权申请")
+	// ErrInvalidScope 申请的权限范围超出原令牌
+	ErrInvalidScope = utils.NewAppError("INVALID_SCOPE", http.StatusBadRequest, "申请的权限范围超出原令牌")
+)
+
+// TokenExchangeRequest 令牌交换请求
+type TokenExchangeRequest struct {
+	SubjectToken       string
+	SubjectTokenType   string
+	RequestedTokenType string
+	Audience           []string
+	Scopes             []string
+}
+
+// TokenExchangeResult 令牌交换结果
+type TokenExchangeResult struct {
+	AccessToken     string
+	IssuedTokenType string
+	ExpiresIn       int64
+	Scopes          []string
+	Subject         *AccessClaims // 原令牌声明
+}
+
+// TokenExchangeService 令牌交换服务（RFC 8693）
+// 内部服务（如网关）代表用户调用其他服务时，用用户令牌换取面向目标服务、权限范围更小的令牌，
+// 新令牌的 act 声明记录调用方。交换后的令牌只能再由其目标受众继续交换，形成可追溯的调用链
+type TokenExchangeService struct {
+	tokens    *TokenService
+	rbac      *RBACService
+	ttl       time.Duration
+	audiences map[string][]string
+}
+
+// NewTokenExchangeService 创建令牌交换服务
+func NewTokenExchangeService(cfg *config.Config, tokens *TokenService, rbac *RBACService) *TokenExchangeService {
+	return &TokenExchangeService{
+		tokens:    tokens,
+		rbac:      rbac,
+		ttl:       cfg.Auth.ExchangeTokenTTL,
+		audiences: cfg.Auth.ExchangeAudiences,
+	}
+}
+
+// Exchange 校验原令牌并签发面向目标受众的降权令牌，actor 为调用方标识
+func (s *TokenExchangeService) Exchange(ctx context.Context, actor string, req TokenExchangeRequest) (*TokenExchangeResult, error) {
+	allowed, ok := s.audiences[actor]
+	if !ok {
+		return nil, ErrExchangeNotAllowed
+	}
+
+	switch req.SubjectTokenType {
+	case TokenTypeAccessToken, TokenTypeJWT:
+	default:
+		return nil, ErrUnsupportedTokenType
+	}
+	switch req.RequestedTokenType {
+	case "", TokenTypeAccessToken, TokenTypeJWT:
+	default:
+		return nil, ErrUnsupportedTokenType
+	}
+
+	subject, err := s.parseSubjectToken(req.SubjectToken, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.Audience) == 0 {
+		return nil, ErrInvalidAudience
+	}
+	for _, audience := range req.Audience {
+		if !containsOrWildcard(allowed, audience) {
+			return nil, ErrInvalidAudience
+		}
+	}
+
+	scopes, err := s.grantScopes(subject, req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	token, expiresAt, err := s.tokens.IssueExchangedToken(subject, actor, req.Audience, scopes, s.ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	issuedType := req.RequestedTokenType
+	if issuedType == "" {
+		issuedType = TokenTypeAccessToken
+	}
+	return &TokenExchangeResult{
+		AccessToken:     token,
+		IssuedTokenType: issuedType,
+		ExpiresIn:       int64(time.Until(expiresAt).Seconds()),
+		Scopes:          scopes,
+		Subject:         subject,
+	}, nil
+}
+
+// parseSubjectToken 解析待交换的令牌
+// 用户访问令牌可由任意已授权的调用方交换；已交换的令牌只能由其目标受众继续交换
+func (s *TokenExchangeService) parseSubjectToken(token, actor string) (*AccessClaims, error) {
+	if claims, err := s.tokens.ParseAccessToken(token); err == nil {
+		return claims, nil
+	}
+
+	claims, err := s.tokens.ParseExchangedToken(token)
+	if err != nil {
+		return nil, ErrInvalidSubjectToken
+	}
+	for _, audience := range claims.Audience {
+		if audience == actor {
+			return claims, nil
+		}
+	}
+	return nil, ErrInvalidSubjectToken
+}
+
+// grantScopes 计算新令牌的权限范围
+// 未指定时沿用原令牌的权限范围，原令牌不受限时取用户在租户下的全部权限；
+// 指定时每项都必须被原令牌和用户当前权限同时覆盖
+func (s *TokenExchangeService) grantScopes(subject *AccessClaims, requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		if subject.IsExchanged() {
+			return subject.Scopes, nil
+		}
+		return s.rbac.Permissions(subject.Subject, subject.TenantID), nil
+	}
+
+	for _, scope := range requested {
+		if err := validatePermission(scope); err != nil {
+			return nil, ErrInvalidScope
+		}
+		if !subject.AllowsScope(scope) || !s.rbac.HasPermission(subject.Subject, subject.TenantID, scope) {
+			return nil, ErrInvalidScope
+		}
+	}
+	return requested, nil
+}
+
+// containsOrWildcard 判断列表是否包含指定值或通配符
+func containsOrWildcard(list []string, value string) bool {
+	for _, item := range list {
+		if item == wildcard || item == value {
+			return true
+		}
+	}
+	return false
+}
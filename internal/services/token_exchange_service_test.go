@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"gin_saas_auth/internal/models"
+)
+
+// exchangePolicy 用户在 acme 租户拥有订单读写和用户只读权限
+const exchangePolicy = `{
+  "roles": {"clerk": {"permissions": ["orders:read", "orders:write", "users:read"]}},
+  "bindings": [{"subject": "user-1", "role": "clerk", "tenant": "acme"}]
+}`
+
+// exchangeEnv 令牌交换测试环境，gateway 可申请 orders 和 billing，orders 只能申请 billing
+type exchangeEnv struct {
+	service     *TokenExchangeService
+	tokens      *TokenService
+	accessToken string
+}
+
+func newExchangeEnv(t *testing.T) *exchangeEnv {
+	t.Helper()
+	cfg := newTestConfig(t)
+	cfg.Auth.ExchangeAudiences = map[string][]string{
+		"gateway": {"orders", "billing"},
+		"orders":  {"billing"},
+	}
+	rbac, err := NewRBACService(writePolicy(t, exchangePolicy), nil)
+	if err != nil {
+		t.Fatalf("加载策略失败: %v", err)
+	}
+	tokens := NewTokenService(cfg)
+
+	user := &models.User{ID: "user-1", TenantID: "acme", Email: "user@example.com", Status: models.UserStatusActive}
+	session := &models.Session{ID: "session-1", UserID: user.ID, AMR: []string{models.AMRPassword}, ExpiresAt: time.Now().Add(time.Hour)}
+	accessToken, _, err := tokens.IssueAccessToken(user, session)
+	if err != nil {
+		t.Fatalf("签发访问令牌失败: %v", err)
+	}
+	return &exchangeEnv{service: NewTokenExchangeService(cfg, tokens, rbac), tokens: tokens, accessToken: accessToken}
+}
+
+// exchange 以 access_token 类型交换令牌
+func (env *exchangeEnv) exchange(actor, subjectToken string, audience, scopes []string) (*TokenExchangeResult, error) {
+	return env.service.Exchange(context.Background(), actor, TokenExchangeRequest{
+		SubjectToken:     subjectToken,
+		SubjectTokenType: TokenTypeAccessToken,
+		Audience:         audience,
+		Scopes:           scopes,
+	})
+}
+
+func TestTokenExchangeScopes(t *testing.T) {
+	env := newExchangeEnv(t)
+
+	tests := []struct {
+		name   string
+		scopes []string
+		want   []string
+		err    error
+	}{
+		{name: "defaults to user permissions", want: []string{"orders:read", "orders:write", "users:read"}},
+		{name: "narrowed", scopes: []string{"orders:read"}, want: []string{"orders:read"}},
+		{name: "not granted to user", scopes: []string{"orders:delete"}, err: ErrInvalidScope},
+		{name: "wildcard beyond user", scopes: []string{"orders:*"}, err: ErrInvalidScope},
+		{name: "invalid format", scopes: []string{"orders"}, err: ErrInvalidScope},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := env.exchange("gateway", env.accessToken, []string{"orders"}, tt.scopes)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if err == nil && !reflect.DeepEqual(result.Scopes, tt.want) {
+				t.Fatalf("scopes = %v, want %v", result.Scopes, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenExchangeChainCannotWidenScopes(t *testing.T) {
+	env := newExchangeEnv(t)
+
+	first, err := env.exchange("gateway", env.accessToken, []string{"orders"}, []string{"orders:read"})
+	if err != nil {
+		t.Fatalf("首次交换失败: %v", err)
+	}
+
+	// 用户拥有 orders:write，但原令牌只授予了 orders:read
+	if _, err := env.exchange("orders", first.AccessToken, []string{"billing"}, []string{"orders:write"}); !errors.Is(err, ErrInvalidScope) {
+		t.Fatalf("扩大权限范围 err = %v, want ErrInvalidScope", err)
+	}
+
+	second, err := env.exchange("orders", first.AccessToken, []string{"billing"}, nil)
+	if err != nil {
+		t.Fatalf("继续交换失败: %v", err)
+	}
+	if !reflect.DeepEqual(second.Scopes, []string{"orders:read"}) {
+		t.Fatalf("继续交换 scopes = %v, want 沿用原令牌", second.Scopes)
+	}
+
+	claims, err := env.tokens.ParseExchangedToken(second.AccessToken)
+	if err != nil {
+		t.Fatalf("解析交换令牌失败: %v", err)
+	}
+	if claims.Actor == nil || claims.Actor.Subject != "orders" || claims.Actor.Actor == nil || claims.Actor.Actor.Subject != "gateway" {
+		t.Fatalf("act = %+v, want orders <- gateway", claims.Actor)
+	}
+	if claims.Subject != "user-1" || claims.TenantID != "acme" {
+		t.Fatalf("sub = %s, tid = %s", claims.Subject, claims.TenantID)
+	}
+}
+
+func TestTokenExchangeRejects(t *testing.T) {
+	env := newExchangeEnv(t)
+	exchanged, err := env.exchange("gateway", env.accessToken, []string{"orders"}, []string{"orders:read"})
+	if err != nil {
+		t.Fatalf("交换失败: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		actor    string
+		token    string
+		audience []string
+		want     error
+	}{
+		{name: "unknown actor", actor: "crawler", token: env.accessToken, audience: []string{"orders"}, want: ErrExchangeNotAllowed},
+		{name: "audience not allowed", actor: "orders", token: env.accessToken, audience: []string{"orders"}, want: ErrInvalidAudience},
+		{name: "missing audience", actor: "gateway", token: env.accessToken, want: ErrInvalidAudience},
+		{name: "invalid token", actor: "gateway", token: "not-a-jwt", audience: []string{"orders"}, want: ErrInvalidSubjectToken},
+		{name: "exchanged token by non-audience", actor: "gateway", token: exchanged.AccessToken, audience: []string{"billing"}, want: ErrInvalidSubjectToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := env.exchange(tt.actor, tt.token, tt.audience, nil); !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	_, err = env.service.Exchange(context.Background(), "gateway", TokenExchangeRequest{
+		SubjectToken:     env.accessToken,
+		SubjectTokenType: "urn:ietf:params:oauth:token-type:saml2",
+		Audience:         []string{"orders"},
+	})
+	if !errors.Is(err, ErrUnsupportedTokenType) {
+		t.Fatalf("不支持的令牌类型 err = %v, want ErrUnsupportedTokenType", err)
+	}
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidToken 访问令牌无效或已过期
+var ErrInvalidToken = utils.NewAppError("INVALID_TOKEN", http.StatusUnauthorized, "访问令牌无效或已过期")
+
+// 令牌用途，防止不同用途的令牌被混用
+const (
+	tokenUseAccess       = "access"
+	tokenUseMFAChallenge = "mfa_challenge"
+	// tokenUseAPIKey 由 API 密钥换算出的声明，仅在请求上下文中使用，不会签发为 JWT
+	tokenUseAPIKey = "api_key"
+	// tokenUseExchanged 令牌交换签发的令牌，面向其他服务，本服务的接口不接受
+	tokenUseExchanged = "exchanged"
+)
+
+// ActorClaim 令牌交换中代表用户发起调用的服务（RFC 8693 act 声明）
+// 多次交换时嵌套记录之前的调用方，最外层为最近一次交换的调用方
+type ActorClaim struct {
+	Subject string      `json:"sub"`
+	Actor   *ActorClaim `json:"act,omitempty"`
+}
+
+// AccessClaims 访问令牌声明
+type AccessClaims struct {
+	jwt.RegisteredClaims
+	TokenUse  string      `json:"token_use"`
+	TenantID  string      `json:"tid,omitempty"`
+	Email     string      `json:"email,omitempty"`
+	SessionID string      `json:"sid,omitempty"`
+	AMR       []string    `json:"amr,omitempty"`
+	Scopes    []string    `json:"scope,omitempty"`
+	Actor     *ActorClaim `json:"act,omitempty"`
+}
+
+// HasAMR 判断令牌是否包含指定的认证方式
+func (c *AccessClaims) HasAMR(method string) bool {
+	for _, m := range c.AMR {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAPIKey 判断声明是否来自 API 密钥
+func (c *AccessClaims) IsAPIKey() bool {
+	return c.TokenUse == tokenUseAPIKey
+}
+
+// IsExchanged 判断声明是否来自令牌交换
+func (c *AccessClaims) IsExchanged() bool {
+	return c.TokenUse == tokenUseExchanged
+}
+
+// AllowsScope 判断声明的权限范围是否覆盖指定权限，用户令牌不受权限范围限制
+func (c *AccessClaims) AllowsScope(permission string) bool {
+	if !c.IsAPIKey() && !c.IsExchanged() {
+		return true
+	}
+	for _, scope := range c.Scopes {
+		if permissionMatches(scope, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenService 访问令牌签发与校验服务
+type TokenService struct {
+	secret       []byte
+	issuer       string
+	ttl          time.Duration
+	challengeTTL time.Duration
+}
+
+// NewTokenService 创建令牌服务
+func NewTokenService(cfg *config.Config) *TokenService {
+	return &TokenService{
+		secret:       []byte(cfg.Auth.JWTSecret),
+		issuer:       cfg.Auth.JWTIssuer,
+		ttl:          cfg.Auth.AccessTokenTTL,
+		challengeTTL: cfg.Auth.MFAChallengeTTL,
+	}
+}
+
+// IssueAccessToken 为用户在指定会话下签发访问令牌，amr 声明沿用会话登录时的认证方式
+func (s *TokenService) IssueAccessToken(user *models.User, session *models.Session) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.ttl)
+
+	claims := AccessClaims{
+		RegisteredClaims: s.registeredClaims(user.ID, now, expiresAt),
+		TokenUse:         tokenUseAccess,
+		TenantID:         user.TenantID,
+		Email:            user.Email,
+		SessionID:        session.ID,
+		AMR:              session.AMR,
+	}
+
+	signed, err := s.sign(claims)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("签发访问令牌失败: %w", err)
+	}
+	return signed, expiresAt, nil
+}
+
+// ParseAccessToken 校验并解析访问令牌
+func (s *TokenService) ParseAccessToken(tokenString string) (*AccessClaims, error) {
+	return s.parse(tokenString, tokenUseAccess)
+}
+
+// IssueMFAChallenge 第一因素校验通过但需要第二因素时，签发短期有效的挑战令牌，amr 为第一因素的认证方式
+func (s *TokenService) IssueMFAChallenge(user *models.User, amr []string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.challengeTTL)
+
+	claims := AccessClaims{
+		RegisteredClaims: s.registeredClaims(user.ID, now, expiresAt),
+		TokenUse:         tokenUseMFAChallenge,
+		TenantID:         user.TenantID,
+		AMR:              amr,
+	}
+
+	signed, err := s.sign(claims)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("签发 MFA 挑战令牌失败: %w", err)
+	}
+	return signed, expiresAt, nil
+}
+
+// ParseMFAChallenge 校验并解析 MFA 挑战令牌
+func (s *TokenService) ParseMFAChallenge(tokenString string) (*AccessClaims, error) {
+	return s.parse(tokenString, tokenUseMFAChallenge)
+}
+
+// IssueExchangedToken 基于已校验的令牌签发面向目标受众的令牌
+// 用户、租户和认证方式沿用原令牌，actor 记录为最外层 act 声明，有效期不超过原令牌
+func (s *TokenService) IssueExchangedToken(subject *AccessClaims, actor string, audience, scopes []string, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	if subject.ExpiresAt != nil && subject.ExpiresAt.Time.Before(expiresAt) {
+		expiresAt = subject.ExpiresAt.Time
+	}
+
+	registered := s.registeredClaims(subject.Subject, now, expiresAt)
+	registered.Audience = audience
+	claims := AccessClaims{
+		RegisteredClaims: registered,
+		TokenUse:         tokenUseExchanged,
+		TenantID:         subject.TenantID,
+		Email:            subject.Email,
+		SessionID:        subject.SessionID,
+		AMR:              subject.AMR,
+		Scopes:           scopes,
+		Actor:            &ActorClaim{Subject: actor, Actor: subject.Actor},
+	}
+
+	signed, err := s.sign(claims)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("签发交换令牌失败: %w", err)
+	}
+	return signed, expiresAt, nil
+}
+
+// ParseExchangedToken 校验并解析令牌交换签发的令牌
+func (s *TokenService) ParseExchangedToken(tokenString string) (*AccessClaims, error) {
+	return s.parse(tokenString, tokenUseExchanged)
+}
+
+// IssueCSRFToken 为会话签发 CSRF 令牌，格式为 "随机数.签名"，签名绑定会话 ID
+func (s *TokenService) IssueCSRFToken(sessionID string) (string, error) {
+	nonce, err := utils.GenerateRandomHex(16)
+	if err != nil {
+		return "", err
+	}
+	return nonce + "." + s.csrfSignature(sessionID, nonce), nil
+}
+
+// VerifyCSRFToken 校验 CSRF 令牌是否为本服务为该会话签发
+func (s *TokenService) VerifyCSRFToken(sessionID, token string) bool {
+	nonce, signature, ok := strings.Cut(token, ".")
+	if !ok || nonce == "" {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(s.csrfSignature(sessionID, nonce)))
+}
+
+// csrfSignature 计算 CSRF 令牌签名，使用由 JWT 密钥派生的独立密钥
+func (s *TokenService) csrfSignature(sessionID, nonce string) string {
+	key := sha256.Sum256(append([]byte("csrf:"), s.secret...))
+	mac := hmac.New(sha256.New, key[:])
+	mac.Write([]byte(sessionID + "." + nonce))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// AccessTokenTTL 访问令牌有效期
+func (s *TokenService) AccessTokenTTL() time.Duration {
+	return s.ttl
+}
+
+// registeredClaims 构造标准声明
+func (s *TokenService) registeredClaims(subject string, now, expiresAt time.Time) jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		ID:        utils.NewID(),
+		Issuer:    s.issuer,
+		Subject:   subject,
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+}
+
+// sign 使用 HS256 签名
+func (s *TokenService) sign(claims AccessClaims) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+}
+
+// parse 校验签名、签发方、有效期和令牌用途
+func (s *TokenService) parse(tokenString, tokenUse string) (*AccessClaims, error) {
+	claims := &AccessClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(s.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.TokenUse != tokenUse {
+		return nil, fmt.Errorf("%w: 令牌用途不匹配", ErrInvalidToken)
+	}
+	return claims, nil
+}
@@ -0,0 +1,413 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"gin_saas_auth/internal/i18n"
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/repository"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrUserExists 邮箱已被注册
+	ErrUserExists = utils.NewAppError("USER_EXISTS", http.StatusConflict, "该邮箱已被注册")
+	// ErrUserNotFound 用户不存在
+	ErrUserNotFound = utils.NewAppError("USER_NOT_FOUND", http.StatusNotFound, "用户不存在")
+	// ErrInvalidCredentials 邮箱或密码错误
+	ErrInvalidCredentials = utils.NewAppError("INVALID_CREDENTIALS", http.StatusUnauthorized, "邮箱或密码错误")
+	// ErrUserDisabled 用户已被禁用
+	ErrUserDisabled = utils.NewAppError("USER_DISABLED", http.StatusForbidden, "用户已被禁用")
+	// ErrInvalidResetToken 重置令牌无效或已过期
+	ErrInvalidResetToken = utils.NewAppError("INVALID_RESET_TOKEN", http.StatusBadRequest, "重置令牌无效或已过期")
+	// ErrInvalidPassword 密码不符合要求
+	ErrInvalidPassword = utils.NewAppError("INVALID_PASSWORD", http.StatusBadRequest, "密码不符合要求")
+)
+
+// resetTokenBytes 密码重置令牌的随机字节数
+const resetTokenBytes = 32
+
+// UserService 用户账户服务
+type UserService struct {
+	users    repository.UserRepository
+	resets   repository.PasswordResetRepository
+	history  repository.PasswordHistoryRepository
+	policies *PasswordPolicyService
+	hasher   *utils.PasswordHasher
+	resetTTL time.Duration
+
+	// dummyHash 用户不存在时参与校验，使响应耗时与真实校验一致，避免枚举邮箱
+	dummyHash string
+}
+
+// NewUserService 创建用户账户服务
+func NewUserService(users repository.UserRepository, resets repository.PasswordResetRepository, history repository.PasswordHistoryRepository, policies *PasswordPolicyService, hasher *utils.PasswordHasher, resetTTL time.Duration) (*UserService, error) {
+	dummyHash, err := hasher.Hash(utils.NewID())
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserService{
+		users:     users,
+		resets:    resets,
+		history:   history,
+		policies:  policies,
+		hasher:    hasher,
+		resetTTL:  resetTTL,
+		dummyHash: dummyHash,
+	}, nil
+}
+
+// Register 在租户下注册新用户
+func (s *UserService) Register(ctx context.Context, tenantID, email, password string) (*models.User, error) {
+	if err := s.ValidatePassword(ctx, tenantID, password); err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := s.hasher.Hash(password)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user := &models.User{
+		ID:                utils.NewID(),
+		TenantID:          tenantID,
+		Email:             normalizeEmail(email),
+		PasswordHash:      passwordHash,
+		Status:            models.UserStatusActive,
+		PasswordChangedAt: now,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	if err := s.users.Create(ctx, user); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrUserExists
+		}
+		return nil, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":   user.ID,
+		"tenant_id": tenantID,
+	}).Info("新用户注册成功")
+	return user, nil
+}
+
+// RegisterFederated 为首次通过外部身份提供方登录的用户创建本地账户
+// 密码为随机值，用户可通过找回密码设置本地密码；邮箱已由提供方验证
+func (s *UserService) RegisterFederated(ctx context.Context, tenantID, email string) (*models.User, error) {
+	password, err := utils.GenerateRandomToken(resetTokenBytes)
+	if err != nil {
+		return nil, err
+	}
+	passwordHash, err := s.hasher.Hash(password)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user := &models.User{
+		ID:                utils.NewID(),
+		TenantID:          tenantID,
+		Email:             normalizeEmail(email),
+		PasswordHash:      passwordHash,
+		Status:            models.UserStatusActive,
+		EmailVerifiedAt:   &now,
+		PasswordChangedAt: now,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	if err := s.users.Create(ctx, user); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrUserExists
+		}
+		return nil, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":   user.ID,
+		"tenant_id": tenantID,
+	}).Info("外部身份用户注册成功")
+	return user, nil
+}
+
+// Authenticate 在租户下校验邮箱密码，哈希参数变更时透明地重新哈希
+func (s *UserService) Authenticate(ctx context.Context, tenantID, email, password string) (*models.User, error) {
+	user, err := s.users.GetByEmail(ctx, tenantID, normalizeEmail(email))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			s.hasher.Verify(password, s.dummyHash)
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	match, needsRehash, err := s.hasher.Verify(password, user.PasswordHash)
+	if err != nil {
+		return nil, fmt.Errorf("校验密码失败: %w", err)
+	}
+	if !match {
+		return nil, ErrInvalidCredentials
+	}
+	if !user.IsActive() {
+		return nil, ErrUserDisabled
+	}
+
+	if needsRehash {
+		s.rehash(ctx, user, password)
+	}
+
+	return user, nil
+}
+
+// ChangePassword 修改密码（需要校验旧密码）
+func (s *UserService) ChangePassword(ctx context.Context, userID, oldPassword, newPassword string) error {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	match, _, err := s.hasher.Verify(oldPassword, user.PasswordHash)
+	if err != nil {
+		return fmt.Errorf("校验密码失败: %w", err)
+	}
+	if !match {
+		return ErrInvalidCredentials
+	}
+
+	return s.setPassword(ctx, user, newPassword)
+}
+
+// RequestPasswordReset 生成一次性密码重置令牌
+// 邮箱不存在时返回空字符串且不报错，避免泄露账户是否存在
+func (s *UserService) RequestPasswordReset(ctx context.Context, tenantID, email string) (string, error) {
+	user, err := s.users.GetByEmail(ctx, tenantID, normalizeEmail(email))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	rawToken, err := utils.GenerateRandomToken(resetTokenBytes)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	err = s.resets.SaveResetToken(ctx, &models.PasswordResetToken{
+		TokenHash: utils.HashToken(rawToken),
+		UserID:    user.ID,
+		ExpiresAt: now.Add(s.resetTTL),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return rawToken, nil
+}
+
+// ResetPassword 使用一次性令牌重置密码，返回被重置的用户 ID
+// 先按租户密码策略校验，避免新密码不合要求时令牌被消耗
+func (s *UserService) ResetPassword(ctx context.Context, tenantID, rawToken, newPassword string) (string, error) {
+	if err := s.ValidatePassword(ctx, tenantID, newPassword); err != nil {
+		return "", err
+	}
+
+	token, err := s.resets.ConsumeResetToken(ctx, utils.HashToken(rawToken), time.Now())
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return "", ErrInvalidResetToken
+		}
+		return "", err
+	}
+
+	user, err := s.GetUser(ctx, token.UserID)
+	if err != nil {
+		return "", err
+	}
+	if err := s.setPassword(ctx, user, newPassword); err != nil {
+		return "", err
+	}
+	return token.UserID, nil
+}
+
+// ResetPasswordForUser 为已通过验证码校验的用户重置密码
+func (s *UserService) ResetPasswordForUser(ctx context.Context, userID, newPassword string) error {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return s.setPassword(ctx, user, newPassword)
+}
+
+// ValidatePassword 按租户密码策略校验新密码（不含历史密码），用于注册和在消耗验证码之前提前拒绝
+func (s *UserService) ValidatePassword(ctx context.Context, tenantID, password string) error {
+	policy, err := s.policies.Get(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	return s.policies.Check(policy, password)
+}
+
+// PasswordExpired 判断用户密码是否已超过租户策略规定的最长使用期限
+func (s *UserService) PasswordExpired(ctx context.Context, user *models.User) (bool, error) {
+	policy, err := s.policies.Get(ctx, user.TenantID)
+	if err != nil {
+		return false, err
+	}
+	return policy.PasswordExpired(user.PasswordChangedAt, time.Now()), nil
+}
+
+// FindByDestination 根据邮箱或已验证的手机号查询用户，不存在时返回 nil
+func (s *UserService) FindByDestination(ctx context.Context, tenantID, channel, destination string) (*models.User, error) {
+	var user *models.User
+	var err error
+	if channel == models.ChannelSMS {
+		user, err = s.users.GetByPhone(ctx, tenantID, destination)
+	} else {
+		user, err = s.users.GetByEmail(ctx, tenantID, destination)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// MarkEmailVerified 标记用户邮箱已验证
+func (s *UserService) MarkEmailVerified(ctx context.Context, userID string) error {
+	if err := s.users.MarkEmailVerified(ctx, userID, time.Now()); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	logrus.WithField("user_id", userID).Info("用户邮箱已验证")
+	return nil
+}
+
+// SetPhone 绑定验证通过的手机号
+func (s *UserService) SetPhone(ctx context.Context, userID, phone string) error {
+	if err := s.users.UpdatePhone(ctx, userID, phone, time.Now()); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return ErrUserNotFound
+		case errors.Is(err, repository.ErrDuplicate):
+			return ErrPhoneInUse
+		}
+		return err
+	}
+	logrus.WithField("user_id", userID).Info("用户手机号已绑定")
+	return nil
+}
+
+// GetUser 根据 ID 获取用户
+func (s *UserService) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// setPassword 按租户密码策略校验并保存新密码，被替换的密码计入历史
+func (s *UserService) setPassword(ctx context.Context, user *models.User, newPassword string) error {
+	policy, err := s.policies.Get(ctx, user.TenantID)
+	if err != nil {
+		return err
+	}
+	if err := s.policies.Check(policy, newPassword); err != nil {
+		return err
+	}
+	if err := s.checkHistory(ctx, user, policy.HistorySize, newPassword); err != nil {
+		return err
+	}
+
+	passwordHash, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := s.users.UpdatePassword(ctx, user.ID, passwordHash, now); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	// 当前密码本身占历史数量中的一个
+	if keep := policy.HistorySize - 1; keep > 0 {
+		if err := s.history.AddPasswordHistory(ctx, user.ID, user.PasswordHash, now, keep); err != nil {
+			logrus.WithError(err).WithField("user_id", user.ID).Warn("记录历史密码失败")
+		}
+	}
+
+	logrus.WithField("user_id", user.ID).Info("用户密码已更新")
+	return nil
+}
+
+// checkHistory 校验新密码不能与当前密码及最近 size-1 个历史密码相同
+func (s *UserService) checkHistory(ctx context.Context, user *models.User, size int, newPassword string) error {
+	if size <= 0 {
+		return nil
+	}
+
+	hashes := []string{user.PasswordHash}
+	if size > 1 {
+		history, err := s.history.ListPasswordHistory(ctx, user.ID, size-1)
+		if err != nil {
+			return err
+		}
+		hashes = append(hashes, history...)
+	}
+
+	for _, passwordHash := range hashes {
+		match, _, err := s.hasher.Verify(newPassword, passwordHash)
+		if err != nil {
+			return fmt.Errorf("校验历史密码失败: %w", err)
+		}
+		if match {
+			return ErrInvalidPassword.WithDetails(i18n.NewMessage("password.reused", i18n.Params{"count": size}))
+		}
+	}
+	return nil
+}
+
+// rehash 使用当前参数重新哈希密码，失败不影响本次登录
+func (s *UserService) rehash(ctx context.Context, user *models.User, password string) {
+	passwordHash, err := s.hasher.Hash(password)
+	if err != nil {
+		logrus.WithError(err).WithField("user_id", user.ID).Warn("重新哈希密码失败")
+		return
+	}
+
+	// 重新哈希不是用户主动修改密码，保留原有的修改时间
+	if err := s.users.UpdatePassword(ctx, user.ID, passwordHash, user.PasswordChangedAt); err != nil {
+		logrus.WithError(err).WithField("user_id", user.ID).Warn("保存重新哈希的密码失败")
+		return
+	}
+	user.PasswordHash = passwordHash
+	logrus.WithField("user_id", user.ID).Info("密码哈希参数已升级")
+}
+
+// normalizeEmail 规范化邮箱地址
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
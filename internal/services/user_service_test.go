@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/repository"
+	"gin_saas_auth/internal/utils"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// userEnv 用户服务测试环境
+type userEnv struct {
+	service *UserService
+	users   *repository.MemoryUserRepository
+	tenants *repository.MemoryTenantRepository
+}
+
+func newUserEnv(t *testing.T) *userEnv {
+	t.Helper()
+	cfg := newTestConfig(t)
+	users := repository.NewMemoryUserRepository()
+	tenants := repository.NewMemoryTenantRepository()
+	policies, err := NewPasswordPolicyService(cfg, tenants)
+	if err != nil {
+		t.Fatalf("初始化密码策略服务失败: %v", err)
+	}
+	hasher := utils.NewPasswordHasher(utils.Argon2Params{
+		Memory:      cfg.Auth.Argon2Memory,
+		Iterations:  cfg.Auth.Argon2Iterations,
+		Parallelism: cfg.Auth.Argon2Parallelism,
+	})
+	service, err := NewUserService(users, users, users, policies, hasher, cfg.Auth.ResetTokenTTL)
+	if err != nil {
+		t.Fatalf("初始化用户服务失败: %v", err)
+	}
+	return &userEnv{service: service, users: users, tenants: tenants}
+}
+
+// createUser 直接写入使用指定密码哈希的用户
+func (env *userEnv) createUser(t *testing.T, passwordHash string, changedAt time.Time) *models.User {
+	t.Helper()
+	user := &models.User{
+		ID:                "user-1",
+		TenantID:          "acme",
+		Email:             "user@example.com",
+		PasswordHash:      passwordHash,
+		PasswordChangedAt: changedAt,
+		Status:            models.UserStatusActive,
+		CreatedAt:         changedAt,
+	}
+	if err := env.users.Create(context.Background(), user); err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	return user
+}
+
+func TestAuthenticateRehashesLegacyPassword(t *testing.T) {
+	env := newUserEnv(t)
+	ctx := context.Background()
+	legacy, err := bcrypt.GenerateFromPassword([]byte("correct horse battery"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("生成 bcrypt 哈希失败: %v", err)
+	}
+	changedAt := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	env.createUser(t, string(legacy), changedAt)
+
+	if _, err := env.service.Authenticate(ctx, "acme", "User@Example.com", "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("密码错误 err = %v, want ErrInvalidCredentials", err)
+	}
+	stored, _ := env.users.GetByID(ctx, "user-1")
+	if stored.PasswordHash != string(legacy) {
+		t.Fatal("密码错误时不应重新哈希")
+	}
+
+	if _, err := env.service.Authenticate(ctx, "acme", "user@example.com", "correct horse battery"); err != nil {
+		t.Fatalf("登录失败: %v", err)
+	}
+	stored, _ = env.users.GetByID(ctx, "user-1")
+	if !strings.HasPrefix(stored.PasswordHash, "$argon2id$") {
+		t.Fatalf("登录后哈希 = %s, want argon2id", stored.PasswordHash)
+	}
+	if !stored.PasswordChangedAt.Equal(changedAt) {
+		t.Fatalf("重新哈希不应修改密码修改时间: %v, want %v", stored.PasswordChangedAt, changedAt)
+	}
+
+	if _, err := env.service.Authenticate(ctx, "acme", "user@example.com", "correct horse battery"); err != nil {
+		t.Fatalf("使用新哈希登录失败: %v", err)
+	}
+}
+
+func TestAuthenticateRehashesOutdatedParams(t *testing.T) {
+	env := newUserEnv(t)
+	ctx := context.Background()
+	outdated, err := utils.NewPasswordHasher(utils.Argon2Params{Memory: 512, Iterations: 1, Parallelism: 1}).Hash("correct horse battery")
+	if err != nil {
+		t.Fatalf("生成旧参数哈希失败: %v", err)
+	}
+	env.createUser(t, outdated, time.Now())
+
+	if _, err := env.service.Authenticate(ctx, "acme", "user@example.com", "correct horse battery"); err != nil {
+		t.Fatalf("登录失败: %v", err)
+	}
+	stored, _ := env.users.GetByID(ctx, "user-1")
+	if stored.PasswordHash == outdated || !strings.Contains(stored.PasswordHash, "$m=1024,t=1,") {
+		t.Fatalf("登录后哈希 = %s, want 当前参数", stored.PasswordHash)
+	}
+}
+
+func TestAuthenticateUnknownUser(t *testing.T) {
+	env := newUserEnv(t)
+	if _, err := env.service.Authenticate(context.Background(), "acme", "nobody@example.com", "password"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("err = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestChangePasswordRejectsRecentPasswords(t *testing.T) {
+	env := newUserEnv(t)
+	ctx := context.Background()
+	if _, err := env.service.policies.Update(ctx, "acme", &models.PasswordPolicy{MinLength: 8, MaxLength: 64, HistorySize: 3}); err != nil {
+		t.Fatalf("保存租户策略失败: %v", err)
+	}
+	user, err := env.service.Register(ctx, "acme", "user@example.com", "first-password")
+	if err != nil {
+		t.Fatalf("注册失败: %v", err)
+	}
+
+	change := func(oldPassword, newPassword string) error {
+		return env.service.ChangePassword(ctx, user.ID, oldPassword, newPassword)
+	}
+	if err := change("first-password", "second-password"); err != nil {
+		t.Fatalf("修改密码失败: %v", err)
+	}
+	if err := change("second-password", "third-password"); err != nil {
+		t.Fatalf("修改密码失败: %v", err)
+	}
+
+	// 当前密码和最近两个历史密码都不能使用
+	for _, reused := range []string{"third-password", "second-password", "first-password"} {
+		if keys := violationKeys(t, change("third-password", reused)); !reflect.DeepEqual(keys, []string{"password.reused"}) {
+			t.Fatalf("使用 %s violations = %v, want [password.reused]", reused, keys)
+		}
+	}
+
+	if err := change("third-password", "fourth-password"); err != nil {
+		t.Fatalf("修改密码失败: %v", err)
+	}
+	// first-password 已超出最近 3 个密码的范围
+	if err := change("fourth-password", "first-password"); err != nil {
+		t.Fatalf("使用较早的密码 err = %v", err)
+	}
+}
@@ -0,0 +1,313 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/repository"
+	"gin_saas_auth/internal/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrInvalidVerificationCode 验证码错误、已使用或已过期
+	ErrInvalidVerificationCode = utils.NewAppError("INVALID_VERIFICATION_CODE", http.StatusBadRequest, "验证码无效或已过期")
+	// ErrVerificationThrottled 发送过于频繁
+	ErrVerificationThrottled = utils.NewAppError("VERIFICATION_THROTTLED", http.StatusTooManyRequests, "验证码发送过于频繁，请稍后再试")
+	// ErrInvalidPhone 手机号格式错误
+	ErrInvalidPhone = utils.NewAppError("INVALID_PHONE", http.StatusBadRequest, "手机号格式错误，请使用带国家代码的国际格式，例如 +8613800000000")
+	// ErrPhoneInUse 手机号已被其他用户绑定
+	ErrPhoneInUse = utils.NewAppError("PHONE_IN_USE", http.StatusConflict, "该手机号已被其他账户绑定")
+	// ErrEmailNotVerified 邮箱尚未验证
+	ErrEmailNotVerified = utils.NewAppError("EMAIL_NOT_VERIFIED", http.StatusForbidden, "邮箱尚未验证，请先完成邮箱验证")
+)
+
+// phonePattern E.164 格式手机号
+var phonePattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// VerificationRequest 发送验证码请求
+type VerificationRequest struct {
+	TenantID    string
+	UserID      string // 验证码所属用户，为空时只计入发送频率而不发送，用于账户不存在时保持响应一致
+	Channel     string
+	Destination string // 已规范化的邮箱或手机号
+	Purpose     string
+}
+
+// VerificationService 邮箱与手机验证码服务
+// 验证码只保存摘要，每个验证码限定校验次数；发送频率按目标地址统计，
+// 保存在登录失败计数相同的存储中，多实例部署时使用 Redis 共享
+type VerificationService struct {
+	codes  repository.VerificationRepository
+	store  repository.AttemptStore
+	sender ChannelSender
+	audit  AuditRecorder
+	cfg    config.VerificationConfig
+	app    string
+}
+
+// NewVerificationService 创建验证码服务
+func NewVerificationService(cfg *config.Config, codes repository.VerificationRepository, store repository.AttemptStore, sender ChannelSender, audit AuditRecorder) *VerificationService {
+	return &VerificationService{
+		codes:  codes,
+		store:  store,
+		sender: sender,
+		audit:  audit,
+		cfg:    cfg.Verification,
+		app:    cfg.App.Name,
+	}
+}
+
+// Supports 判断渠道是否已启用
+func (s *VerificationService) Supports(channel string) bool {
+	return s.sender.Supports(channel)
+}
+
+// SendCode 生成并发送验证码，发送过于频繁时返回 ErrVerificationThrottled 和需要等待的时间
+func (s *VerificationService) SendCode(ctx context.Context, req VerificationRequest) (time.Duration, error) {
+	if !s.Supports(req.Channel) {
+		return 0, ErrChannelUnavailable
+	}
+	if retryAfter, err := s.throttle(ctx, req.Channel, req.Destination); err != nil {
+		return retryAfter, err
+	}
+	if req.UserID == "" {
+		return 0, nil
+	}
+
+	code, err := utils.GenerateNumericCode(s.cfg.CodeLength)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	record := &models.VerificationCode{
+		ID:          utils.NewID(),
+		TenantID:    req.TenantID,
+		UserID:      req.UserID,
+		Channel:     req.Channel,
+		Destination: req.Destination,
+		Purpose:     req.Purpose,
+		ExpiresAt:   now.Add(s.cfg.CodeTTL),
+		CreatedAt:   now,
+	}
+	record.CodeHash = hashVerificationCode(record.ID, code)
+	if err := s.codes.Save(ctx, record); err != nil {
+		return 0, err
+	}
+
+	if err := s.sender.Send(ctx, s.codeMessage(req, code)); err != nil {
+		return 0, fmt.Errorf("发送验证码失败: %w", err)
+	}
+	s.record(ctx, req, nil)
+	return 0, nil
+}
+
+// SendResetLink 发送密码重置链接，通过发送频率限制后才调用 mint 生成重置令牌，被限流的请求不会产生令牌
+// mint 返回空令牌（账户不存在）时只计入发送频率而不发送
+func (s *VerificationService) SendResetLink(ctx context.Context, req VerificationRequest, mint func(ctx context.Context) (string, error)) (time.Duration, error) {
+	if !s.Supports(models.ChannelEmail) {
+		return 0, ErrChannelUnavailable
+	}
+	if retryAfter, err := s.throttle(ctx, models.ChannelEmail, req.Destination); err != nil {
+		return retryAfter, err
+	}
+	token, err := mint(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if token == "" {
+		return 0, nil
+	}
+
+	body := fmt.Sprintf("【%s】您正在重置登录密码，重置令牌为：\n\n%s\n", s.app, token)
+	if link := s.link(req, url.Values{"token": {token}}); link != "" {
+		body = fmt.Sprintf("【%s】您正在重置登录密码，请打开以下链接设置新密码：\n\n%s\n", s.app, link)
+	}
+	err = s.sender.Send(ctx, &Message{
+		Channel: models.ChannelEmail,
+		To:      req.Destination,
+		Subject: "重置密码",
+		Body:    body + "\n如非本人操作，请忽略本邮件。",
+	})
+	if err != nil {
+		return 0, fmt.Errorf("发送重置链接失败: %w", err)
+	}
+	s.record(ctx, req, map[string]string{"method": "link"})
+	return 0, nil
+}
+
+// Verify 校验验证码，成功后验证码失效并返回验证码记录（包含所属用户）
+// 先累加校验次数再比较，并发猜测也不会超过次数限制
+func (s *VerificationService) Verify(ctx context.Context, tenantID, channel, destination, purpose, code string) (*models.VerificationCode, error) {
+	record, err := s.codes.GetLatest(ctx, tenantID, channel, destination, purpose)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidVerificationCode
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if record.ConsumedAt != nil || !now.Before(record.ExpiresAt) {
+		return nil, ErrInvalidVerificationCode
+	}
+	attempts, err := s.codes.IncrementAttempts(ctx, record.ID)
+	if err != nil {
+		return nil, err
+	}
+	if attempts > s.cfg.MaxAttempts {
+		return nil, ErrInvalidVerificationCode
+	}
+
+	expected := hashVerificationCode(record.ID, strings.TrimSpace(code))
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(record.CodeHash)) != 1 {
+		logrus.WithFields(logrus.Fields{
+			"user_id":  record.UserID,
+			"purpose":  purpose,
+			"attempts": attempts,
+		}).Warn("验证码校验失败")
+		return nil, ErrInvalidVerificationCode
+	}
+
+	if err := s.codes.Consume(ctx, record.ID, now); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidVerificationCode
+		}
+		return nil, err
+	}
+	return record, nil
+}
+
+// throttle 检查并累计目标地址的发送次数
+// 两次发送之间至少间隔 ResendInterval，统计窗口内超过 MaxSends 次后锁定到窗口结束
+func (s *VerificationService) throttle(ctx context.Context, channel, destination string) (time.Duration, error) {
+	key := "verify:" + channel + ":" + destination
+	remaining, err := s.store.LockRemaining(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	if remaining > 0 {
+		return remaining, ErrVerificationThrottled
+	}
+
+	if s.cfg.MaxSends > 0 {
+		count, err := s.store.IncrementFailures(ctx, key, s.cfg.SendWindow)
+		if err != nil {
+			return 0, err
+		}
+		if count > s.cfg.MaxSends {
+			if err := s.store.Lock(ctx, key, s.cfg.SendWindow); err != nil {
+				return 0, err
+			}
+			return s.cfg.SendWindow, ErrVerificationThrottled
+		}
+	}
+	if s.cfg.ResendInterval > 0 {
+		if err := s.store.Lock(ctx, key, s.cfg.ResendInterval); err != nil {
+			return 0, err
+		}
+	}
+	return 0, nil
+}
+
+// codeMessage 根据用途生成验证码消息
+func (s *VerificationService) codeMessage(req VerificationRequest, code string) *Message {
+	var subject, action string
+	switch req.Purpose {
+	case models.PurposeLogin:
+		subject, action = "登录验证码", "登录"
+	case models.PurposePasswordReset:
+		subject, action = "重置密码验证码", "重置密码"
+	default:
+		subject, action = "验证您的"+channelName(req.Channel), "验证"+channelName(req.Channel)
+	}
+	minutes := int(s.cfg.CodeTTL.Minutes())
+
+	if req.Channel == models.ChannelSMS {
+		return &Message{
+			Channel: req.Channel,
+			To:      req.Destination,
+			Body:    fmt.Sprintf("【%s】验证码 %s，用于%s，%d 分钟内有效，请勿泄露给他人。", s.app, code, action, minutes),
+		}
+	}
+
+	body := fmt.Sprintf("【%s】您正在%s，验证码为：\n\n%s\n\n%d 分钟内有效。\n", s.app, action, code, minutes)
+	if link := s.link(req, url.Values{"code": {code}}); link != "" {
+		body += fmt.Sprintf("\n也可以直接打开以下链接完成操作：\n\n%s\n", link)
+	}
+	return &Message{
+		Channel: req.Channel,
+		To:      req.Destination,
+		Subject: subject,
+		Body:    body + "\n如非本人操作，请忽略本邮件。",
+	}
+}
+
+// link 生成前端验证页面链接，未配置页面地址时返回空字符串
+func (s *VerificationService) link(req VerificationRequest, params url.Values) string {
+	if s.cfg.LinkBaseURL == "" {
+		return ""
+	}
+	params.Set("purpose", req.Purpose)
+	params.Set("tenant", req.TenantID)
+	params.Set(req.Channel, req.Destination)
+
+	separator := "?"
+	if strings.Contains(s.cfg.LinkBaseURL, "?") {
+		separator = "&"
+	}
+	return s.cfg.LinkBaseURL + separator + params.Encode()
+}
+
+// record 记录验证码已发送的审计事件
+func (s *VerificationService) record(ctx context.Context, req VerificationRequest, metadata map[string]string) {
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	metadata["channel"] = req.Channel
+	metadata["purpose"] = req.Purpose
+	s.audit.Record(ctx, &models.AuditEvent{
+		Type:       models.AuditVerificationSent,
+		TenantID:   req.TenantID,
+		ActorID:    req.UserID,
+		Subject:    req.Destination,
+		Metadata:   metadata,
+		OccurredAt: time.Now(),
+	})
+}
+
+// NormalizeDestination 规范化邮箱或手机号，手机号去除空格和连字符后须为 E.164 格式
+func NormalizeDestination(channel, destination string) (string, error) {
+	if channel == models.ChannelEmail {
+		return normalizeEmail(destination), nil
+	}
+
+	phone := strings.NewReplacer(" ", "", "-", "", "(", "", ")", "").Replace(strings.TrimSpace(destination))
+	if !phonePattern.MatchString(phone) {
+		return "", ErrInvalidPhone
+	}
+	return phone, nil
+}
+
+// hashVerificationCode 验证码摘要，以记录 ID 加盐避免相同验证码产生相同摘要
+func hashVerificationCode(id, code string) string {
+	return utils.HashToken(id + ":" + code)
+}
+
+// channelName 渠道的中文名称
+func channelName(channel string) string {
+	if channel == models.ChannelSMS {
+		return "手机号"
+	}
+	return "邮箱"
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"gin_saas_auth/internal/config"
+	"gin_saas_auth/internal/models"
+	"gin_saas_auth/internal/repository"
+)
+
+// smsCodePattern 从短信正文中提取验证码
+var smsCodePattern = regexp.MustCompile(`验证码 (\d+)`)
+
+// captureSender 记录发出的消息
+type captureSender struct {
+	mu       sync.Mutex
+	messages []*Message
+}
+
+func (s *captureSender) Send(_ context.Context, msg *Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, msg)
+	return nil
+}
+
+// lastCode 最近一条短信中的验证码
+func (s *captureSender) lastCode(t *testing.T) string {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.messages) == 0 {
+		t.Fatal("没有发出任何消息")
+	}
+	match := smsCodePattern.FindStringSubmatch(s.messages[len(s.messages)-1].Body)
+	if match == nil {
+		t.Fatalf("短信中没有验证码: %s", s.messages[len(s.messages)-1].Body)
+	}
+	return match[1]
+}
+
+func (s *captureSender) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.messages)
+}
+
+func newTestVerificationService(cfg config.VerificationConfig, sender *captureSender) *VerificationService {
+	channels := ChannelSender{models.ChannelSMS: sender, models.ChannelEmail: sender}
+	return NewVerificationService(&config.Config{Verification: cfg}, repository.NewMemoryVerificationRepository(), repository.NewMemoryAttemptStore(), channels, &recordingAudit{})
+}
+
+// smsRequest 向指定手机号发送验证码的请求
+func smsRequest(tenantID, userID, phone string) VerificationRequest {
+	return VerificationRequest{TenantID: tenantID, UserID: userID, Channel: models.ChannelSMS, Destination: phone, Purpose: models.PurposeVerify}
+}
+
+func TestVerificationResendInterval(t *testing.T) {
+	sender := &captureSender{}
+	service := newTestVerificationService(config.VerificationConfig{CodeLength: 6, CodeTTL: 10 * time.Minute, MaxAttempts: 5, ResendInterval: time.Minute}, sender)
+	ctx := context.Background()
+
+	if _, err := service.SendCode(ctx, smsRequest("acme", "user-1", "+8613800000000")); err != nil {
+		t.Fatalf("发送验证码失败: %v", err)
+	}
+	retryAfter, err := service.SendCode(ctx, smsRequest("acme", "user-1", "+8613800000000"))
+	if !errors.Is(err, ErrVerificationThrottled) {
+		t.Fatalf("立即重发 err = %v, want ErrVerificationThrottled", err)
+	}
+	if retryAfter <= 59*time.Second || retryAfter > time.Minute {
+		t.Fatalf("retryAfter = %v, want 约 1m", retryAfter)
+	}
+	// 发送频率按目标地址统计，与租户无关
+	if _, err := service.SendCode(ctx, smsRequest("globex", "user-9", "+8613800000000")); !errors.Is(err, ErrVerificationThrottled) {
+		t.Fatalf("其他租户向同一手机号发送 err = %v, want ErrVerificationThrottled", err)
+	}
+	if _, err := service.SendCode(ctx, smsRequest("acme", "user-1", "+8613900000000")); err != nil {
+		t.Fatalf("其他手机号发送失败: %v", err)
+	}
+	if sender.count() != 2 {
+		t.Fatalf("发出消息 %d 条, want 2", sender.count())
+	}
+}
+
+func TestVerificationMaxSendsPerWindow(t *testing.T) {
+	sender := &captureSender{}
+	service := newTestVerificationService(config.VerificationConfig{CodeLength: 6, CodeTTL: 10 * time.Minute, MaxAttempts: 5, MaxSends: 2, SendWindow: time.Hour}, sender)
+	ctx := context.Background()
+
+	for i := range 2 {
+		if _, err := service.SendCode(ctx, smsRequest("acme", "user-1", "+8613800000000")); err != nil {
+			t.Fatalf("第 %d 次发送失败: %v", i+1, err)
+		}
+	}
+	for i := range 2 {
+		retryAfter, err := service.SendCode(ctx, smsRequest("acme", "user-1", "+8613800000000"))
+		if !errors.Is(err, ErrVerificationThrottled) || retryAfter <= 59*time.Minute {
+			t.Fatalf("超出上限后第 %d 次 retryAfter = %v, err = %v, want 锁定到窗口结束", i+1, retryAfter, err)
+		}
+	}
+	if sender.count() != 2 {
+		t.Fatalf("发出消息 %d 条, want 2", sender.count())
+	}
+}
+
+func TestVerificationUnknownUserCountsTowardThrottle(t *testing.T) {
+	sender := &captureSender{}
+	service := newTestVerificationService(config.VerificationConfig{CodeLength: 6, CodeTTL: 10 * time.Minute, MaxAttempts: 5, ResendInterval: time.Minute}, sender)
+	ctx := context.Background()
+
+	if _, err := service.SendCode(ctx, smsRequest("acme", "", "+8613800000000")); err != nil {
+		t.Fatalf("账户不存在时应与正常发送一致: %v", err)
+	}
+	if sender.count() != 0 {
+		t.Fatal("账户不存在时不应发送消息")
+	}
+	if _, err := service.SendCode(ctx, smsRequest("acme", "", "+8613800000000")); !errors.Is(err, ErrVerificationThrottled) {
+		t.Fatalf("账户不存在时同样计入发送频率, err = %v", err)
+	}
+}
+
+func TestVerificationResetLinkThrottledBeforeMint(t *testing.T) {
+	sender := &captureSender{}
+	service := newTestVerificationService(config.VerificationConfig{ResendInterval: time.Minute}, sender)
+	ctx := context.Background()
+	req := VerificationRequest{TenantID: "acme", UserID: "user-1", Channel: models.ChannelEmail, Destination: "user@example.com", Purpose: models.PurposePasswordReset}
+
+	minted := 0
+	mint := func(context.Context) (string, error) {
+		minted++
+		return "reset-token", nil
+	}
+	if _, err := service.SendResetLink(ctx, req, mint); err != nil {
+		t.Fatalf("发送重置链接失败: %v", err)
+	}
+	if _, err := service.SendResetLink(ctx, req, mint); !errors.Is(err, ErrVerificationThrottled) {
+		t.Fatalf("立即重发 err = %v, want ErrVerificationThrottled", err)
+	}
+	if minted != 1 {
+		t.Fatalf("生成重置令牌 %d 次, want 1", minted)
+	}
+}
+
+func TestVerificationMaxAttempts(t *testing.T) {
+	sender := &captureSender{}
+	service := newTestVerificationService(config.VerificationConfig{CodeLength: 6, CodeTTL: 10 * time.Minute, MaxAttempts: 3}, sender)
+	ctx := context.Background()
+
+	if _, err := service.SendCode(ctx, smsRequest("acme", "user-1", "+8613800000000")); err != nil {
+		t.Fatalf("发送验证码失败: %v", err)
+	}
+	code := sender.lastCode(t)
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+
+	for i := range 3 {
+		if _, err := service.Verify(ctx, "acme", models.ChannelSMS, "+8613800000000", models.PurposeVerify, wrong); !errors.Is(err, ErrInvalidVerificationCode) {
+			t.Fatalf("第 %d 次错误验证码 err = %v", i+1, err)
+		}
+	}
+	// 校验次数用尽后正确的验证码也失效
+	if _, err := service.Verify(ctx, "acme", models.ChannelSMS, "+8613800000000", models.PurposeVerify, code); !errors.Is(err, ErrInvalidVerificationCode) {
+		t.Fatalf("次数用尽后 err = %v, want ErrInvalidVerificationCode", err)
+	}
+}
+
+func TestVerificationCodeSingleUse(t *testing.T) {
+	sender := &captureSender{}
+	service := newTestVerificationService(config.VerificationConfig{CodeLength: 6, CodeTTL: 10 * time.Minute, MaxAttempts: 3}, sender)
+	ctx := context.Background()
+
+	if _, err := service.SendCode(ctx, smsRequest("acme", "user-1", "+8613800000000")); err != nil {
+		t.Fatalf("发送验证码失败: %v", err)
+	}
+	code := sender.lastCode(t)
+
+	// 验证码按租户隔离
+	if _, err := service.Verify(ctx, "globex", models.ChannelSMS, "+8613800000000", models.PurposeVerify, code); !errors.Is(err, ErrInvalidVerificationCode) {
+		t.Fatalf("其他租户 err = %v, want ErrInvalidVerificationCode", err)
+	}
+	record, err := service.Verify(ctx, "acme", models.ChannelSMS, "+8613800000000", models.PurposeVerify, " "+code+" ")
+	if err != nil {
+		t.Fatalf("校验验证码失败: %v", err)
+	}
+	if record.UserID != "user-1" {
+		t.Fatalf("验证码所属用户 = %s, want user-1", record.UserID)
+	}
+	if _, err := service.Verify(ctx, "acme", models.ChannelSMS, "+8613800000000", models.PurposeVerify, code); !errors.Is(err, ErrInvalidVerificationCode) {
+		t.Fatalf("重复使用 err = %v, want ErrInvalidVerificationCode", err)
+	}
+}
@@ -0,0 +1,191 @@
+// utils/binding.go
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"gin_saas_auth/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError 单个字段的参数错误
+type FieldError struct {
+	Field   string `json:"field"`           // 字段路径，使用 JSON 字段名，例如 profile.email
+	Rule    string `json:"rule"`            // 未通过的规则：校验标签，或 unknown（不支持的字段）、type（类型不匹配）
+	Param   string `json:"param,omitempty"` // 规则参数，例如 min=8 中的 8
+	Message string `json:"message"`
+}
+
+func init() {
+	// 校验错误中使用 JSON 字段名，与客户端提交的字段保持一致
+	if validate, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
+
+// ValidationError 400错误，附带字段错误列表
+func ValidationError(c *gin.Context, errs []FieldError) {
+	problem := NewProblem(400, CodeValidationFailed, "validation.failed")
+	problem.Errors = errs
+	WriteProblem(c, problem)
+}
+
+// BindJSON 解析 JSON 请求体并按 binding 标签校验，失败时写入错误响应并返回 false
+// 要求 Content-Type 为 JSON（415），请求体不超过上限（413），只包含一个 JSON 值且不含结构体未定义的字段（400）
+func BindJSON(c *gin.Context, obj any) bool {
+	return bindJSON(c, obj, false)
+}
+
+// BindOptionalJSON 与 BindJSON 相同，但允许请求体为空，此时不做任何绑定和校验
+func BindOptionalJSON(c *gin.Context, obj any) bool {
+	return bindJSON(c, obj, true)
+}
+
+// bindJSON 解析并校验 JSON 请求体
+func bindJSON(c *gin.Context, obj any, optional bool) bool {
+	empty := c.Request.Body == nil || c.Request.Body == http.NoBody || c.Request.ContentLength == 0
+	if empty && optional {
+		return true
+	}
+	if !isJSONContentType(c.GetHeader("Content-Type")) {
+		ErrorResponse(c, http.StatusUnsupportedMediaType, "request.json_required")
+		return false
+	}
+	if empty {
+		BadRequest(c, "request.body_required")
+		return false
+	}
+
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(obj)
+	if optional && errors.Is(err, io.EOF) {
+		return true
+	}
+	if err != nil {
+		respondDecodeError(c, err)
+		return false
+	}
+	if decoder.Decode(&json.RawMessage{}) != io.EOF {
+		BadRequest(c, "request.multiple_json_values")
+		return false
+	}
+
+	if err := binding.Validator.ValidateStruct(obj); err != nil {
+		var validationErrs validator.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			ValidationError(c, fieldErrors(c, validationErrs))
+			return false
+		}
+		BadRequest(c, "request.invalid_params", i18n.Params{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// isJSONContentType 是否为 application/json 或 application/*+json
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || (strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json"))
+}
+
+// respondDecodeError 按 JSON 解析错误的类型写入错误响应
+func respondDecodeError(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		ErrorResponse(c, http.StatusRequestEntityTooLarge, "request.body_too_large", i18n.Params{"limit": maxBytesErr.Limit})
+	case errors.Is(err, io.EOF):
+		BadRequest(c, "request.body_required")
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		BadRequest(c, "request.invalid_json")
+	case errors.As(err, &typeErr):
+		ValidationError(c, []FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Param:   typeErr.Type.String(),
+			Message: T(c, "validation.type", i18n.Params{"type": T(c, jsonTypeName(typeErr.Type))}),
+		}})
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json 未导出该错误类型，只能从错误信息中取出字段名
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		ValidationError(c, []FieldError{{Field: field, Rule: "unknown", Message: T(c, "validation.unknown")}})
+	default:
+		BadRequest(c, "request.invalid_params", i18n.Params{"error": err.Error()})
+	}
+}
+
+// fieldErrors 将校验错误转换为字段错误列表，提示按请求语言输出
+func fieldErrors(c *gin.Context, errs validator.ValidationErrors) []FieldError {
+	result := make([]FieldError, 0, len(errs))
+	for _, e := range errs {
+		// 去掉最外层结构体名，例如 LoginRequest.email -> email
+		field := e.Namespace()
+		if _, rest, ok := strings.Cut(field, "."); ok {
+			field = rest
+		}
+		result = append(result, FieldError{
+			Field:   field,
+			Rule:    e.Tag(),
+			Param:   e.Param(),
+			Message: T(c, validationMessage(e), i18n.Params{"param": e.Param(), "tag": e.Tag()}),
+		})
+	}
+	return result
+}
+
+// validationMessage 校验规则对应的提示消息键，消息模板中以 {param} 引用规则参数
+func validationMessage(e validator.FieldError) string {
+	isLength := e.Kind() == reflect.String || e.Kind() == reflect.Slice || e.Kind() == reflect.Map
+
+	switch e.Tag() {
+	case "required", "email", "url", "uuid", "e164", "oneof", "len":
+		return "validation." + e.Tag()
+	case "min", "max":
+		if isLength {
+			return "validation." + e.Tag() + "_length"
+		}
+		return "validation." + e.Tag()
+	default:
+		return "validation.default"
+	}
+}
+
+// jsonTypeName Go 类型对应的 JSON 类型名称的消息键
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "json_type.string"
+	case reflect.Bool:
+		return "json_type.boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "json_type.number"
+	case reflect.Slice, reflect.Array:
+		return "json_type.array"
+	default:
+		return "json_type.object"
+	}
+}
@@ -0,0 +1,195 @@
+// utils/binding_test.go
+package utils
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bindingRequest 绑定测试使用的请求结构
+type bindingRequest struct {
+	Email   string `json:"email" binding:"required,email"`
+	Age     int    `json:"age" binding:"omitempty,min=18"`
+	Profile struct {
+		Name string `json:"name" binding:"omitempty,max=4"`
+	} `json:"profile"`
+}
+
+// bindingProblem 错误响应中绑定测试关心的字段
+type bindingProblem struct {
+	Code   string       `json:"code"`
+	Detail string       `json:"detail"`
+	Errors []FieldError `json:"errors"`
+}
+
+// serveBind 以中文请求调用绑定方法，返回绑定结果和响应
+func serveBind(t *testing.T, bind func(*gin.Context, any) bool, contentType string, body io.Reader) (bool, *bindingRequest, *httptest.ResponseRecorder) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", body)
+	if contentType != "" {
+		c.Request.Header.Set("Content-Type", contentType)
+	}
+	c.Set(ContextKeyLanguage, "zh")
+
+	var req bindingRequest
+	ok := bind(c, &req)
+	return ok, &req, w
+}
+
+// decodeProblem 解析错误响应
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) bindingProblem {
+	t.Helper()
+	var problem bindingProblem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("解析错误响应失败: %v, body = %s", err, w.Body.String())
+	}
+	return problem
+}
+
+func TestBindJSONAcceptsValidBody(t *testing.T) {
+	for _, contentType := range []string{"application/json", "application/json; charset=utf-8", "application/merge-patch+json"} {
+		t.Run(contentType, func(t *testing.T) {
+			ok, req, w := serveBind(t, BindJSON, contentType, strings.NewReader(`{"email": "a@example.com", "age": 20, "profile": {"name": "amy"}}`))
+			if !ok {
+				t.Fatalf("绑定失败: %s", w.Body.String())
+			}
+			if req.Email != "a@example.com" || req.Age != 20 || req.Profile.Name != "amy" {
+				t.Fatalf("绑定结果 = %+v", req)
+			}
+		})
+	}
+}
+
+func TestBindJSONRejectsInvalidBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		detail      string
+	}{
+		{name: "form content type", contentType: "application/x-www-form-urlencoded", body: `{"email": "a@example.com"}`, status: http.StatusUnsupportedMediaType, detail: "请求体必须为 JSON，Content-Type 应为 application/json"},
+		{name: "missing content type", body: `{"email": "a@example.com"}`, status: http.StatusUnsupportedMediaType, detail: "请求体必须为 JSON，Content-Type 应为 application/json"},
+		{name: "empty body", contentType: "application/json", status: http.StatusBadRequest, detail: "请求体不能为空"},
+		{name: "malformed json", contentType: "application/json", body: `{"email": `, status: http.StatusBadRequest, detail: "请求体不是有效的 JSON"},
+		{name: "syntax error", contentType: "application/json", body: `{email}`, status: http.StatusBadRequest, detail: "请求体不是有效的 JSON"},
+		{name: "multiple values", contentType: "application/json", body: `{"email": "a@example.com"} {}`, status: http.StatusBadRequest, detail: "请求体只能包含一个 JSON 值"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, _, w := serveBind(t, BindJSON, tt.contentType, strings.NewReader(tt.body))
+			if ok {
+				t.Fatal("绑定应失败")
+			}
+			if w.Code != tt.status {
+				t.Fatalf("状态码 = %d, want %d", w.Code, tt.status)
+			}
+			if problem := decodeProblem(t, w); problem.Detail != tt.detail {
+				t.Fatalf("detail = %q, want %q", problem.Detail, tt.detail)
+			}
+		})
+	}
+}
+
+func TestBindJSONReportsFieldErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []FieldError
+	}{
+		{
+			name: "unknown field",
+			body: `{"email": "a@example.com", "role": "admin"}`,
+			want: []FieldError{{Field: "role", Rule: "unknown", Message: "不支持的字段"}},
+		},
+		{
+			name: "wrong type",
+			body: `{"email": "a@example.com", "age": "20"}`,
+			want: []FieldError{{Field: "age", Rule: "type", Param: "int", Message: "类型错误，应为数字"}},
+		},
+		{
+			name: "nested validation",
+			body: `{"email": "a@example.com", "profile": {"name": "amelia"}}`,
+			want: []FieldError{{Field: "profile.name", Rule: "max", Param: "4"}},
+		},
+		{
+			name: "required and format",
+			body: `{"email": "not-an-email", "age": 16}`,
+			want: []FieldError{{Field: "email", Rule: "email"}, {Field: "age", Rule: "min", Param: "18"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, _, w := serveBind(t, BindJSON, "application/json", strings.NewReader(tt.body))
+			if ok {
+				t.Fatal("绑定应失败")
+			}
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("状态码 = %d, want 400", w.Code)
+			}
+			problem := decodeProblem(t, w)
+			if problem.Code != CodeValidationFailed {
+				t.Fatalf("错误码 = %s, want %s", problem.Code, CodeValidationFailed)
+			}
+			if len(problem.Errors) != len(tt.want) {
+				t.Fatalf("errors = %+v, want %+v", problem.Errors, tt.want)
+			}
+			for i, got := range problem.Errors {
+				want := tt.want[i]
+				if got.Field != want.Field || got.Rule != want.Rule || got.Param != want.Param {
+					t.Fatalf("errors[%d] = %+v, want %+v", i, got, want)
+				}
+				// 未指定提示时只要求提示不为空，具体文案由语言包维护
+				if got.Message == "" || (want.Message != "" && got.Message != want.Message) {
+					t.Fatalf("errors[%d].message = %q, want %q", i, got.Message, want.Message)
+				}
+			}
+		})
+	}
+}
+
+func TestBindJSONBodyTooLarge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Body = http.MaxBytesReader(w, io.NopCloser(strings.NewReader(`{"email": "a@example.com"}`)), 8)
+	c.Request.ContentLength = -1
+	c.Set(ContextKeyLanguage, "zh")
+
+	var req bindingRequest
+	if BindJSON(c, &req) {
+		t.Fatal("超过上限时绑定应失败")
+	}
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("状态码 = %d, want 413", w.Code)
+	}
+	if problem := decodeProblem(t, w); problem.Detail != "请求体过大，最大 8 字节" {
+		t.Fatalf("detail = %q", problem.Detail)
+	}
+}
+
+func TestBindOptionalJSON(t *testing.T) {
+	ok, req, w := serveBind(t, BindOptionalJSON, "", http.NoBody)
+	if !ok {
+		t.Fatalf("空请求体应跳过绑定: %s", w.Body.String())
+	}
+	if *req != (bindingRequest{}) {
+		t.Fatalf("空请求体不应修改结构: %+v", req)
+	}
+
+	ok, _, w = serveBind(t, BindOptionalJSON, "application/json", strings.NewReader(`{"email": "a@example.com", "extra": true}`))
+	if ok || w.Code != http.StatusBadRequest {
+		t.Fatalf("非空请求体仍应严格校验: ok = %v, 状态码 = %d", ok, w.Code)
+	}
+}
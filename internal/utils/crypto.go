@@ -0,0 +1,62 @@
+// utils/crypto.go
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// ErrDecrypt 密文无法解密
+var ErrDecrypt = errors.New("密文解密失败")
+
+// SecretBox 基于 AES-256-GCM 的对称加密，用于保存需要还原明文的敏感字段（如 TOTP 密钥）
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+// NewSecretBox 使用任意长度的密钥材料创建加密器，内部以 SHA-256 派生 256 位密钥
+func NewSecretBox(keyMaterial string) (*SecretBox, error) {
+	key := sha256.Sum256([]byte(keyMaterial))
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("创建加密器失败: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("创建加密器失败: %w", err)
+	}
+	return &SecretBox{aead: aead}, nil
+}
+
+// Seal 加密明文，返回 base64 编码的 nonce+密文
+func (b *SecretBox) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("生成随机数失败: %w", err)
+	}
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Open 解密 Seal 生成的密文
+func (b *SecretBox) Open(encoded string) (string, error) {
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", ErrDecrypt
+	}
+	nonceSize := b.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", ErrDecrypt
+	}
+	plaintext, err := b.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return "", ErrDecrypt
+	}
+	return string(plaintext), nil
+}
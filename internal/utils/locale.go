@@ -0,0 +1,24 @@
+// utils/locale.go
+package utils
+
+import (
+	"gin_saas_auth/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContextKeyLanguage 上下文中保存协商语言的键
+const ContextKeyLanguage = "language"
+
+// Language 当前请求使用的语言，未经过语言协商时为默认语言
+func Language(c *gin.Context) string {
+	if lang := c.GetString(ContextKeyLanguage); lang != "" {
+		return lang
+	}
+	return i18n.DefaultLanguage
+}
+
+// T 按当前请求的语言翻译消息键
+func T(c *gin.Context, key string, params ...i18n.Params) string {
+	return i18n.T(Language(c), key, params...)
+}
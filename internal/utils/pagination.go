@@ -0,0 +1,615 @@
+// utils/pagination.go
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gin_saas_auth/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 列表接口的保留查询参数，其余参数按接口的过滤白名单解析
+const (
+	QueryPage     = "page"
+	QueryPageSize = "page_size"
+	QueryCursor   = "cursor"
+	QuerySort     = "sort"
+)
+
+// 接口未指定时的每页条数
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// maxPage 页码上限，更靠后的数据应使用游标分页
+const maxPage = 100000
+
+// FieldType 列表字段的取值类型，决定过滤值和游标的解析与比较方式
+type FieldType int
+
+const (
+	// FieldString 字符串，取值函数返回 string
+	FieldString FieldType = iota
+	// FieldNumber 数值，取值函数返回 int、int64 或 float64
+	FieldNumber
+	// FieldBool 布尔值，取值函数返回 bool
+	FieldBool
+	// FieldTime 时间，取值函数返回 time.Time 或 *time.Time（nil 视为零值），过滤值为 RFC3339 格式
+	FieldTime
+)
+
+// FilterOp 过滤运算符，查询参数写作 field[op]=value，省略运算符时为 eq
+type FilterOp string
+
+const (
+	FilterEq       FilterOp = "eq"
+	FilterNe       FilterOp = "ne"
+	FilterGt       FilterOp = "gt"
+	FilterGte      FilterOp = "gte"
+	FilterLt       FilterOp = "lt"
+	FilterLte      FilterOp = "lte"
+	FilterIn       FilterOp = "in"       // 逗号分隔的多个值，匹配其中之一
+	FilterContains FilterOp = "contains" // 字符串包含，不区分大小写
+)
+
+// ListField 列表接口允许排序或过滤的字段
+type ListField[E any] struct {
+	Type     FieldType
+	Value    func(item E) any
+	Sortable bool
+	Filters  []FilterOp // 允许的过滤运算符，为空时不可过滤
+}
+
+// ListSpec 列表接口的分页、排序和过滤规则，每个接口单独定义字段白名单
+type ListSpec[E any] struct {
+	Fields          map[string]ListField[E]
+	Key             func(item E) string // 唯一键，排序值相同时决定先后顺序，也用于定位游标
+	DefaultSort     string              // 未指定 sort 时的排序，格式同查询参数，例如 -created_at
+	DefaultPageSize int                 // 为 0 时使用 20
+	MaxPageSize     int                 // 为 0 时使用 100，超出时按上限返回
+}
+
+// SortField 排序字段
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// Filter 过滤条件，Values 已按字段类型解析
+type Filter struct {
+	Field  string
+	Op     FilterOp
+	Values []any
+}
+
+// ListQuery 解析后的列表查询，指定游标时按游标分页，否则按页码分页
+type ListQuery struct {
+	Page     int
+	PageSize int
+	Sort     []SortField
+	Filters  []Filter
+
+	cursor *pageCursor
+}
+
+// PageInfo 分页信息，页码分页时返回 page 和 total_pages，两种方式在还有下一页时都返回 next_cursor
+type PageInfo struct {
+	Page       int    `json:"page,omitempty"`
+	PageSize   int    `json:"page_size"`
+	Total      int    `json:"total"`
+	TotalPages int    `json:"total_pages,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// pageCursor 游标内容：上一页最后一条记录的排序值和唯一键，以及生成游标时的排序方式
+type pageCursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+	Key    string   `json:"k"`
+
+	parsed []any
+}
+
+// ParseListQuery 按接口规则解析分页、排序和过滤参数，参数不合法时写入 400 错误响应并返回 false
+// 未列入白名单的普通查询参数不视为过滤条件，field[op] 形式的参数必须是白名单中的字段和运算符
+func ParseListQuery[E any](c *gin.Context, spec ListSpec[E]) (*ListQuery, bool) {
+	query := &ListQuery{PageSize: spec.defaultPageSize()}
+	var errs []FieldError
+
+	values := c.Request.URL.Query()
+	if v := values.Get(QueryPage); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil {
+			errs = append(errs, numberError(c, QueryPage))
+		} else if page < 1 {
+			errs = append(errs, minError(c, QueryPage, 1))
+		} else if page > maxPage {
+			errs = append(errs, maxError(c, QueryPage, maxPage))
+		}
+		query.Page = page
+	}
+	if v := values.Get(QueryPageSize); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil {
+			errs = append(errs, numberError(c, QueryPageSize))
+		} else if size < 1 {
+			errs = append(errs, minError(c, QueryPageSize, 1))
+		}
+		query.PageSize = min(size, spec.maxPageSize())
+	}
+
+	sortExpr := values.Get(QuerySort)
+	if sortExpr == "" {
+		sortExpr = spec.DefaultSort
+	}
+	sortFields, sortErr := spec.parseSort(c, sortExpr)
+	if sortErr != nil {
+		errs = append(errs, *sortErr)
+	}
+	query.Sort = sortFields
+
+	if v := values.Get(QueryCursor); v != "" {
+		switch {
+		case query.Page != 0:
+			errs = append(errs, FieldError{Field: QueryCursor, Rule: "excluded_with", Param: QueryPage, Message: T(c, "list.cursor_with_page")})
+		case sortErr == nil:
+			cursor, err := spec.decodeCursor(v, query.Sort)
+			if err != nil {
+				errs = append(errs, FieldError{Field: QueryCursor, Rule: "cursor", Message: T(c, "list.invalid_cursor")})
+			}
+			query.cursor = cursor
+		}
+	} else if query.Page == 0 {
+		query.Page = 1
+	}
+
+	filters, filterErrs := spec.parseFilters(c, values)
+	query.Filters = filters
+	errs = append(errs, filterErrs...)
+
+	if len(errs) > 0 {
+		ValidationError(c, errs)
+		return nil, false
+	}
+	return query, true
+}
+
+// Apply 对内存中的完整列表依次过滤、排序和分页
+func (s ListSpec[E]) Apply(items []E, query *ListQuery) ([]E, PageInfo) {
+	filtered := make([]E, 0, len(items))
+	for _, item := range items {
+		if s.matches(item, query.Filters) {
+			filtered = append(filtered, item)
+		}
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return s.compare(filtered[i], filtered[j], query.Sort) < 0
+	})
+
+	info := PageInfo{PageSize: query.PageSize, Total: len(filtered)}
+	start := 0
+	if query.cursor != nil {
+		start = sort.Search(len(filtered), func(i int) bool {
+			return s.compareCursor(filtered[i], query.cursor, query.Sort) > 0
+		})
+	} else {
+		info.Page = query.Page
+		info.TotalPages = (len(filtered) + query.PageSize - 1) / query.PageSize
+		// 先比较页码再相乘，避免页码过大时偏移量溢出
+		if query.Page-1 < (len(filtered)+query.PageSize-1)/query.PageSize {
+			start = (query.Page - 1) * query.PageSize
+		} else {
+			start = len(filtered)
+		}
+	}
+	end := min(start+query.PageSize, len(filtered))
+
+	page := filtered[start:end]
+	info.HasMore = end < len(filtered)
+	if info.HasMore && len(page) > 0 {
+		info.NextCursor = s.encodeCursor(page[len(page)-1], query.Sort)
+	}
+	return page, info
+}
+
+// parseSort 解析逗号分隔的排序字段，字段前加 - 表示降序
+func (s ListSpec[E]) parseSort(c *gin.Context, expr string) ([]SortField, *FieldError) {
+	var result []SortField
+	for _, part := range strings.Split(expr, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		field := SortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if spec, exists := s.Fields[field.Field]; !exists || !spec.Sortable {
+			allowed := s.fieldNames(func(f ListField[E]) bool { return f.Sortable })
+			return nil, &FieldError{Field: QuerySort, Rule: "oneof", Param: allowed, Message: T(c, "validation.oneof", i18n.Params{"param": allowed})}
+		}
+		result = append(result, field)
+	}
+	return result, nil
+}
+
+// parseFilters 解析白名单字段的过滤参数，同一参数出现多次时各条件同时生效
+func (s ListSpec[E]) parseFilters(c *gin.Context, values map[string][]string) ([]Filter, []FieldError) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var filters []Filter
+	var errs []FieldError
+	for _, name := range names {
+		switch name {
+		case QueryPage, QueryPageSize, QueryCursor, QuerySort:
+			continue
+		}
+
+		fieldName, op, bracketed := name, FilterEq, false
+		if i := strings.IndexByte(name, '['); i > 0 && strings.HasSuffix(name, "]") {
+			fieldName, op, bracketed = name[:i], FilterOp(name[i+1:len(name)-1]), true
+		}
+		field, exists := s.Fields[fieldName]
+		if !exists || len(field.Filters) == 0 {
+			// 普通查询参数可能由接口自行处理，只有显式的过滤语法才报错
+			if bracketed {
+				errs = append(errs, FieldError{Field: name, Rule: "unknown", Message: T(c, "validation.unknown")})
+			}
+			continue
+		}
+		if !containsOp(field.Filters, op) {
+			allowed := joinOps(field.Filters)
+			errs = append(errs, FieldError{Field: name, Rule: "oneof", Param: allowed, Message: T(c, "validation.oneof", i18n.Params{"param": allowed})})
+			continue
+		}
+
+		for _, raw := range values[name] {
+			rawValues := []string{raw}
+			if op == FilterIn {
+				rawValues = strings.Split(raw, ",")
+			}
+			filter := Filter{Field: fieldName, Op: op}
+			for _, rawValue := range rawValues {
+				value, err := parseFieldValue(field.Type, strings.TrimSpace(rawValue))
+				if err != nil {
+					errs = append(errs, typeError(c, name, field.Type))
+					break
+				}
+				filter.Values = append(filter.Values, value)
+			}
+			filters = append(filters, filter)
+		}
+	}
+	return filters, errs
+}
+
+// matches 判断记录是否满足全部过滤条件
+func (s ListSpec[E]) matches(item E, filters []Filter) bool {
+	for _, filter := range filters {
+		field := s.Fields[filter.Field]
+		value := normalizeFieldValue(field.Type, field.Value(item))
+		if !matchFilter(field.Type, value, filter) {
+			return false
+		}
+	}
+	return true
+}
+
+// compare 按排序字段比较两条记录，排序值都相同时按唯一键比较
+func (s ListSpec[E]) compare(a, b E, sortFields []SortField) int {
+	for _, sf := range sortFields {
+		field := s.Fields[sf.Field]
+		result := compareFieldValues(field.Type, normalizeFieldValue(field.Type, field.Value(a)), normalizeFieldValue(field.Type, field.Value(b)))
+		if result != 0 {
+			if sf.Desc {
+				return -result
+			}
+			return result
+		}
+	}
+	return strings.Compare(s.Key(a), s.Key(b))
+}
+
+// compareCursor 比较记录与游标位置，大于 0 表示记录在游标之后
+func (s ListSpec[E]) compareCursor(item E, cursor *pageCursor, sortFields []SortField) int {
+	for i, sf := range sortFields {
+		field := s.Fields[sf.Field]
+		result := compareFieldValues(field.Type, normalizeFieldValue(field.Type, field.Value(item)), cursor.parsed[i])
+		if result != 0 {
+			if sf.Desc {
+				return -result
+			}
+			return result
+		}
+	}
+	return strings.Compare(s.Key(item), cursor.Key)
+}
+
+// encodeCursor 以记录的排序值和唯一键生成下一页游标
+func (s ListSpec[E]) encodeCursor(item E, sortFields []SortField) string {
+	cursor := pageCursor{Sort: sortSignature(sortFields), Key: s.Key(item)}
+	for _, sf := range sortFields {
+		field := s.Fields[sf.Field]
+		cursor.Values = append(cursor.Values, formatFieldValue(field.Type, normalizeFieldValue(field.Type, field.Value(item))))
+	}
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor 解析游标，排序方式与生成游标时不同则视为无效
+func (s ListSpec[E]) decodeCursor(encoded string, sortFields []SortField) (*pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	var cursor pageCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	if cursor.Sort != sortSignature(sortFields) || len(cursor.Values) != len(sortFields) {
+		return nil, errors.New("游标与排序方式不匹配")
+	}
+	for i, sf := range sortFields {
+		value, err := parseFieldValue(s.Fields[sf.Field].Type, cursor.Values[i])
+		if err != nil {
+			return nil, err
+		}
+		cursor.parsed = append(cursor.parsed, value)
+	}
+	return &cursor, nil
+}
+
+// fieldNames 满足条件的字段名，以空格分隔
+func (s ListSpec[E]) fieldNames(include func(ListField[E]) bool) string {
+	names := make([]string, 0, len(s.Fields))
+	for name, field := range s.Fields {
+		if include(field) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, " ")
+}
+
+// defaultPageSize 每页默认条数
+func (s ListSpec[E]) defaultPageSize() int {
+	if s.DefaultPageSize > 0 {
+		return min(s.DefaultPageSize, s.maxPageSize())
+	}
+	return min(defaultPageSize, s.maxPageSize())
+}
+
+// maxPageSize 每页最大条数
+func (s ListSpec[E]) maxPageSize() int {
+	if s.MaxPageSize > 0 {
+		return s.MaxPageSize
+	}
+	return maxPageSize
+}
+
+// SetLinkHeader 按 RFC 8288 写入分页导航的 Link 响应头，链接保留原请求的其余查询参数
+func SetLinkHeader(c *gin.Context, page PageInfo) {
+	var links []string
+	addLink := func(rel, name, value string) {
+		u := *c.Request.URL
+		query := u.Query()
+		query.Del(QueryPage)
+		query.Del(QueryCursor)
+		query.Set(name, value)
+		u.RawQuery = query.Encode()
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), rel))
+	}
+
+	if page.Page == 0 {
+		// 游标分页只能向后翻页
+		if page.NextCursor != "" {
+			addLink("next", QueryCursor, page.NextCursor)
+		}
+	} else {
+		lastPage := max(page.TotalPages, 1)
+		addLink("first", QueryPage, "1")
+		if page.Page > 1 {
+			addLink("prev", QueryPage, strconv.Itoa(min(page.Page-1, lastPage)))
+		}
+		if page.HasMore {
+			addLink("next", QueryPage, strconv.Itoa(page.Page+1))
+		}
+		addLink("last", QueryPage, strconv.Itoa(lastPage))
+	}
+
+	if len(links) > 0 {
+		c.Header("Link", strings.Join(links, ", "))
+	}
+}
+
+// sortSignature 排序方式的规范写法，写入游标用于校验
+func sortSignature(sortFields []SortField) string {
+	parts := make([]string, 0, len(sortFields))
+	for _, sf := range sortFields {
+		if sf.Desc {
+			parts = append(parts, "-"+sf.Field)
+		} else {
+			parts = append(parts, sf.Field)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// parseFieldValue 按字段类型解析查询参数或游标中的值
+func parseFieldValue(fieldType FieldType, raw string) (any, error) {
+	switch fieldType {
+	case FieldNumber:
+		return strconv.ParseFloat(raw, 64)
+	case FieldBool:
+		return strconv.ParseBool(raw)
+	case FieldTime:
+		return time.Parse(time.RFC3339Nano, raw)
+	default:
+		return raw, nil
+	}
+}
+
+// formatFieldValue 将字段值格式化为可由 parseFieldValue 还原的字符串
+func formatFieldValue(fieldType FieldType, value any) string {
+	switch fieldType {
+	case FieldNumber:
+		return strconv.FormatFloat(value.(float64), 'g', -1, 64)
+	case FieldBool:
+		return strconv.FormatBool(value.(bool))
+	case FieldTime:
+		return value.(time.Time).UTC().Format(time.RFC3339Nano)
+	default:
+		return value.(string)
+	}
+}
+
+// normalizeFieldValue 将取值函数的返回值统一为 string、float64、bool 或 time.Time
+func normalizeFieldValue(fieldType FieldType, value any) any {
+	switch fieldType {
+	case FieldNumber:
+		switch v := value.(type) {
+		case int:
+			return float64(v)
+		case int64:
+			return float64(v)
+		case float64:
+			return v
+		}
+		return float64(0)
+	case FieldBool:
+		v, _ := value.(bool)
+		return v
+	case FieldTime:
+		switch v := value.(type) {
+		case time.Time:
+			return v
+		case *time.Time:
+			if v != nil {
+				return *v
+			}
+		}
+		return time.Time{}
+	default:
+		v, _ := value.(string)
+		return v
+	}
+}
+
+// compareFieldValues 比较两个已统一类型的字段值
+func compareFieldValues(fieldType FieldType, a, b any) int {
+	switch fieldType {
+	case FieldNumber:
+		x, y := a.(float64), b.(float64)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	case FieldBool:
+		x, y := a.(bool), b.(bool)
+		switch {
+		case x == y:
+			return 0
+		case !x:
+			return -1
+		}
+		return 1
+	case FieldTime:
+		return a.(time.Time).Compare(b.(time.Time))
+	default:
+		return strings.Compare(a.(string), b.(string))
+	}
+}
+
+// matchFilter 判断字段值是否满足过滤条件
+func matchFilter(fieldType FieldType, value any, filter Filter) bool {
+	switch filter.Op {
+	case FilterIn:
+		for _, v := range filter.Values {
+			if compareFieldValues(fieldType, value, v) == 0 {
+				return true
+			}
+		}
+		return false
+	case FilterContains:
+		s, _ := value.(string)
+		sub, _ := filter.Values[0].(string)
+		return strings.Contains(strings.ToLower(s), strings.ToLower(sub))
+	}
+
+	result := compareFieldValues(fieldType, value, filter.Values[0])
+	switch filter.Op {
+	case FilterNe:
+		return result != 0
+	case FilterGt:
+		return result > 0
+	case FilterGte:
+		return result >= 0
+	case FilterLt:
+		return result < 0
+	case FilterLte:
+		return result <= 0
+	default:
+		return result == 0
+	}
+}
+
+// containsOp 运算符是否在允许的列表中
+func containsOp(ops []FilterOp, op FilterOp) bool {
+	for _, allowed := range ops {
+		if allowed == op {
+			return true
+		}
+	}
+	return false
+}
+
+// joinOps 以空格连接运算符
+func joinOps(ops []FilterOp) string {
+	names := make([]string, 0, len(ops))
+	for _, op := range ops {
+		names = append(names, string(op))
+	}
+	return strings.Join(names, " ")
+}
+
+// numberError 查询参数不是整数
+func numberError(c *gin.Context, name string) FieldError {
+	return FieldError{Field: name, Rule: "type", Param: "int", Message: T(c, "validation.type", i18n.Params{"type": T(c, "json_type.number")})}
+}
+
+// minError 查询参数小于最小值
+func minError(c *gin.Context, name string, minValue int) FieldError {
+	param := strconv.Itoa(minValue)
+	return FieldError{Field: name, Rule: "min", Param: param, Message: T(c, "validation.min", i18n.Params{"param": param})}
+}
+
+// maxError 查询参数大于最大值
+func maxError(c *gin.Context, name string, maxValue int) FieldError {
+	param := strconv.Itoa(maxValue)
+	return FieldError{Field: name, Rule: "max", Param: param, Message: T(c, "validation.max", i18n.Params{"param": param})}
+}
+
+// typeError 过滤值与字段类型不符
+func typeError(c *gin.Context, name string, fieldType FieldType) FieldError {
+	typeName := map[FieldType]string{
+		FieldString: "string",
+		FieldNumber: "number",
+		FieldBool:   "boolean",
+		FieldTime:   "time",
+	}[fieldType]
+	return FieldError{Field: name, Rule: "type", Param: typeName, Message: T(c, "validation.type", i18n.Params{"type": T(c, "json_type."+typeName)})}
+}
@@ -0,0 +1,393 @@
+// utils/pagination_test.go
+package utils
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listItem 分页测试使用的记录
+type listItem struct {
+	ID        string
+	Name      string
+	Score     int
+	Active    bool
+	CreatedAt time.Time
+}
+
+var listBase = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var testListSpec = ListSpec[listItem]{
+	Fields: map[string]ListField[listItem]{
+		"name": {
+			Type:     FieldString,
+			Value:    func(item listItem) any { return item.Name },
+			Sortable: true,
+			Filters:  []FilterOp{FilterEq, FilterIn, FilterContains},
+		},
+		"score": {
+			Type:     FieldNumber,
+			Value:    func(item listItem) any { return item.Score },
+			Sortable: true,
+			Filters:  []FilterOp{FilterEq, FilterNe, FilterGt, FilterGte, FilterLt, FilterLte},
+		},
+		"active": {
+			Type:    FieldBool,
+			Value:   func(item listItem) any { return item.Active },
+			Filters: []FilterOp{FilterEq},
+		},
+		"created_at": {
+			Type:     FieldTime,
+			Value:    func(item listItem) any { return item.CreatedAt },
+			Sortable: true,
+			Filters:  []FilterOp{FilterGte, FilterLt},
+		},
+	},
+	Key:             func(item listItem) string { return item.ID },
+	DefaultSort:     "-created_at",
+	DefaultPageSize: 2,
+	MaxPageSize:     3,
+}
+
+// testListItems 7 条记录，score 有重复以验证按唯一键决定先后
+func testListItems() []listItem {
+	names := []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf"}
+	items := make([]listItem, 0, len(names))
+	for i, name := range names {
+		items = append(items, listItem{
+			ID:        "id-" + strconv.Itoa(i),
+			Name:      name,
+			Score:     i % 3,
+			Active:    i%2 == 0,
+			CreatedAt: listBase.Add(time.Duration(i) * time.Hour),
+		})
+	}
+	return items
+}
+
+// listContext 创建带查询参数的请求上下文
+func listContext(rawQuery string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/items?"+rawQuery, nil)
+	return c, w
+}
+
+// parseList 解析查询参数，失败时返回错误响应中的字段和规则
+func parseList(t *testing.T, rawQuery string) (*ListQuery, []string) {
+	t.Helper()
+	c, w := listContext(rawQuery)
+	query, ok := ParseListQuery(c, testListSpec)
+	if ok {
+		return query, nil
+	}
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("状态码 = %d, want 400", w.Code)
+	}
+	var problem struct {
+		Errors []FieldError `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("解析错误响应失败: %v", err)
+	}
+	var errs []string
+	for _, e := range problem.Errors {
+		errs = append(errs, e.Field+":"+e.Rule)
+	}
+	return nil, errs
+}
+
+// itemIDs 记录的唯一键
+func itemIDs(items []listItem) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+func TestParseListQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		page     int
+		pageSize int
+		sort     []SortField
+		filters  int
+		errs     []string
+	}{
+		{name: "defaults", query: "", page: 1, pageSize: 2, sort: []SortField{{Field: "created_at", Desc: true}}},
+		{name: "page and size", query: "page=3&page_size=3&sort=name,-score", page: 3, pageSize: 3, sort: []SortField{{Field: "name"}, {Field: "score", Desc: true}}},
+		{name: "size capped", query: "page_size=50", page: 1, pageSize: 3, sort: []SortField{{Field: "created_at", Desc: true}}},
+		{name: "max page", query: "page=" + strconv.Itoa(maxPage), page: maxPage, pageSize: 2, sort: []SortField{{Field: "created_at", Desc: true}}},
+		{name: "filters", query: "name[in]=alpha,bravo&score[gte]=1&active=true&other=x", page: 1, pageSize: 2, sort: []SortField{{Field: "created_at", Desc: true}}, filters: 3},
+		{name: "page not number", query: "page=abc", errs: []string{"page:type"}},
+		{name: "page zero", query: "page=0", errs: []string{"page:min"}},
+		{name: "page over limit", query: "page=" + strconv.Itoa(maxPage+1), errs: []string{"page:max"}},
+		{name: "page overflow", query: "page=922337203685477582&page_size=10", errs: []string{"page:max"}},
+		{name: "page out of int range", query: "page=99999999999999999999", errs: []string{"page:type"}},
+		{name: "size zero", query: "page_size=0", errs: []string{"page_size:min"}},
+		{name: "unknown sort", query: "sort=active", errs: []string{"sort:oneof"}},
+		{name: "cursor with page", query: "page=2&cursor=abc", errs: []string{"cursor:excluded_with"}},
+		{name: "invalid cursor", query: "cursor=not-a-cursor", errs: []string{"cursor:cursor"}},
+		{name: "unknown filter", query: "owner[eq]=x", errs: []string{"owner[eq]:unknown"}},
+		{name: "operator not allowed", query: "name[gt]=a", errs: []string{"name[gt]:oneof"}},
+		{name: "value type", query: "score[gt]=high&created_at[gte]=yesterday", errs: []string{"created_at[gte]:type", "score[gt]:type"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, errs := parseList(t, tt.query)
+			if !reflect.DeepEqual(errs, tt.errs) {
+				t.Fatalf("errors = %v, want %v", errs, tt.errs)
+			}
+			if tt.errs != nil {
+				return
+			}
+			if query.Page != tt.page || query.PageSize != tt.pageSize {
+				t.Fatalf("page = %d, page_size = %d, want %d, %d", query.Page, query.PageSize, tt.page, tt.pageSize)
+			}
+			if !reflect.DeepEqual(query.Sort, tt.sort) {
+				t.Fatalf("sort = %+v, want %+v", query.Sort, tt.sort)
+			}
+			if len(query.Filters) != tt.filters {
+				t.Fatalf("filters = %+v, want %d", query.Filters, tt.filters)
+			}
+		})
+	}
+}
+
+func TestListSpecApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		ids   []string
+		info  PageInfo
+	}{
+		{
+			name:  "default sort",
+			query: "",
+			ids:   []string{"id-6", "id-5"},
+			info:  PageInfo{Page: 1, PageSize: 2, Total: 7, TotalPages: 4, HasMore: true},
+		},
+		{
+			name:  "sort ties broken by key",
+			query: "sort=-score&page_size=3",
+			ids:   []string{"id-2", "id-5", "id-1"},
+			info:  PageInfo{Page: 1, PageSize: 3, Total: 7, TotalPages: 3, HasMore: true},
+		},
+		{
+			name:  "last page",
+			query: "sort=name&page=4",
+			ids:   []string{"id-6"},
+			info:  PageInfo{Page: 4, PageSize: 2, Total: 7, TotalPages: 4},
+		},
+		{
+			name:  "beyond last page",
+			query: "sort=name&page=" + strconv.Itoa(maxPage),
+			ids:   []string{},
+			info:  PageInfo{Page: maxPage, PageSize: 2, Total: 7, TotalPages: 4},
+		},
+		{
+			name:  "in filter",
+			query: "sort=name&name[in]=echo,alpha,zulu",
+			ids:   []string{"id-0", "id-4"},
+			info:  PageInfo{Page: 1, PageSize: 2, Total: 2, TotalPages: 1},
+		},
+		{
+			name:  "contains ignores case",
+			query: "sort=name&name[contains]=LT",
+			ids:   []string{"id-3"},
+			info:  PageInfo{Page: 1, PageSize: 2, Total: 1, TotalPages: 1},
+		},
+		{
+			name:  "number and bool filters",
+			query: "sort=name&score[ne]=0&active=true",
+			ids:   []string{"id-2", "id-4"},
+			info:  PageInfo{Page: 1, PageSize: 2, Total: 2, TotalPages: 1},
+		},
+		{
+			name:  "time range",
+			query: "sort=created_at&created_at[gte]=2026-01-01T02:00:00Z&created_at[lt]=2026-01-01T04:00:00Z",
+			ids:   []string{"id-2", "id-3"},
+			info:  PageInfo{Page: 1, PageSize: 2, Total: 2, TotalPages: 1},
+		},
+		{
+			name:  "repeated filters combine",
+			query: "sort=score&score[gt]=0&score[lt]=2",
+			ids:   []string{"id-1", "id-4"},
+			info:  PageInfo{Page: 1, PageSize: 2, Total: 2, TotalPages: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, errs := parseList(t, tt.query)
+			if errs != nil {
+				t.Fatalf("解析查询失败: %v", errs)
+			}
+			page, info := testListSpec.Apply(testListItems(), query)
+			if ids := itemIDs(page); !reflect.DeepEqual(ids, tt.ids) {
+				t.Fatalf("ids = %v, want %v", ids, tt.ids)
+			}
+			if info.HasMore != (info.NextCursor != "") {
+				t.Fatalf("has_more = %v, next_cursor = %q", info.HasMore, info.NextCursor)
+			}
+			info.NextCursor = ""
+			if info != tt.info {
+				t.Fatalf("page info = %+v, want %+v", info, tt.info)
+			}
+		})
+	}
+}
+
+func TestListSpecApplyLargePage(t *testing.T) {
+	query := &ListQuery{Page: math.MaxInt, PageSize: 10}
+	page, info := testListSpec.Apply(testListItems(), query)
+	if len(page) != 0 || info.HasMore || info.Total != 7 {
+		t.Fatalf("page = %v, info = %+v", itemIDs(page), info)
+	}
+}
+
+func TestListCursorRoundTrip(t *testing.T) {
+	items := testListItems()
+	for _, sortExpr := range []string{"name", "-score", "score,-created_at", "-created_at"} {
+		t.Run(sortExpr, func(t *testing.T) {
+			all, errs := parseList(t, "sort="+sortExpr+"&page_size=3")
+			if errs != nil {
+				t.Fatalf("解析查询失败: %v", errs)
+			}
+			all.PageSize = len(items)
+			want, _ := testListSpec.Apply(items, all)
+
+			var got []string
+			cursor := ""
+			for pages := 0; ; pages++ {
+				if pages > len(items) {
+					t.Fatal("游标分页没有结束")
+				}
+				rawQuery := "sort=" + sortExpr + "&page_size=3"
+				if cursor != "" {
+					rawQuery += "&cursor=" + cursor
+				}
+				query, errs := parseList(t, rawQuery)
+				if errs != nil {
+					t.Fatalf("解析游标失败: %v", errs)
+				}
+				page, info := testListSpec.Apply(items, query)
+				got = append(got, itemIDs(page)...)
+				if cursor != "" && info.Page != 0 {
+					t.Fatalf("游标分页不应返回页码: %+v", info)
+				}
+				if !info.HasMore {
+					break
+				}
+				cursor = info.NextCursor
+			}
+			if !reflect.DeepEqual(got, itemIDs(want)) {
+				t.Fatalf("游标分页结果 = %v, want %v", got, itemIDs(want))
+			}
+		})
+	}
+}
+
+func TestListCursorRejectsDifferentSort(t *testing.T) {
+	query, errs := parseList(t, "sort=name")
+	if errs != nil {
+		t.Fatalf("解析查询失败: %v", errs)
+	}
+	_, info := testListSpec.Apply(testListItems(), query)
+
+	if _, errs := parseList(t, "sort=name&cursor="+info.NextCursor); errs != nil {
+		t.Fatalf("相同排序的游标应有效: %v", errs)
+	}
+	if _, errs := parseList(t, "sort=-name&cursor="+info.NextCursor); !reflect.DeepEqual(errs, []string{"cursor:cursor"}) {
+		t.Fatalf("排序不同的游标 errors = %v", errs)
+	}
+}
+
+func TestSetLinkHeader(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		page  PageInfo
+		links []string
+	}{
+		{
+			name:  "first page",
+			query: "page=1&sort=name",
+			page:  PageInfo{Page: 1, PageSize: 2, Total: 5, TotalPages: 3, HasMore: true, NextCursor: "c"},
+			links: []string{
+				`</api/v1/items?page=1&sort=name>; rel="first"`,
+				`</api/v1/items?page=2&sort=name>; rel="next"`,
+				`</api/v1/items?page=3&sort=name>; rel="last"`,
+			},
+		},
+		{
+			name:  "middle page",
+			query: "page=2&name[contains]=a",
+			page:  PageInfo{Page: 2, PageSize: 2, Total: 5, TotalPages: 3, HasMore: true},
+			links: []string{
+				`</api/v1/items?name%5Bcontains%5D=a&page=1>; rel="first"`,
+				`</api/v1/items?name%5Bcontains%5D=a&page=1>; rel="prev"`,
+				`</api/v1/items?name%5Bcontains%5D=a&page=3>; rel="next"`,
+				`</api/v1/items?name%5Bcontains%5D=a&page=3>; rel="last"`,
+			},
+		},
+		{
+			name:  "beyond last page",
+			query: "page=9",
+			page:  PageInfo{Page: 9, PageSize: 2, Total: 5, TotalPages: 3},
+			links: []string{
+				`</api/v1/items?page=1>; rel="first"`,
+				`</api/v1/items?page=3>; rel="prev"`,
+				`</api/v1/items?page=3>; rel="last"`,
+			},
+		},
+		{
+			name:  "empty list",
+			query: "",
+			page:  PageInfo{Page: 1, PageSize: 2},
+			links: []string{
+				`</api/v1/items?page=1>; rel="first"`,
+				`</api/v1/items?page=1>; rel="last"`,
+			},
+		},
+		{
+			name:  "cursor",
+			query: "cursor=old&sort=name",
+			page:  PageInfo{PageSize: 2, Total: 5, HasMore: true, NextCursor: "next"},
+			links: []string{`</api/v1/items?cursor=next&sort=name>; rel="next"`},
+		},
+		{
+			name:  "cursor last page",
+			query: "cursor=old",
+			page:  PageInfo{PageSize: 2, Total: 5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := listContext(tt.query)
+			SetLinkHeader(c, tt.page)
+
+			header := w.Header().Get("Link")
+			if tt.links == nil {
+				if header != "" {
+					t.Fatalf("Link = %q, want empty", header)
+				}
+				return
+			}
+			if links := strings.Split(header, ", "); !reflect.DeepEqual(links, tt.links) {
+				t.Fatalf("Link = %q, want %q", links, tt.links)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// utils/password_test.go
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon2Params 测试用的低开销参数
+var testArgon2Params = Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func TestPasswordHasherVerify(t *testing.T) {
+	hasher := NewPasswordHasher(testArgon2Params)
+	current, err := hasher.Hash("correct horse battery")
+	if err != nil {
+		t.Fatalf("生成哈希失败: %v", err)
+	}
+	if !strings.HasPrefix(current, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Fatalf("哈希格式 = %s", current)
+	}
+	outdated, err := NewPasswordHasher(Argon2Params{Memory: 512, Iterations: 1, Parallelism: 1}).Hash("correct horse battery")
+	if err != nil {
+		t.Fatalf("生成旧参数哈希失败: %v", err)
+	}
+	legacy, err := bcrypt.GenerateFromPassword([]byte("correct horse battery"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("生成 bcrypt 哈希失败: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		password    string
+		encoded     string
+		match       bool
+		needsRehash bool
+	}{
+		{name: "current params", password: "correct horse battery", encoded: current, match: true},
+		{name: "wrong password", password: "wrong", encoded: current},
+		{name: "outdated params", password: "correct horse battery", encoded: outdated, match: true, needsRehash: true},
+		{name: "outdated params wrong password", password: "wrong", encoded: outdated},
+		{name: "bcrypt", password: "correct horse battery", encoded: string(legacy), match: true, needsRehash: true},
+		{name: "bcrypt wrong password", password: "wrong", encoded: string(legacy)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, needsRehash, err := hasher.Verify(tt.password, tt.encoded)
+			if err != nil {
+				t.Fatalf("校验失败: %v", err)
+			}
+			if match != tt.match || needsRehash != tt.needsRehash {
+				t.Fatalf("match = %v, needsRehash = %v, want %v, %v", match, needsRehash, tt.match, tt.needsRehash)
+			}
+		})
+	}
+}
+
+func TestPasswordHasherRejectsInvalidHash(t *testing.T) {
+	hasher := NewPasswordHasher(testArgon2Params)
+	for _, encoded := range []string{
+		"",
+		"plaintext",
+		"$argon2id$v=19$m=1024,t=1,p=1$c2FsdA",
+		"$argon2id$v=19$m=x,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=1$!!!$a2V5",
+	} {
+		if _, _, err := hasher.Verify("password", encoded); !errors.Is(err, ErrInvalidHash) {
+			t.Errorf("Verify(%q) err = %v, want ErrInvalidHash", encoded, err)
+		}
+	}
+}
+
+func TestNewPasswordHasherDefaults(t *testing.T) {
+	hasher := NewPasswordHasher(Argon2Params{Memory: 1024})
+	want := DefaultArgon2Params()
+	want.Memory = 1024
+	if hasher.params != want {
+		t.Fatalf("params = %+v, want %+v", hasher.params, want)
+	}
+}
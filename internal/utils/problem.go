@@ -0,0 +1,260 @@
+// utils/problem.go
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gin_saas_auth/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ProblemContentType 错误响应的媒体类型（RFC 7807）
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix 问题类型 URI 前缀，后接小写、连字符分隔的错误码
+const problemTypePrefix = "urn:gin-saas-auth:problem:"
+
+const (
+	// HeaderRequestID 请求 ID 请求头和响应头
+	HeaderRequestID = "X-Request-ID"
+	// ContextKeyRequestID 上下文中保存请求 ID 的键
+	ContextKeyRequestID = "request_id"
+)
+
+// 通用错误码，业务错误使用 AppError 中定义的错误码
+const (
+	CodeBadRequest           = "BAD_REQUEST"
+	CodeValidationFailed     = "VALIDATION_FAILED"
+	CodeUnauthorized         = "UNAUTHORIZED"
+	CodeForbidden            = "FORBIDDEN"
+	CodeNotFound             = "NOT_FOUND"
+	CodeConflict             = "CONFLICT"
+	CodePayloadTooLarge      = "PAYLOAD_TOO_LARGE"
+	CodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	CodeRateLimited          = "RATE_LIMITED"
+	CodeInternal             = "INTERNAL_ERROR"
+	CodeServiceUnavailable   = "SERVICE_UNAVAILABLE"
+	CodeTimeout              = "TIMEOUT"
+)
+
+// statusCodes HTTP 状态码对应的通用错误码
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            CodeBadRequest,
+	http.StatusUnauthorized:          CodeUnauthorized,
+	http.StatusForbidden:             CodeForbidden,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusConflict:              CodeConflict,
+	http.StatusRequestEntityTooLarge: CodePayloadTooLarge,
+	http.StatusUnsupportedMediaType:  CodeUnsupportedMediaType,
+	http.StatusTooManyRequests:       CodeRateLimited,
+	http.StatusInternalServerError:   CodeInternal,
+	http.StatusServiceUnavailable:    CodeServiceUnavailable,
+	http.StatusGatewayTimeout:        CodeTimeout,
+}
+
+// AppError 带有稳定错误码和 HTTP 状态的业务错误
+// 通常定义为包级哨兵错误，经 fmt.Errorf("%w") 包装或 WithDetails 附加说明后仍可通过 errors.Is 判断，并由 RespondError 转为错误响应
+// 错误响应中的说明按消息键 error.<错误码> 本地化，消息目录未收录时使用 Message
+type AppError struct {
+	Code    string // 错误码，客户端据此判断错误类型，发布后不应修改
+	Status  int
+	Message string         // 默认语言的错误信息
+	Details []i18n.Message // 补充说明，例如未满足的具体要求
+}
+
+// NewAppError 创建业务错误
+func NewAppError(code string, status int, message string) *AppError {
+	return &AppError{Code: code, Status: status, Message: message}
+}
+
+// WithDetails 返回附加了补充说明的副本，错误码和状态不变
+func (e *AppError) WithDetails(details ...i18n.Message) *AppError {
+	copied := *e
+	copied.Details = append(append([]i18n.Message(nil), e.Details...), details...)
+	return &copied
+}
+
+// Error 默认语言的错误信息
+func (e *AppError) Error() string {
+	return e.Localize(i18n.DefaultLanguage)
+}
+
+// Is 错误码相同即视为同一错误，使附加了说明的副本与哨兵错误匹配
+func (e *AppError) Is(target error) bool {
+	other, ok := target.(*AppError)
+	return ok && other.Code == e.Code
+}
+
+// Localize 按语言输出错误信息和补充说明，用于日志等单行文本
+func (e *AppError) Localize(lang string) string {
+	message := e.LocalizeMessage(lang)
+	if len(e.Details) == 0 {
+		return message
+	}
+
+	details := make([]string, 0, len(e.Details))
+	for _, detail := range e.Details {
+		details = append(details, detail.Localize(lang))
+	}
+	return i18n.T(lang, "error.with_details", i18n.Params{
+		"message": message,
+		"details": strings.Join(details, i18n.T(lang, "common.list_separator")),
+	})
+}
+
+// LocalizeMessage 按语言输出错误信息，不含补充说明
+func (e *AppError) LocalizeMessage(lang string) string {
+	if message, ok := i18n.Lookup(lang, "error."+e.Code); ok {
+		return message
+	}
+	return e.Message
+}
+
+// Problem 错误响应（RFC 7807 Problem Details），code、request_id、errors、details 为扩展字段
+type Problem struct {
+	Type       string         `json:"type"`
+	Title      string         `json:"title"`
+	Status     int            `json:"status"`
+	Detail     string         `json:"detail,omitempty"`
+	Instance   string         `json:"instance,omitempty"`
+	Code       string         `json:"code"`
+	RequestID  string         `json:"request_id,omitempty"`
+	Errors     []FieldError   `json:"errors,omitempty"`  // 参数错误的字段列表
+	Details    []string       `json:"details,omitempty"` // 业务错误的具体原因，例如未满足的密码要求
+	Extensions map[string]any `json:"-"`                 // 其他扩展字段，例如 retry_after
+
+	message i18n.Message // 写入响应时按请求语言生成 Detail
+	appErr  *AppError
+}
+
+// NewProblem 创建错误响应，message 为说明的消息键
+func NewProblem(status int, code, message string, params ...i18n.Params) *Problem {
+	return &Problem{Status: status, Code: code, message: i18n.NewMessage(message, params...)}
+}
+
+// With 添加扩展字段
+func (p *Problem) With(key string, value any) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]any)
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+// MarshalJSON 扩展字段按名称顺序追加在标准字段之后，与标准字段同名的扩展字段被忽略
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem
+	data, err := json.Marshal(problem(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+
+	var standard map[string]json.RawMessage
+	if err := json.Unmarshal(data, &standard); err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(p.Extensions))
+	for key := range p.Extensions {
+		if _, exists := standard[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	buf := bytes.NewBuffer(data[:len(data)-1])
+	for _, key := range keys {
+		name, _ := json.Marshal(key)
+		value, err := json.Marshal(p.Extensions[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteByte(',')
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// WriteProblem 写入错误响应，按请求语言生成标题和说明，补全类型、请求路径和请求 ID
+func WriteProblem(c *gin.Context, p *Problem) {
+	lang := Language(c)
+	if p.Code == "" {
+		p.Code = statusCode(p.Status)
+	}
+	if p.Type == "" {
+		p.Type = problemTypePrefix + strings.ToLower(strings.ReplaceAll(p.Code, "_", "-"))
+	}
+	if p.Title == "" {
+		title, ok := i18n.Lookup(lang, "status."+strconv.Itoa(p.Status))
+		if !ok {
+			title = http.StatusText(p.Status)
+		}
+		p.Title = title
+	}
+	if p.Detail == "" {
+		switch {
+		case p.appErr != nil:
+			// 补充说明只在 details 中逐条列出
+			p.Detail = p.appErr.LocalizeMessage(lang)
+		case p.message.Key != "":
+			p.Detail = p.message.Localize(lang)
+		}
+	}
+	if p.appErr != nil && len(p.Details) == 0 {
+		for _, detail := range p.appErr.Details {
+			p.Details = append(p.Details, detail.Localize(lang))
+		}
+	}
+	if p.Instance == "" {
+		p.Instance = c.Request.URL.Path
+	}
+	if p.RequestID == "" {
+		p.RequestID = c.GetString(ContextKeyRequestID)
+	}
+
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(p.Status, p)
+}
+
+// ErrorProblem 将错误转为错误响应：错误链中有 AppError 时使用其错误码、状态和说明，否则为 500，不暴露内部错误信息
+func ErrorProblem(err error) *Problem {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return &Problem{Status: appErr.Status, Code: appErr.Code, appErr: appErr}
+	}
+	return NewProblem(http.StatusInternalServerError, CodeInternal, "request.internal_error")
+}
+
+// RespondError 写入错误对应的错误响应，非业务错误记录日志
+func RespondError(c *gin.Context, err error) {
+	var appErr *AppError
+	if !errors.As(err, &appErr) {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"method":     c.Request.Method,
+			"path":       c.Request.URL.Path,
+			"request_id": c.GetString(ContextKeyRequestID),
+		}).Error("请求处理失败")
+	}
+	WriteProblem(c, ErrorProblem(err))
+}
+
+// statusCode HTTP 状态码对应的通用错误码
+func statusCode(status int) string {
+	if code, exists := statusCodes[status]; exists {
+		return code
+	}
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
@@ -0,0 +1,80 @@
+// utils/problem_test.go
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"gin_saas_auth/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRespondErrorListsDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	errInvalidPassword := NewAppError("INVALID_PASSWORD", http.StatusBadRequest, "密码不符合要求")
+	err := errInvalidPassword.WithDetails(
+		i18n.NewMessage("password.min_length", i18n.Params{"min": 12}),
+		i18n.NewMessage("password.require_digit"),
+	)
+
+	tests := []struct {
+		lang    string
+		detail  string
+		details []string
+	}{
+		{lang: "en", detail: "Password does not meet the requirements", details: []string{"must be at least 12 characters long", "must contain a digit"}},
+		{lang: "zh", detail: "密码不符合要求", details: []string{"长度不能少于 12 个字符", "必须包含数字"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", nil)
+			c.Set(ContextKeyLanguage, tt.lang)
+
+			RespondError(c, err)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("状态码 = %d, want 400", w.Code)
+			}
+			var body struct {
+				Code    string   `json:"code"`
+				Detail  string   `json:"detail"`
+				Details []string `json:"details"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("解析错误响应失败: %v", err)
+			}
+			if body.Code != "INVALID_PASSWORD" {
+				t.Fatalf("错误码 = %s, want INVALID_PASSWORD", body.Code)
+			}
+			if !reflect.DeepEqual(body.Details, tt.details) {
+				t.Fatalf("details = %q, want %q", body.Details, tt.details)
+			}
+			if body.Detail != tt.detail {
+				t.Fatalf("detail = %q, want %q", body.Detail, tt.detail)
+			}
+		})
+	}
+}
+
+func TestRespondErrorOmitsEmptyDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+	RespondError(c, NewAppError("USER_NOT_FOUND", http.StatusNotFound, "用户不存在"))
+
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("解析错误响应失败: %v", err)
+	}
+	if _, ok := body["details"]; ok {
+		t.Fatalf("没有具体原因时不应返回 details: %s", w.Body.String())
+	}
+}
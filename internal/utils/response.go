@@ -2,71 +2,90 @@
 package utils
 
 import (
+	"gin_saas_auth/internal/i18n"
+
 	"github.com/gin-gonic/gin"
 )
 
 // Response 统一响应结构
+// 以下响应方法的 message 为消息键，按请求协商的语言输出；未收录的文本原样输出
 type Response struct {
 	Code    int         `json:"code"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
 }
 
+// PageResponse 分页列表响应，在统一响应结构上附加分页信息
+type PageResponse struct {
+	Response
+	Page PageInfo `json:"page"`
+}
+
 // SuccessResponse 成功响应
 func SuccessResponse(c *gin.Context, code int, message string, data interface{}) {
 	c.JSON(code, Response{
 		Code:    code,
-		Message: message,
+		Message: T(c, message),
 		Data:    data,
 	})
 }
 
-// ErrorResponse 错误响应
-func ErrorResponse(c *gin.Context, code int, message string) {
-	c.JSON(code, Response{
-		Code:    code,
-		Message: message,
-	})
+// ErrorResponse 错误响应，使用 RFC 7807 格式和状态码对应的通用错误码
+func ErrorResponse(c *gin.Context, code int, message string, params ...i18n.Params) {
+	WriteProblem(c, NewProblem(code, "", message, params...))
 }
 
 // SuccessWithData 成功响应带数据
 func SuccessWithData(c *gin.Context, data interface{}) {
 	c.JSON(200, Response{
 		Code:    200,
-		Message: "操作成功",
+		Message: T(c, "common.success"),
 		Data:    data,
 	})
 }
 
+// SuccessWithPage 分页列表成功响应，同时写入翻页的 Link 响应头
+func SuccessWithPage(c *gin.Context, data interface{}, page PageInfo) {
+	SetLinkHeader(c, page)
+	c.JSON(200, PageResponse{
+		Response: Response{
+			Code:    200,
+			Message: T(c, "common.success"),
+			Data:    data,
+		},
+		Page: page,
+	})
+}
+
 // Success 成功响应
 func Success(c *gin.Context, message string) {
 	c.JSON(200, Response{
 		Code:    200,
-		Message: message,
+		Message: T(c, message),
 	})
 }
 
 // BadRequest 400错误
-func BadRequest(c *gin.Context, message string) {
-	ErrorResponse(c, 400, message)
+func BadRequest(c *gin.Context, message string, params ...i18n.Params) {
+	ErrorResponse(c, 400, message, params...)
 }
 
 // Unauthorized 401错误
-func Unauthorized(c *gin.Context, message string) {
-	ErrorResponse(c, 401, message)
+func Unauthorized(c *gin.Context, message string, params ...i18n.Params) {
+	ErrorResponse(c, 401, message, params...)
 }
 
 // Forbidden 403错误
-func Forbidden(c *gin.Context, message string) {
-	ErrorResponse(c, 403, message)
+func Forbidden(c *gin.Context, message string, params ...i18n.Params) {
+	ErrorResponse(c, 403, message, params...)
 }
 
 // NotFound 404错误
-func NotFound(c *gin.Context, message string) {
-	ErrorResponse(c, 404, message)
+func NotFound(c *gin.Context, message string, params ...i18n.Params) {
+	ErrorResponse(c, 404, message, params...)
 }
 
 // InternalServerError 500错误
-func InternalServerError(c *gin.Context, message string) {
-	ErrorResponse(c, 500, message)
+func InternalServerError(c *gin.Context, message string, params ...i18n.Params) {
+	ErrorResponse(c, 500, message, params...)
 }
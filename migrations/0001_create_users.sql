@@ -0,0 +1,20 @@
+-- 用户与密码重置令牌
+CREATE TABLE IF NOT EXISTS users (
+    id                  VARCHAR(36)  PRIMARY KEY,
+    email               VARCHAR(255) NOT NULL UNIQUE,
+    password_hash       TEXT         NOT NULL,
+    status              VARCHAR(16)  NOT NULL DEFAULT 'active',
+    password_changed_at TIMESTAMPTZ  NOT NULL,
+    created_at          TIMESTAMPTZ  NOT NULL,
+    updated_at          TIMESTAMPTZ  NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    token_hash VARCHAR(64) PRIMARY KEY,
+    user_id    VARCHAR(36) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens (user_id);
@@ -0,0 +1,20 @@
+-- TOTP 多因素认证
+CREATE TABLE IF NOT EXISTS totp_enrollments (
+    user_id          VARCHAR(36) PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    secret_encrypted TEXT        NOT NULL,
+    confirmed        BOOLEAN     NOT NULL DEFAULT FALSE,
+    confirmed_at     TIMESTAMPTZ,
+    last_used_step   BIGINT      NOT NULL DEFAULT 0,
+    created_at       TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    user_id    VARCHAR(36) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash  VARCHAR(64) NOT NULL,
+    used_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, code_hash)
+);
+
+-- 会话记录登录时使用的认证方式，刷新令牌时沿用
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS amr VARCHAR(64) NOT NULL DEFAULT '';
@@ -0,0 +1,17 @@
+-- 租户，用户按租户隔离
+CREATE TABLE IF NOT EXISTS tenants (
+    id         VARCHAR(63)  PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    status     VARCHAR(16)  NOT NULL DEFAULT 'active',
+    created_at TIMESTAMPTZ  NOT NULL,
+    updated_at TIMESTAMPTZ  NOT NULL
+);
+
+INSERT INTO tenants (id, name, status, created_at, updated_at)
+VALUES ('default', '默认租户', 'active', NOW(), NOW())
+ON CONFLICT (id) DO NOTHING;
+
+-- 已有用户归入默认租户，邮箱改为租户内唯一
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default' REFERENCES tenants (id);
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_tenant_email ON users (tenant_id, email);
@@ -0,0 +1,17 @@
+-- 机器客户端使用的 API 密钥，仅保存 SHA-256 摘要
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           VARCHAR(36)  PRIMARY KEY,
+    tenant_id    VARCHAR(63)  NOT NULL REFERENCES tenants (id),
+    user_id      VARCHAR(36)  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name         VARCHAR(100) NOT NULL,
+    prefix       VARCHAR(16)  NOT NULL UNIQUE,
+    key_hash     VARCHAR(64)  NOT NULL,
+    scopes       TEXT         NOT NULL DEFAULT '',
+    rate_limit   INTEGER      NOT NULL DEFAULT 0,
+    expires_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ  NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys (user_id);
@@ -0,0 +1,21 @@
+-- 安全审计事件，按序号组成哈希链，应用只追加不修改
+CREATE TABLE IF NOT EXISTS audit_events (
+    seq         BIGINT       PRIMARY KEY,
+    id          VARCHAR(36)  NOT NULL UNIQUE,
+    type        VARCHAR(64)  NOT NULL,
+    tenant_id   VARCHAR(63)  NOT NULL DEFAULT '',
+    actor_id    VARCHAR(36)  NOT NULL DEFAULT '',
+    subject     VARCHAR(255) NOT NULL DEFAULT '',
+    client_ip   VARCHAR(64)  NOT NULL DEFAULT '',
+    user_agent  VARCHAR(512) NOT NULL DEFAULT '',
+    metadata    TEXT         NOT NULL DEFAULT 'null',
+    occurred_at TIMESTAMPTZ  NOT NULL,
+    prev_hash   VARCHAR(64)  NOT NULL,
+    hash        VARCHAR(64)  NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_tenant_time ON audit_events (tenant_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor_time ON audit_events (actor_id, occurred_at);
+
+-- 建议为应用账号仅授予 INSERT 和 SELECT 权限：
+-- REVOKE UPDATE, DELETE, TRUNCATE ON audit_events FROM <app_user>;
@@ -0,0 +1,23 @@
+-- 邮箱与手机验证
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone VARCHAR(32) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_verified_at TIMESTAMPTZ;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_tenant_phone ON users (tenant_id, phone) WHERE phone <> '';
+
+-- 一次性验证码，仅保存摘要；同一目标和用途只有最近一次发送的验证码有效
+CREATE TABLE IF NOT EXISTS verification_codes (
+    id          VARCHAR(36)  PRIMARY KEY,
+    tenant_id   VARCHAR(63)  NOT NULL REFERENCES tenants (id),
+    user_id     VARCHAR(36)  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    channel     VARCHAR(16)  NOT NULL,
+    destination VARCHAR(255) NOT NULL,
+    purpose     VARCHAR(32)  NOT NULL,
+    code_hash   VARCHAR(64)  NOT NULL,
+    attempts    INT          NOT NULL DEFAULT 0,
+    expires_at  TIMESTAMPTZ  NOT NULL,
+    consumed_at TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ  NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_verification_codes_lookup
+    ON verification_codes (tenant_id, channel, destination, purpose, created_at DESC);
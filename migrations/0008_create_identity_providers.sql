@@ -0,0 +1,35 @@
+-- 租户配置的上游 OIDC 身份提供方，客户端密钥加密保存
+CREATE TABLE IF NOT EXISTS identity_providers (
+    id                      VARCHAR(36)  PRIMARY KEY,
+    tenant_id               VARCHAR(63)  NOT NULL REFERENCES tenants (id),
+    name                    VARCHAR(63)  NOT NULL,
+    display_name            VARCHAR(100) NOT NULL,
+    issuer                  VARCHAR(255) NOT NULL,
+    client_id               VARCHAR(255) NOT NULL,
+    client_secret_encrypted TEXT         NOT NULL DEFAULT '',
+    scopes                  TEXT         NOT NULL DEFAULT '',
+    email_claim             VARCHAR(100) NOT NULL DEFAULT 'email',
+    email_verified_claim    VARCHAR(100) NOT NULL DEFAULT 'email_verified',
+    trust_email             BOOLEAN      NOT NULL DEFAULT FALSE,
+    auto_link               BOOLEAN      NOT NULL DEFAULT FALSE,
+    allow_signup            BOOLEAN      NOT NULL DEFAULT FALSE,
+    enabled                 BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_at              TIMESTAMPTZ  NOT NULL,
+    updated_at              TIMESTAMPTZ  NOT NULL,
+    UNIQUE (tenant_id, name)
+);
+
+-- 本地用户与外部身份的关联，同一提供方的 subject 只能关联一个用户
+CREATE TABLE IF NOT EXISTS federated_identities (
+    id            VARCHAR(36)  PRIMARY KEY,
+    tenant_id     VARCHAR(63)  NOT NULL REFERENCES tenants (id),
+    provider_id   VARCHAR(36)  NOT NULL REFERENCES identity_providers (id) ON DELETE CASCADE,
+    subject       VARCHAR(255) NOT NULL,
+    user_id       VARCHAR(36)  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    email         VARCHAR(255) NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ  NOT NULL,
+    last_login_at TIMESTAMPTZ,
+    UNIQUE (provider_id, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_federated_identities_user_id ON federated_identities (user_id);
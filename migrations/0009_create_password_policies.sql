@@ -0,0 +1,24 @@
+-- 租户自定义的密码策略，未配置的租户使用全局默认策略
+CREATE TABLE IF NOT EXISTS password_policies (
+    tenant_id      VARCHAR(63) PRIMARY KEY REFERENCES tenants (id) ON DELETE CASCADE,
+    min_length     INTEGER     NOT NULL,
+    max_length     INTEGER     NOT NULL,
+    require_upper  BOOLEAN     NOT NULL DEFAULT FALSE,
+    require_lower  BOOLEAN     NOT NULL DEFAULT FALSE,
+    require_digit  BOOLEAN     NOT NULL DEFAULT FALSE,
+    require_symbol BOOLEAN     NOT NULL DEFAULT FALSE,
+    check_breached BOOLEAN     NOT NULL DEFAULT FALSE,
+    history_size   INTEGER     NOT NULL DEFAULT 0,
+    max_age_days   INTEGER     NOT NULL DEFAULT 0,
+    updated_at     TIMESTAMPTZ NOT NULL
+);
+
+-- 被替换的历史密码哈希，用于禁止重复使用最近的密码
+CREATE TABLE IF NOT EXISTS password_history (
+    id            BIGSERIAL    PRIMARY KEY,
+    user_id       VARCHAR(36)  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    password_hash TEXT         NOT NULL,
+    replaced_at   TIMESTAMPTZ  NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_history_user_id ON password_history (user_id, replaced_at DESC);